@@ -0,0 +1,99 @@
+package main
+
+import (
+	"WalletRieltaTestTask/config"
+	"WalletRieltaTestTask/internal/app"
+	"WalletRieltaTestTask/pkg/logger"
+	"os"
+	"os/signal"
+	"syscall"
+)
+
+// @title           Wallet Rielta
+// @version         1.0
+// @description     This is a test task.
+// @description
+// @description     Errors are returned as RFC 7807 problem details (application/problem+json).
+// @description     The "type" field is one of the following URIs:
+// @description     - urn:wallet-rielta:problem:validation-failed (400) - request fields failed validation
+// @description     - urn:wallet-rielta:problem:malformed-request (400) - request body is not valid json
+// @description     - urn:wallet-rielta:problem:wrong-amount (400) - amount must be greater than zero
+// @description     - urn:wallet-rielta:problem:empty-wallet (400) - wallet address is empty
+// @description     - urn:wallet-rielta:problem:sender-is-receiver (400) - sender and receiver are the same wallet
+// @description     - urn:wallet-rielta:problem:reason-required (400) - reason of the admin operation is required
+// @description     - urn:wallet-rielta:problem:invalid-webhook-url (400) - webhook url must be an absolute http or https url
+// @description     - urn:wallet-rielta:problem:unknown-event-type (400) - unknown event type in the webhook filter
+// @description     - urn:wallet-rielta:problem:not-wallet-owner (403) - wallet belongs to another user
+// @description     - urn:wallet-rielta:problem:wallet-not-found (404) - wallet not found
+// @description     - urn:wallet-rielta:problem:transaction-not-found (404) - transaction not found
+// @description     - urn:wallet-rielta:problem:webhook-not-found (404) - webhook not found
+// @description     - urn:wallet-rielta:problem:event-not-found (404) - event of the Last-Event-ID header not found
+// @description     - urn:wallet-rielta:problem:not-found (404) - resource not found
+// @description     - urn:wallet-rielta:problem:wallet-frozen (409) - wallet is frozen
+// @description     - urn:wallet-rielta:problem:not-enough-funds (409) - not enough funds on the wallet
+// @description     - urn:wallet-rielta:problem:already-reversed (409) - transaction already reversed
+// @description     - urn:wallet-rielta:problem:reversal-not-reversible (409) - reversal transaction can't be reversed
+// @description     - urn:wallet-rielta:problem:balance-too-large (422) - balance would exceed the maximum
+// @description     - urn:wallet-rielta:problem:timeout (504) - request timed out
+// @description     - urn:wallet-rielta:problem:unauthorized (401) - missing or invalid credentials
+// @description     - urn:wallet-rielta:problem:signature-required (401) - api key requires signed requests
+// @description     - urn:wallet-rielta:problem:invalid-signature (401) - invalid or expired request signature
+// @description     - urn:wallet-rielta:problem:request-replayed (401) - request nonce has already been used
+// @description     - urn:wallet-rielta:problem:forbidden (403) - no access to the operation
+// @description     - urn:wallet-rielta:problem:request-too-large (413) - request body is too large
+// @description     - urn:wallet-rielta:problem:rate-limited (429) - too many requests, see the Retry-After header
+// @description     - urn:wallet-rielta:problem:internal (500) - internal server error
+// @description
+// @description     Requests are authenticated with the X-API-Key header. Keys with "require-signature" instead send
+// @description     X-API-Key-ID, X-Timestamp (unix seconds), X-Nonce and X-Signature headers, where the signature is
+// @description     hex HMAC-SHA256 keyed with the signing secret printed when the key is created over the lines:
+// @description     method, path with query, timestamp, nonce, hex sha256 of the body.
+// @description
+// @description     End users authenticate with "Authorization: Bearer <jwt>" signed with RS256 or ES256.
+// @description     Users can send funds from and read history of only the wallets they own.
+// @description
+// @description     The /admin api is granted by roles of the api key or the "roles" token claim:
+// @description     support can search wallets, operator can also freeze wallets and adjust balances,
+// @description     admin can also reverse transactions and read the audit log. Every admin operation requires a reason.
+// @description
+// @description     Requests are rate limited for every api key, token subject or client ip with a token bucket.
+// @description     Responses contain RateLimit-Policy, RateLimit-Limit, RateLimit-Remaining and RateLimit-Reset headers.
+// @description     State-changing operations are written to the hash-chained audit log with the caller, request id and ip.
+
+// @host      localhost:8080
+// @BasePath  /api
+
+// @securityDefinitions.apikey ApiKeyAuth
+// @in                         header
+// @name                       X-API-Key
+
+// @securityDefinitions.apikey BearerAuth
+// @in                         header
+// @name                       Authorization
+func main() {
+	// Init configuration
+	cfg := config.MustLoad()
+
+	// Init logger
+	log := logger.SetupLogger(cfg.Log.Level)
+
+	// The api calls the worker through the rabbitMQ RPC and doesn't connect to postgres
+	application := app.NewAPI(log, cfg)
+
+	application.Run()
+
+	// Graceful shutdown
+	stop := make(chan os.Signal, 1)
+	signal.Notify(stop, syscall.SIGTERM, syscall.SIGINT)
+
+	select {
+	case <-stop:
+	case <-application.Notify():
+	}
+
+	log.Info("Starting graceful shutdown")
+
+	application.Shutdown(log)
+
+	log.Info("Gracefully stopped")
+}
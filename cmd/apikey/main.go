@@ -0,0 +1,76 @@
+package main
+
+import (
+	"WalletRieltaTestTask/config"
+	"WalletRieltaTestTask/internal/entity"
+	worker_postgres "WalletRieltaTestTask/internal/walletWorker/repository/postgres"
+	workerUC "WalletRieltaTestTask/internal/walletWorker/usecase"
+	"WalletRieltaTestTask/pkg/postgres"
+	"WalletRieltaTestTask/pkg/sealer"
+	"context"
+	"flag"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// Creates a new api key. The raw key and the signing secret are printed once and can't be restored later.
+func main() {
+	var (
+		name             string
+		scopes           string
+		roles            string
+		requireSignature bool
+	)
+
+	flag.StringVar(&name, "name", "", "name of the key owner")
+	flag.StringVar(&scopes, "scopes", entity.ScopeRead, "comma separated scopes: read, transfer, admin")
+	flag.StringVar(&roles, "roles", "", "comma separated admin api roles: support, operator, admin")
+	flag.BoolVar(&requireSignature, "require-signature", false, "accept only HMAC signed requests")
+
+	cfg := config.MustLoad()
+
+	if name == "" {
+		panic("Name is required")
+	}
+
+	keyScopes := strings.Split(scopes, ",")
+	for _, scope := range keyScopes {
+		if !slices.Contains([]string{entity.ScopeRead, entity.ScopeTransfer, entity.ScopeAdmin}, scope) {
+			panic("Unknown scope: " + scope)
+		}
+	}
+
+	var keyRoles []string
+	if roles != "" {
+		keyRoles = strings.Split(roles, ",")
+	}
+
+	for _, role := range keyRoles {
+		if !entity.IsRole(role) {
+			panic("Unknown role: " + role)
+		}
+	}
+
+	pg, err := postgres.NewPostgresDB(cfg.PG.URL, postgres.MaxPoolSize(1))
+	if err != nil {
+		panic(err)
+	}
+	defer pg.Close()
+
+	secrets, err := sealer.New(cfg.Auth.SecretKey)
+	if err != nil {
+		panic(err)
+	}
+
+	apiKeys := workerUC.NewAPIKeyWorker(worker_postgres.NewAPIKeyRepo(pg), secrets)
+
+	rawKey, key, err := apiKeys.CreateAPIKey(context.Background(), name, keyScopes, keyRoles, requireSignature)
+	if err != nil {
+		panic(err)
+	}
+
+	fmt.Printf("Api key %q created\n", key.ID)
+	fmt.Printf("Key: %s\n", rawKey)
+	fmt.Printf("Signing secret: %s\n", key.SigningSecret)
+}
@@ -9,12 +9,6 @@ import (
 	"syscall"
 )
 
-// @title           Wallet Rielta
-// @version         1.0
-// @description     This is a test task.
-
-// @host      localhost:8080
-// @BasePath  /api/v1
 func main() {
 	// Init configuration
 	cfg := config.MustLoad()
@@ -22,16 +16,10 @@ func main() {
 	// Init logger
 	log := logger.SetupLogger(cfg.Log.Level)
 
+	// The api and the worker in one process, see GATEWAY_MODE
 	application := app.New(log, cfg)
 
-	// Run servers
-	go func() {
-		application.HTTPServer.MustRun()
-	}()
-
-	go func() {
-		application.RMQServer.MustRun()
-	}()
+	application.Run()
 
 	// Graceful shutdown
 	stop := make(chan os.Signal, 1)
@@ -39,21 +27,13 @@ func main() {
 
 	select {
 	case <-stop:
-	case <-application.RMQServer.Notify():
+	case <-application.API.Notify():
+	case <-application.Worker.Notify():
 	}
 
 	log.Info("Starting graceful shutdown")
 
-	if err := application.HTTPServer.Shutdown(); err != nil {
-		log.Error("HTTPServer.Shutdown error", logger.Err(err))
-	}
-
-	if err := application.RMQServer.Shutdown(); err != nil {
-		log.Error("RMQServer.Shutdown error", logger.Err(err))
-	}
-
-	application.DB.Close()
+	application.Shutdown(log)
 
 	log.Info("Gracefully stopped")
-
 }
@@ -0,0 +1,44 @@
+package main
+
+import (
+	"WalletRieltaTestTask/config"
+	worker_postgres "WalletRieltaTestTask/internal/walletWorker/repository/postgres"
+	workerUC "WalletRieltaTestTask/internal/walletWorker/usecase"
+	"WalletRieltaTestTask/pkg/postgres"
+	"context"
+	"flag"
+	"fmt"
+	"os"
+)
+
+// Verifies the hash chain of the audit log. Exits with code 1 if any entry was modified or removed.
+// Removal of the latest entries is detected by comparing the head with the one saved by the previous run.
+func main() {
+	var head string
+
+	flag.StringVar(&head, "head", "", "hash of the chain head saved by the previous verification")
+
+	cfg := config.MustLoad()
+
+	pg, err := postgres.NewPostgresDB(cfg.PG.URL, postgres.MaxPoolSize(1))
+	if err != nil {
+		panic(err)
+	}
+	defer pg.Close()
+
+	audit := workerUC.NewAuditWorker(worker_postgres.NewAuditRepo(pg))
+
+	report, err := audit.VerifyAuditChain(context.Background(), head)
+	if err != nil {
+		fmt.Printf("Audit log is broken after %d valid entries: %v\n", report.Checked, err)
+		os.Exit(1)
+	}
+
+	fmt.Printf("Audit log is valid, %d entries checked\n", report.Checked)
+
+	if report.Legacy > 0 {
+		fmt.Printf("%d legacy entries written before the hash chain aren't verified\n", report.Legacy)
+	}
+
+	fmt.Printf("Head: %s\n", report.Head)
+}
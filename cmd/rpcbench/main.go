@@ -0,0 +1,116 @@
+package main
+
+import (
+	"WalletRieltaTestTask/config"
+	"WalletRieltaTestTask/pkg/logger"
+	"WalletRieltaTestTask/pkg/rabbitmq/rmq_rpc/client"
+	"WalletRieltaTestTask/pkg/rabbitmq/rmq_rpc/server"
+	"context"
+	"flag"
+	"fmt"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Measures the throughput of the RabbitMQ RPC under parallel load.
+// The calls go through a separate exchange and queue, so it can run next to the app.
+func main() {
+	var (
+		exchange string
+		calls    int
+		parallel int
+		workers  int
+		prefetch int
+		channels int
+		size     int
+	)
+
+	flag.StringVar(&exchange, "exchange", "rpc_bench", "exchange and queue of the benchmark server")
+	flag.IntVar(&calls, "calls", 10000, "number of the calls")
+	flag.IntVar(&parallel, "parallel", 64, "goroutines making the calls")
+	flag.IntVar(&workers, "workers", 24, "goroutines of the server")
+	flag.IntVar(&prefetch, "prefetch", 0, "prefetch of the server, the workers count if zero")
+	flag.IntVar(&channels, "channels", 16, "publishing channels of the client")
+	flag.IntVar(&size, "size", 256, "size of the request body in bytes")
+
+	cfg := config.MustLoad()
+
+	log := logger.SetupLogger(cfg.Log.Level)
+
+	router := map[string]server.CallHandler{
+		"echo": server.Handle(func(_ context.Context, request []byte) ([]byte, error) {
+			return request, nil
+		}),
+	}
+
+	opts := []server.Option{server.DefaultGoroutinesCount(workers)}
+	if prefetch > 0 {
+		opts = append(opts, server.Prefetch(prefetch))
+	}
+
+	rmqServer, err := server.New(cfg.RMQ.URL, exchange, router, log, opts...)
+	if err != nil {
+		panic(err)
+	}
+
+	rmqServer.MustRun()
+
+	rmqClient, err := client.NewRabbitMQClient(cfg.RMQ.URL, exchange, client.Channels(channels), client.Timeout(time.Minute))
+	if err != nil {
+		panic(err)
+	}
+
+	var (
+		next      atomic.Int64
+		failed    atomic.Int64
+		mu        sync.Mutex
+		latencies = make([]time.Duration, 0, calls)
+		wg        sync.WaitGroup
+	)
+
+	request := make([]byte, size)
+
+	start := time.Now()
+
+	for i := 0; i < parallel; i++ {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			for next.Add(1) <= int64(calls) {
+				callStart := time.Now()
+
+				var response []byte
+				if err := rmqClient.RemoteCall(context.Background(), "echo", request, &response); err != nil {
+					failed.Add(1)
+					continue
+				}
+
+				mu.Lock()
+				latencies = append(latencies, time.Since(callStart))
+				mu.Unlock()
+			}
+		}()
+	}
+
+	wg.Wait()
+
+	elapsed := time.Since(start)
+
+	slices.Sort(latencies)
+
+	fmt.Printf("calls: %d, failed: %d, parallel: %d, workers: %d, channels: %d\n",
+		calls, failed.Load(), parallel, workers, channels)
+	fmt.Printf("elapsed: %s, throughput: %.0f calls/s\n", elapsed, float64(calls)/elapsed.Seconds())
+
+	if len(latencies) > 0 {
+		fmt.Printf("latency p50: %s, p99: %s, max: %s\n",
+			latencies[len(latencies)/2], latencies[len(latencies)*99/100], latencies[len(latencies)-1])
+	}
+
+	_ = rmqClient.Shutdown()
+	_ = rmqServer.Shutdown()
+}
@@ -0,0 +1,38 @@
+package main
+
+import (
+	"WalletRieltaTestTask/config"
+	"WalletRieltaTestTask/internal/app"
+	"WalletRieltaTestTask/pkg/logger"
+	"os"
+	"os/signal"
+	"syscall"
+)
+
+func main() {
+	// Init configuration
+	cfg := config.MustLoad()
+
+	// Init logger
+	log := logger.SetupLogger(cfg.Log.Level)
+
+	// The worker serves the rabbitMQ RPC calls of the api
+	application := app.NewWorker(log, cfg)
+
+	application.Run()
+
+	// Graceful shutdown
+	stop := make(chan os.Signal, 1)
+	signal.Notify(stop, syscall.SIGTERM, syscall.SIGINT)
+
+	select {
+	case <-stop:
+	case <-application.Notify():
+	}
+
+	log.Info("Starting graceful shutdown")
+
+	application.Shutdown(log)
+
+	log.Info("Gracefully stopped")
+}
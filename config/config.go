@@ -8,6 +8,12 @@ import (
 	"time"
 )
 
+// Modes of the gateway.
+const (
+	GatewayRabbitMQ = "rabbitmq"
+	GatewayDirect   = "direct"
+)
+
 const (
 	defaultConfigPath = "./config/config.yaml"
 	defaultEnvPath    = ".env"
@@ -15,11 +21,20 @@ const (
 
 type (
 	Config struct {
-		App  `yaml:"app"`
-		HTTP `yaml:"http"`
-		PG   `yaml:"pg"`
-		RMQ  `yaml:"rabbitmq"`
-		Log  `yaml:"logger"`
+		App       `yaml:"app"`
+		HTTP      `yaml:"http"`
+		GRPC      `yaml:"grpc"`
+		Metrics   `yaml:"metrics"`
+		PG        `yaml:"pg"`
+		RMQ       `yaml:"rabbitmq"`
+		Gateway   `yaml:"gateway"`
+		Log       `yaml:"logger"`
+		Auth      `yaml:"auth"`
+		JWT       `yaml:"jwt"`
+		RateLimit `yaml:"rateLimit"`
+		Outbox    `yaml:"outbox"`
+		Webhooks  `yaml:"webhooks"`
+		Stream    `yaml:"stream"`
 	}
 
 	App struct {
@@ -35,20 +50,102 @@ type (
 		Timeout time.Duration `env:"HTTP_TIMEOUT" env-default:"5s"    yaml:"timeout"`
 	}
 
+	GRPC struct {
+		Port string `env:"GRPC_PORT" env-default:":9090" yaml:"port"`
+	}
+
+	// Metrics - port of the prometheus metrics of the worker, the api serves them on the http port.
+	Metrics struct {
+		Port string `env:"METRICS_PORT" env-default:":9100" yaml:"port"`
+	}
+
 	PG struct {
 		PoolMax int    `env:"PG_POOL_MAX" env-default:"2"     yaml:"poolMax"`
-		URL     string `env:"PG_URL"      env-required:"true" yaml:"url"`
+		URL     string `env:"PG_URL"      yaml:"url"`
 	}
 
 	RMQ struct {
 		ServerExchange string `env:"RMQ_RPC_SERVER" env-default:"rpc_server" yaml:"rpcServerExchange"`
-		ClientExchange string `env:"RMQ_RPC_CLIENT" env-default:"rpc_client" yaml:"rpcClientExchange"`
-		URL            string `env:"RMQ_URL"        env-required:"true"      yaml:"url"`
+		// AckAfterCall - calls are acked after they are served, failed calls are retried
+		// MaxAttempts times and moved to the DeadLetterExchange, "<ServerExchange>.dlx" if empty.
+		AckAfterCall       bool   `env:"RMQ_RPC_ACK_AFTER_CALL"       env-default:"true" yaml:"rpcAckAfterCall"`
+		MaxAttempts        int    `env:"RMQ_RPC_MAX_ATTEMPTS"         env-default:"5"    yaml:"rpcMaxAttempts"`
+		DeadLetterExchange string `env:"RMQ_RPC_DEAD_LETTER_EXCHANGE"                    yaml:"rpcDeadLetterExchange"`
+		// Prefetch - calls delivered to a worker before it acks the previous ones, APP_WORKERS if zero.
+		// Channels - channels of the api for publishing the calls concurrently.
+		Prefetch int `env:"RMQ_RPC_PREFETCH"                 yaml:"rpcPrefetch"`
+		Channels int `env:"RMQ_RPC_CHANNELS" env-default:"16" yaml:"rpcChannels"`
+		// Codec - encoding of the calls and replies, json or msgpack. Calls and replies of at least
+		// CompressionThreshold bytes are compressed by Compression, gzip or zstd, off if empty.
+		Codec                string `env:"RMQ_RPC_CODEC"                 env-default:"json" yaml:"rpcCodec"`
+		Compression          string `env:"RMQ_RPC_COMPRESSION"                              yaml:"rpcCompression"`
+		CompressionThreshold int    `env:"RMQ_RPC_COMPRESSION_THRESHOLD" env-default:"4096" yaml:"rpcCompressionThreshold"`
+
+		EventsExchange string `env:"RMQ_EVENTS_EXCHANGE" env-default:"wallet_events" yaml:"eventsExchange"`
+		URL            string `env:"RMQ_URL"                                 yaml:"url"`
+	}
+
+	// Gateway - how the api calls the worker use cases.
+	// Only Postgres is needed in the direct mode, the api and the worker run in the same process.
+	Gateway struct {
+		Mode string `env:"GATEWAY_MODE" env-default:"rabbitmq" yaml:"mode"`
 	}
 
 	Log struct {
 		Level string `env:"LOG_LEVEL" env-default:"debug" yaml:"logLevel"`
 	}
+
+	// Auth - SecretKey encrypts the signing secrets of the api keys in the db, 32 bytes hex encoded.
+	Auth struct {
+		SignatureMaxSkew time.Duration `env:"AUTH_SIGNATURE_MAX_SKEW" env-default:"5m" yaml:"signatureMaxSkew"`
+		SecretKey        string        `env:"AUTH_SECRET_KEY"                          yaml:"secretKey"`
+	}
+
+	JWT struct {
+		JWKSPath string `env:"JWT_JWKS_PATH" yaml:"jwksPath"`
+		Issuer   string `env:"JWT_ISSUER"    yaml:"issuer"`
+		Audience string `env:"JWT_AUDIENCE"  yaml:"audience"`
+	}
+
+	// RateLimit - requests allowed per period for every client.
+	// Routes are keyed by the method and the path pattern, e.g. "POST /api/v1/wallet".
+	// IP limits are applied to all requests of the client ip before authentication.
+	RateLimit struct {
+		Enabled    bool                     `env:"RATE_LIMIT_ENABLED"     env-default:"true" yaml:"enabled"`
+		Requests   int                      `env:"RATE_LIMIT_REQUESTS"    env-default:"100"  yaml:"requests"`
+		Period     time.Duration            `env:"RATE_LIMIT_PERIOD"      env-default:"1m"   yaml:"period"`
+		Burst      int                      `env:"RATE_LIMIT_BURST"                          yaml:"burst"`
+		IPRequests int                      `env:"RATE_LIMIT_IP_REQUESTS" env-default:"300"  yaml:"ipRequests"`
+		IPPeriod   time.Duration            `env:"RATE_LIMIT_IP_PERIOD"   env-default:"1m"   yaml:"ipPeriod"`
+		Routes     map[string]RateLimitRule `yaml:"routes"`
+	}
+
+	// Outbox - relay of the wallet events to the events exchange.
+	Outbox struct {
+		Interval  time.Duration `env:"OUTBOX_INTERVAL"   env-default:"1s"  yaml:"interval"`
+		BatchSize uint          `env:"OUTBOX_BATCH_SIZE" env-default:"100" yaml:"batchSize"`
+	}
+
+	// Webhooks - dispatching of the wallet events to the subscribed urls.
+	Webhooks struct {
+		Interval    time.Duration `env:"WEBHOOK_INTERVAL"     env-default:"1s"  yaml:"interval"`
+		BatchSize   uint          `env:"WEBHOOK_BATCH_SIZE"   env-default:"50"  yaml:"batchSize"`
+		MaxAttempts int           `env:"WEBHOOK_MAX_ATTEMPTS" env-default:"8"   yaml:"maxAttempts"`
+		BackoffBase time.Duration `env:"WEBHOOK_BACKOFF_BASE" env-default:"10s" yaml:"backoffBase"`
+		BackoffMax  time.Duration `env:"WEBHOOK_BACKOFF_MAX"  env-default:"1h"  yaml:"backoffMax"`
+		Timeout     time.Duration `env:"WEBHOOK_TIMEOUT"      env-default:"10s" yaml:"timeout"`
+	}
+
+	// Stream - live wallet events of the http api.
+	Stream struct {
+		Buffer int `env:"STREAM_BUFFER" env-default:"64" yaml:"buffer"`
+	}
+
+	RateLimitRule struct {
+		Requests int           `yaml:"requests"`
+		Period   time.Duration `yaml:"period"`
+		Burst    int           `yaml:"burst"`
+	}
 )
 
 func MustLoad() *Config {
@@ -15,35 +15,928 @@ const docTemplate = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
-        "/wallet": {
+        "/admin/audit": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Записи журнала неизменяемы и связаны цепочкой хешей, целостность проверяется командой audit-verify.",
+                "tags": [
+                    "Admin"
+                ],
+                "summary": "Поиск в журнале аудита",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID кошелька",
+                        "name": "walletId",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "ID перевода",
+                        "name": "transactionId",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Инициатор операции, например user:42 или key:wr_...",
+                        "name": "actor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Операция, например funds.sent",
+                        "name": "action",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "ID запроса",
+                        "name": "requestId",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Начало периода (RFC 3339)",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Конец периода, не включая (RFC 3339)",
+                        "name": "to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 50,
+                        "description": "Количество записей, до 500",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Смещение",
+                        "name": "offset",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Найденные записи",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/entity.AuditEntry"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Ошибка в пользовательском запросе (validation-failed, malformed-request)",
+                        "schema": {
+                            "$ref": "#/definitions/v1.problemDetails"
+                        }
+                    },
+                    "401": {
+                        "description": "Запрос не аутентифицирован (unauthorized)",
+                        "schema": {
+                            "$ref": "#/definitions/v1.problemDetails"
+                        }
+                    },
+                    "403": {
+                        "description": "Нет доступа к операции (forbidden)",
+                        "schema": {
+                            "$ref": "#/definitions/v1.problemDetails"
+                        }
+                    },
+                    "429": {
+                        "description": "Превышен лимит запросов (rate-limited)",
+                        "schema": {
+                            "$ref": "#/definitions/v1.problemDetails"
+                        }
+                    },
+                    "500": {
+                        "description": "Не удалось выполнить запрос (internal)",
+                        "schema": {
+                            "$ref": "#/definitions/v1.problemDetails"
+                        }
+                    },
+                    "504": {
+                        "description": "Время ожидания вышло (timeout)",
+                        "schema": {
+                            "$ref": "#/definitions/v1.problemDetails"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/transactions/{transactionId}/reverse": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Возвращает средства отправителю отдельным переводом.",
+                "tags": [
+                    "Admin"
+                ],
+                "summary": "Отмена перевода",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID перевода",
+                        "name": "transactionId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Причина отмены",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/v1.reasonRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Перевод отменен, возвращается обратный перевод",
+                        "schema": {
+                            "$ref": "#/definitions/entity.Transaction"
+                        }
+                    },
+                    "400": {
+                        "description": "Ошибка в пользовательском запросе (validation-failed, malformed-request, reason-required)",
+                        "schema": {
+                            "$ref": "#/definitions/v1.problemDetails"
+                        }
+                    },
+                    "401": {
+                        "description": "Запрос не аутентифицирован (unauthorized)",
+                        "schema": {
+                            "$ref": "#/definitions/v1.problemDetails"
+                        }
+                    },
+                    "403": {
+                        "description": "Нет доступа к операции (forbidden)",
+                        "schema": {
+                            "$ref": "#/definitions/v1.problemDetails"
+                        }
+                    },
+                    "404": {
+                        "description": "Перевод не найден (transaction-not-found)",
+                        "schema": {
+                            "$ref": "#/definitions/v1.problemDetails"
+                        }
+                    },
+                    "409": {
+                        "description": "Перевод уже отменен, сам является отменой или у получателя недостаточно средств (already-reversed, reversal-not-reversible, not-enough-funds)",
+                        "schema": {
+                            "$ref": "#/definitions/v1.problemDetails"
+                        }
+                    },
+                    "429": {
+                        "description": "Превышен лимит запросов (rate-limited)",
+                        "schema": {
+                            "$ref": "#/definitions/v1.problemDetails"
+                        }
+                    },
+                    "500": {
+                        "description": "Не удалось выполнить запрос (internal)",
+                        "schema": {
+                            "$ref": "#/definitions/v1.problemDetails"
+                        }
+                    },
+                    "504": {
+                        "description": "Время ожидания вышло (timeout)",
+                        "schema": {
+                            "$ref": "#/definitions/v1.problemDetails"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/wallets": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "tags": [
+                    "Admin"
+                ],
+                "summary": "Поиск кошельков",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID владельца",
+                        "name": "ownerId",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Заморожен",
+                        "name": "frozen",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Минимальный баланс",
+                        "name": "minBalance",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Максимальный баланс",
+                        "name": "maxBalance",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 50,
+                        "description": "Количество кошельков, до 500",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Смещение",
+                        "name": "offset",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Найденные кошельки",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/entity.Wallet"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Ошибка в пользовательском запросе (validation-failed)",
+                        "schema": {
+                            "$ref": "#/definitions/v1.problemDetails"
+                        }
+                    },
+                    "401": {
+                        "description": "Запрос не аутентифицирован (unauthorized)",
+                        "schema": {
+                            "$ref": "#/definitions/v1.problemDetails"
+                        }
+                    },
+                    "403": {
+                        "description": "Нет доступа к операции (forbidden)",
+                        "schema": {
+                            "$ref": "#/definitions/v1.problemDetails"
+                        }
+                    },
+                    "429": {
+                        "description": "Превышен лимит запросов (rate-limited)",
+                        "schema": {
+                            "$ref": "#/definitions/v1.problemDetails"
+                        }
+                    },
+                    "500": {
+                        "description": "Не удалось выполнить запрос (internal)",
+                        "schema": {
+                            "$ref": "#/definitions/v1.problemDetails"
+                        }
+                    },
+                    "504": {
+                        "description": "Время ожидания вышло (timeout)",
+                        "schema": {
+                            "$ref": "#/definitions/v1.problemDetails"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/wallets/{walletId}/adjustments": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "tags": [
+                    "Admin"
+                ],
+                "summary": "Ручное изменение баланса кошелька",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID кошелька",
+                        "name": "walletId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Запрос изменения баланса",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/v1.adjustmentRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Баланс изменен",
+                        "schema": {
+                            "$ref": "#/definitions/entity.Wallet"
+                        }
+                    },
+                    "400": {
+                        "description": "Ошибка в пользовательском запросе (validation-failed, malformed-request, reason-required)",
+                        "schema": {
+                            "$ref": "#/definitions/v1.problemDetails"
+                        }
+                    },
+                    "401": {
+                        "description": "Запрос не аутентифицирован (unauthorized)",
+                        "schema": {
+                            "$ref": "#/definitions/v1.problemDetails"
+                        }
+                    },
+                    "403": {
+                        "description": "Нет доступа к операции (forbidden)",
+                        "schema": {
+                            "$ref": "#/definitions/v1.problemDetails"
+                        }
+                    },
+                    "404": {
+                        "description": "Кошелек не найден (wallet-not-found)",
+                        "schema": {
+                            "$ref": "#/definitions/v1.problemDetails"
+                        }
+                    },
+                    "409": {
+                        "description": "Недостаточно средств (not-enough-funds)",
+                        "schema": {
+                            "$ref": "#/definitions/v1.problemDetails"
+                        }
+                    },
+                    "422": {
+                        "description": "Баланс превысит максимум (balance-too-large)",
+                        "schema": {
+                            "$ref": "#/definitions/v1.problemDetails"
+                        }
+                    },
+                    "429": {
+                        "description": "Превышен лимит запросов (rate-limited)",
+                        "schema": {
+                            "$ref": "#/definitions/v1.problemDetails"
+                        }
+                    },
+                    "500": {
+                        "description": "Не удалось выполнить запрос (internal)",
+                        "schema": {
+                            "$ref": "#/definitions/v1.problemDetails"
+                        }
+                    },
+                    "504": {
+                        "description": "Время ожидания вышло (timeout)",
+                        "schema": {
+                            "$ref": "#/definitions/v1.problemDetails"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/wallets/{walletId}/freeze": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Замороженный кошелек не может отправлять и получать переводы.",
+                "tags": [
+                    "Admin"
+                ],
+                "summary": "Заморозка кошелька",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID кошелька",
+                        "name": "walletId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Причина заморозки",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/v1.reasonRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Кошелек заморожен",
+                        "schema": {
+                            "$ref": "#/definitions/entity.Wallet"
+                        }
+                    },
+                    "400": {
+                        "description": "Ошибка в пользовательском запросе (validation-failed, malformed-request, reason-required)",
+                        "schema": {
+                            "$ref": "#/definitions/v1.problemDetails"
+                        }
+                    },
+                    "401": {
+                        "description": "Запрос не аутентифицирован (unauthorized)",
+                        "schema": {
+                            "$ref": "#/definitions/v1.problemDetails"
+                        }
+                    },
+                    "403": {
+                        "description": "Нет доступа к операции (forbidden)",
+                        "schema": {
+                            "$ref": "#/definitions/v1.problemDetails"
+                        }
+                    },
+                    "404": {
+                        "description": "Кошелек не найден (wallet-not-found)",
+                        "schema": {
+                            "$ref": "#/definitions/v1.problemDetails"
+                        }
+                    },
+                    "429": {
+                        "description": "Превышен лимит запросов (rate-limited)",
+                        "schema": {
+                            "$ref": "#/definitions/v1.problemDetails"
+                        }
+                    },
+                    "500": {
+                        "description": "Не удалось выполнить запрос (internal)",
+                        "schema": {
+                            "$ref": "#/definitions/v1.problemDetails"
+                        }
+                    },
+                    "504": {
+                        "description": "Время ожидания вышло (timeout)",
+                        "schema": {
+                            "$ref": "#/definitions/v1.problemDetails"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/wallets/{walletId}/unfreeze": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "tags": [
+                    "Admin"
+                ],
+                "summary": "Разморозка кошелька",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID кошелька",
+                        "name": "walletId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Причина разморозки",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/v1.reasonRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Кошелек разморожен",
+                        "schema": {
+                            "$ref": "#/definitions/entity.Wallet"
+                        }
+                    },
+                    "400": {
+                        "description": "Ошибка в пользовательском запросе (validation-failed, malformed-request, reason-required)",
+                        "schema": {
+                            "$ref": "#/definitions/v1.problemDetails"
+                        }
+                    },
+                    "401": {
+                        "description": "Запрос не аутентифицирован (unauthorized)",
+                        "schema": {
+                            "$ref": "#/definitions/v1.problemDetails"
+                        }
+                    },
+                    "403": {
+                        "description": "Нет доступа к операции (forbidden)",
+                        "schema": {
+                            "$ref": "#/definitions/v1.problemDetails"
+                        }
+                    },
+                    "404": {
+                        "description": "Кошелек не найден (wallet-not-found)",
+                        "schema": {
+                            "$ref": "#/definitions/v1.problemDetails"
+                        }
+                    },
+                    "429": {
+                        "description": "Превышен лимит запросов (rate-limited)",
+                        "schema": {
+                            "$ref": "#/definitions/v1.problemDetails"
+                        }
+                    },
+                    "500": {
+                        "description": "Не удалось выполнить запрос (internal)",
+                        "schema": {
+                            "$ref": "#/definitions/v1.problemDetails"
+                        }
+                    },
+                    "504": {
+                        "description": "Время ожидания вышло (timeout)",
+                        "schema": {
+                            "$ref": "#/definitions/v1.problemDetails"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/events": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Одно соединение получает события нескольких кошельков.\nКлиент отправляет команды {\"action\":\"subscribe\",\"walletId\":\"...\",\"lastEventId\":\"...\"}\nи {\"action\":\"unsubscribe\",\"walletId\":\"...\"}, сервер отвечает сообщениями subscribed, unsubscribed, error\nи присылает события кошельков в сообщениях event.\n\nСервер отправляет ping каждые 54 секунды и закрывает соединение без pong в течение минуты.\nКлиент, не успевающий читать события, отключается с кодом 1013 и переподписывается с lastEventId.",
+                "tags": [
+                    "Wallet"
+                ],
+                "summary": "WebSocket подписка на события кошельков",
+                "parameters": [
+                    {
+                        "description": "Команда клиента",
+                        "name": "input",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/v1.streamCommand"
+                        }
+                    }
+                ],
+                "responses": {
+                    "101": {
+                        "description": "Соединение установлено",
+                        "schema": {
+                            "$ref": "#/definitions/v1.streamMessage"
+                        }
+                    },
+                    "401": {
+                        "description": "Запрос не аутентифицирован (unauthorized, signature-required, invalid-signature, request-replayed)",
+                        "schema": {
+                            "$ref": "#/definitions/v1.problemDetails"
+                        }
+                    },
+                    "403": {
+                        "description": "Нет доступа к операции (forbidden)",
+                        "schema": {
+                            "$ref": "#/definitions/v1.problemDetails"
+                        }
+                    },
+                    "429": {
+                        "description": "Превышен лимит запросов (rate-limited)",
+                        "schema": {
+                            "$ref": "#/definitions/v1.problemDetails"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/wallet": {
             "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
                 "description": "Создает новый кошелек с уникальным ID. Идентификатор генерируется сервером.\n\nСозданный кошелек должен иметь сумму 100.0 у.е. на балансе",
                 "tags": [
                     "Wallet"
                 ],
-                "summary": "Создание кошелька",
+                "summary": "Создание кошелька",
+                "responses": {
+                    "200": {
+                        "description": "Кошелек создан",
+                        "schema": {
+                            "$ref": "#/definitions/entity.Wallet"
+                        }
+                    },
+                    "401": {
+                        "description": "Запрос не аутентифицирован (unauthorized, signature-required, invalid-signature, request-replayed)",
+                        "schema": {
+                            "$ref": "#/definitions/v1.problemDetails"
+                        }
+                    },
+                    "403": {
+                        "description": "Нет доступа к операции (forbidden)",
+                        "schema": {
+                            "$ref": "#/definitions/v1.problemDetails"
+                        }
+                    },
+                    "429": {
+                        "description": "Превышен лимит запросов (rate-limited)",
+                        "schema": {
+                            "$ref": "#/definitions/v1.problemDetails"
+                        }
+                    },
+                    "500": {
+                        "description": "Не удалось создать кошелек (internal)",
+                        "schema": {
+                            "$ref": "#/definitions/v1.problemDetails"
+                        }
+                    },
+                    "504": {
+                        "description": "Время ожидания вышло (timeout)",
+                        "schema": {
+                            "$ref": "#/definitions/v1.problemDetails"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/wallet/{walletId}": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "tags": [
+                    "Wallet"
+                ],
+                "summary": "Получение текущего состояния кошелька",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID кошелька",
+                        "name": "walletId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/entity.Wallet"
+                        }
+                    },
+                    "401": {
+                        "description": "Запрос не аутентифицирован (unauthorized, signature-required, invalid-signature, request-replayed)",
+                        "schema": {
+                            "$ref": "#/definitions/v1.problemDetails"
+                        }
+                    },
+                    "403": {
+                        "description": "Нет доступа к операции (forbidden)",
+                        "schema": {
+                            "$ref": "#/definitions/v1.problemDetails"
+                        }
+                    },
+                    "404": {
+                        "description": "Указанный кошелек не найден (wallet-not-found)",
+                        "schema": {
+                            "$ref": "#/definitions/v1.problemDetails"
+                        }
+                    },
+                    "429": {
+                        "description": "Превышен лимит запросов (rate-limited)",
+                        "schema": {
+                            "$ref": "#/definitions/v1.problemDetails"
+                        }
+                    },
+                    "500": {
+                        "description": "Не удалось выполнить запрос (internal)",
+                        "schema": {
+                            "$ref": "#/definitions/v1.problemDetails"
+                        }
+                    },
+                    "504": {
+                        "description": "Время ожидания вышло (timeout)",
+                        "schema": {
+                            "$ref": "#/definitions/v1.problemDetails"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/wallet/{walletId}/events": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Server-Sent Events с событиями кошелька (wallet.created, funds.sent, funds.received, balance.adjusted) после их фиксации.\nПоле id события передается в заголовке Last-Event-ID при переподключении, чтобы получить пропущенные события.\nСобытия доставляются как минимум один раз, повторы отбрасываются по id.\nМедленный клиент отключается и продолжает с последнего полученного события.",
+                "produces": [
+                    "text/event-stream"
+                ],
+                "tags": [
+                    "Wallet"
+                ],
+                "summary": "Поток событий кошелька",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID кошелька",
+                        "name": "walletId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ID последнего полученного события",
+                        "name": "Last-Event-ID",
+                        "in": "header"
+                    }
+                ],
                 "responses": {
                     "200": {
-                        "description": "Кошелек создан",
+                        "description": "Поток событий",
                         "schema": {
-                            "$ref": "#/definitions/entity.Wallet"
+                            "$ref": "#/definitions/entity.Event"
+                        }
+                    },
+                    "401": {
+                        "description": "Запрос не аутентифицирован (unauthorized, signature-required, invalid-signature, request-replayed)",
+                        "schema": {
+                            "$ref": "#/definitions/v1.problemDetails"
+                        }
+                    },
+                    "403": {
+                        "description": "Нет доступа к операции (forbidden, not-wallet-owner)",
+                        "schema": {
+                            "$ref": "#/definitions/v1.problemDetails"
+                        }
+                    },
+                    "404": {
+                        "description": "Кошелек или событие не найдены (wallet-not-found, event-not-found)",
+                        "schema": {
+                            "$ref": "#/definitions/v1.problemDetails"
+                        }
+                    },
+                    "429": {
+                        "description": "Превышен лимит запросов (rate-limited)",
+                        "schema": {
+                            "$ref": "#/definitions/v1.problemDetails"
                         }
                     },
                     "500": {
-                        "description": "Не удалось создать кошелек"
+                        "description": "Не удалось выполнить запрос (internal)",
+                        "schema": {
+                            "$ref": "#/definitions/v1.problemDetails"
+                        }
                     },
                     "504": {
-                        "description": "Время ожидания вышло"
+                        "description": "Время ожидания вышло (timeout)",
+                        "schema": {
+                            "$ref": "#/definitions/v1.problemDetails"
+                        }
                     }
                 }
             }
         },
-        "/wallet/{walletId}": {
+        "/v1/wallet/{walletId}/history": {
             "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Возвращает историю транзакций по указанному кошельку.",
                 "tags": [
                     "Wallet"
                 ],
-                "summary": "Получение текущего состояния кошелька",
+                "summary": "Получение историй входящих и исходящих транзакций",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID кошелька",
+                        "name": "walletId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "История транзакций получена",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/entity.Transaction"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Запрос не аутентифицирован (unauthorized, signature-required, invalid-signature, request-replayed)",
+                        "schema": {
+                            "$ref": "#/definitions/v1.problemDetails"
+                        }
+                    },
+                    "403": {
+                        "description": "Нет доступа к операции (forbidden, not-wallet-owner)",
+                        "schema": {
+                            "$ref": "#/definitions/v1.problemDetails"
+                        }
+                    },
+                    "404": {
+                        "description": "Указанный кошелек не найден (wallet-not-found)",
+                        "schema": {
+                            "$ref": "#/definitions/v1.problemDetails"
+                        }
+                    },
+                    "429": {
+                        "description": "Превышен лимит запросов (rate-limited)",
+                        "schema": {
+                            "$ref": "#/definitions/v1.problemDetails"
+                        }
+                    },
+                    "500": {
+                        "description": "Не удалось выполнить запрос (internal)",
+                        "schema": {
+                            "$ref": "#/definitions/v1.problemDetails"
+                        }
+                    },
+                    "504": {
+                        "description": "Время ожидания вышло (timeout)",
+                        "schema": {
+                            "$ref": "#/definitions/v1.problemDetails"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/wallet/{walletId}/send": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "tags": [
+                    "Wallet"
+                ],
+                "summary": "Перевод средств с одного кошелька на другой",
                 "parameters": [
                     {
                         "type": "string",
@@ -51,115 +944,523 @@ const docTemplate = `{
                         "name": "walletId",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Ключ идемпотентности, повторный запрос с ним не переводит средства повторно",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    },
+                    {
+                        "description": "Запрос перевода средств",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/v1.transactionRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Перевод успешно проведен"
+                    },
+                    "400": {
+                        "description": "Ошибка в пользовательском запросе (validation-failed, malformed-request, wrong-amount, empty-wallet, sender-is-receiver, invalid-idempotency-key)",
+                        "schema": {
+                            "$ref": "#/definitions/v1.problemDetails"
+                        }
+                    },
+                    "401": {
+                        "description": "Запрос не аутентифицирован (unauthorized, signature-required, invalid-signature, request-replayed)",
+                        "schema": {
+                            "$ref": "#/definitions/v1.problemDetails"
+                        }
+                    },
+                    "403": {
+                        "description": "Нет доступа к операции (forbidden, not-wallet-owner)",
+                        "schema": {
+                            "$ref": "#/definitions/v1.problemDetails"
+                        }
+                    },
+                    "404": {
+                        "description": "Исходящий кошелек не найден (wallet-not-found)",
+                        "schema": {
+                            "$ref": "#/definitions/v1.problemDetails"
+                        }
+                    },
+                    "422": {
+                        "description": "Ключ идемпотентности использован другим переводом (idempotency-key-conflict)",
+                        "schema": {
+                            "$ref": "#/definitions/v1.problemDetails"
+                        }
+                    },
+                    "429": {
+                        "description": "Превышен лимит запросов (rate-limited)",
+                        "schema": {
+                            "$ref": "#/definitions/v1.problemDetails"
+                        }
+                    },
+                    "500": {
+                        "description": "Ошибка перевода (internal)",
+                        "schema": {
+                            "$ref": "#/definitions/v1.problemDetails"
+                        }
+                    },
+                    "504": {
+                        "description": "Время ожидания вышло (timeout)",
+                        "schema": {
+                            "$ref": "#/definitions/v1.problemDetails"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/webhooks": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "tags": [
+                    "Webhooks"
+                ],
+                "summary": "Список вебхуков",
+                "responses": {
+                    "200": {
+                        "description": "Вебхуки вызывающего",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/entity.Webhook"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Запрос не аутентифицирован (unauthorized, signature-required, invalid-signature, request-replayed)",
+                        "schema": {
+                            "$ref": "#/definitions/v1.problemDetails"
+                        }
+                    },
+                    "403": {
+                        "description": "Нет доступа к операции (forbidden)",
+                        "schema": {
+                            "$ref": "#/definitions/v1.problemDetails"
+                        }
+                    },
+                    "429": {
+                        "description": "Превышен лимит запросов (rate-limited)",
+                        "schema": {
+                            "$ref": "#/definitions/v1.problemDetails"
+                        }
+                    },
+                    "500": {
+                        "description": "Не удалось выполнить запрос (internal)",
+                        "schema": {
+                            "$ref": "#/definitions/v1.problemDetails"
+                        }
+                    },
+                    "504": {
+                        "description": "Время ожидания вышло (timeout)",
+                        "schema": {
+                            "$ref": "#/definitions/v1.problemDetails"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Подписывает адрес на события кошельков. События доставляются POST запросом с JSON телом события.\n\nТело подписывается HMAC-SHA256 с ключом secret от строки \"\u003cX-Webhook-Timestamp\u003e.\u003cтело\u003e\",\nподпись передается в заголовке X-Webhook-Signature в виде \"v1=\u003chex\u003e\".\nКлюч возвращается только при создании. Пользователи получают события только своих кошельков.",
+                "tags": [
+                    "Webhooks"
+                ],
+                "summary": "Создание вебхука",
+                "parameters": [
+                    {
+                        "description": "Запрос подписки",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/v1.webhookRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Вебхук создан",
+                        "schema": {
+                            "$ref": "#/definitions/entity.Webhook"
+                        }
+                    },
+                    "400": {
+                        "description": "Ошибка в пользовательском запросе (validation-failed, malformed-request, invalid-webhook-url, unknown-event-type)",
+                        "schema": {
+                            "$ref": "#/definitions/v1.problemDetails"
+                        }
+                    },
+                    "401": {
+                        "description": "Запрос не аутентифицирован (unauthorized, signature-required, invalid-signature, request-replayed)",
+                        "schema": {
+                            "$ref": "#/definitions/v1.problemDetails"
+                        }
+                    },
+                    "403": {
+                        "description": "Нет доступа к операции (forbidden)",
+                        "schema": {
+                            "$ref": "#/definitions/v1.problemDetails"
+                        }
+                    },
+                    "429": {
+                        "description": "Превышен лимит запросов (rate-limited)",
+                        "schema": {
+                            "$ref": "#/definitions/v1.problemDetails"
+                        }
+                    },
+                    "500": {
+                        "description": "Не удалось выполнить запрос (internal)",
+                        "schema": {
+                            "$ref": "#/definitions/v1.problemDetails"
+                        }
+                    },
+                    "504": {
+                        "description": "Время ожидания вышло (timeout)",
+                        "schema": {
+                            "$ref": "#/definitions/v1.problemDetails"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/webhooks/{webhookId}": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "tags": [
+                    "Webhooks"
+                ],
+                "summary": "Получение вебхука",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID вебхука",
+                        "name": "webhookId",
+                        "in": "path",
+                        "required": true
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "OK",
+                        "description": "Вебхук",
                         "schema": {
-                            "$ref": "#/definitions/entity.Wallet"
+                            "$ref": "#/definitions/entity.Webhook"
+                        }
+                    },
+                    "401": {
+                        "description": "Запрос не аутентифицирован (unauthorized, signature-required, invalid-signature, request-replayed)",
+                        "schema": {
+                            "$ref": "#/definitions/v1.problemDetails"
+                        }
+                    },
+                    "403": {
+                        "description": "Нет доступа к операции (forbidden)",
+                        "schema": {
+                            "$ref": "#/definitions/v1.problemDetails"
                         }
                     },
                     "404": {
-                        "description": "Указанный кошелек не найден"
+                        "description": "Вебхук не найден (webhook-not-found)",
+                        "schema": {
+                            "$ref": "#/definitions/v1.problemDetails"
+                        }
+                    },
+                    "429": {
+                        "description": "Превышен лимит запросов (rate-limited)",
+                        "schema": {
+                            "$ref": "#/definitions/v1.problemDetails"
+                        }
                     },
                     "500": {
-                        "description": "Не удалось выполнить запрос"
+                        "description": "Не удалось выполнить запрос (internal)",
+                        "schema": {
+                            "$ref": "#/definitions/v1.problemDetails"
+                        }
                     },
                     "504": {
-                        "description": "Время ожидания вышло"
+                        "description": "Время ожидания вышло (timeout)",
+                        "schema": {
+                            "$ref": "#/definitions/v1.problemDetails"
+                        }
                     }
                 }
-            }
-        },
-        "/wallet/{walletId}/history": {
-            "get": {
-                "description": "Возвращает историю транзакций по указанному кошельку.",
+            },
+            "delete": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Недоставленные события вебхука отменяются.",
                 "tags": [
-                    "Wallet"
+                    "Webhooks"
                 ],
-                "summary": "Получение историй входящих и исходящих транзакций",
+                "summary": "Удаление вебхука",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "ID кошелька",
-                        "name": "walletId",
+                        "description": "ID вебхука",
+                        "name": "webhookId",
                         "in": "path",
                         "required": true
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "История транзакций получена",
+                    "204": {
+                        "description": "Вебхук удален"
+                    },
+                    "401": {
+                        "description": "Запрос не аутентифицирован (unauthorized, signature-required, invalid-signature, request-replayed)",
                         "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/entity.Transaction"
-                            }
+                            "$ref": "#/definitions/v1.problemDetails"
+                        }
+                    },
+                    "403": {
+                        "description": "Нет доступа к операции (forbidden)",
+                        "schema": {
+                            "$ref": "#/definitions/v1.problemDetails"
                         }
                     },
                     "404": {
-                        "description": "Указанный кошелек не найден"
+                        "description": "Вебхук не найден (webhook-not-found)",
+                        "schema": {
+                            "$ref": "#/definitions/v1.problemDetails"
+                        }
+                    },
+                    "429": {
+                        "description": "Превышен лимит запросов (rate-limited)",
+                        "schema": {
+                            "$ref": "#/definitions/v1.problemDetails"
+                        }
                     },
                     "500": {
-                        "description": "Не удалось выполнить запрос"
+                        "description": "Не удалось выполнить запрос (internal)",
+                        "schema": {
+                            "$ref": "#/definitions/v1.problemDetails"
+                        }
                     },
                     "504": {
-                        "description": "Время ожидания вышло"
+                        "description": "Время ожидания вышло (timeout)",
+                        "schema": {
+                            "$ref": "#/definitions/v1.problemDetails"
+                        }
                     }
                 }
             }
         },
-        "/wallet/{walletId}/send": {
-            "post": {
+        "/v1/webhooks/{webhookId}/deliveries": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Доставки в статусе pending повторяются с экспоненциальной задержкой,\nпосле исчерпания попыток доставка переходит в статус dead.",
                 "tags": [
-                    "Wallet"
+                    "Webhooks"
                 ],
-                "summary": "Перевод средств с одного кошелька на другой",
+                "summary": "Журнал доставок вебхука",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "ID кошелька",
-                        "name": "walletId",
+                        "description": "ID вебхука",
+                        "name": "webhookId",
                         "in": "path",
                         "required": true
                     },
                     {
-                        "description": "Запрос перевода средств",
-                        "name": "input",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/v1.transactionRequest"
-                        }
+                        "type": "integer",
+                        "default": 50,
+                        "description": "Количество доставок, до 500",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Смещение",
+                        "name": "offset",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Перевод успешно проведен"
+                        "description": "Доставки, начиная с последней",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/entity.WebhookDelivery"
+                            }
+                        }
                     },
                     "400": {
-                        "description": "Ошибка в пользовательском запросе"
+                        "description": "Ошибка в пользовательском запросе (validation-failed)",
+                        "schema": {
+                            "$ref": "#/definitions/v1.problemDetails"
+                        }
+                    },
+                    "401": {
+                        "description": "Запрос не аутентифицирован (unauthorized, signature-required, invalid-signature, request-replayed)",
+                        "schema": {
+                            "$ref": "#/definitions/v1.problemDetails"
+                        }
+                    },
+                    "403": {
+                        "description": "Нет доступа к операции (forbidden)",
+                        "schema": {
+                            "$ref": "#/definitions/v1.problemDetails"
+                        }
                     },
                     "404": {
-                        "description": "Исходящий кошелек не найден"
+                        "description": "Вебхук не найден (webhook-not-found)",
+                        "schema": {
+                            "$ref": "#/definitions/v1.problemDetails"
+                        }
+                    },
+                    "429": {
+                        "description": "Превышен лимит запросов (rate-limited)",
+                        "schema": {
+                            "$ref": "#/definitions/v1.problemDetails"
+                        }
                     },
                     "500": {
-                        "description": "Ошибка перевода"
+                        "description": "Не удалось выполнить запрос (internal)",
+                        "schema": {
+                            "$ref": "#/definitions/v1.problemDetails"
+                        }
                     },
                     "504": {
-                        "description": "Время ожидания вышло"
+                        "description": "Время ожидания вышло (timeout)",
+                        "schema": {
+                            "$ref": "#/definitions/v1.problemDetails"
+                        }
                     }
                 }
             }
         }
     },
     "definitions": {
+        "entity.AuditEntry": {
+            "type": "object",
+            "properties": {
+                "action": {
+                    "type": "string"
+                },
+                "actor": {
+                    "type": "string"
+                },
+                "balances": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/entity.BalanceChange"
+                    }
+                },
+                "details": {
+                    "type": "object"
+                },
+                "hash": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "ip": {
+                    "type": "string"
+                },
+                "payloadHash": {
+                    "type": "string"
+                },
+                "prevHash": {
+                    "type": "string"
+                },
+                "reason": {
+                    "type": "string"
+                },
+                "requestId": {
+                    "type": "string"
+                },
+                "time": {
+                    "type": "string"
+                },
+                "transactionId": {
+                    "type": "integer"
+                },
+                "walletId": {
+                    "type": "string"
+                }
+            }
+        },
+        "entity.BalanceChange": {
+            "type": "object",
+            "properties": {
+                "after": {
+                    "type": "integer"
+                },
+                "before": {
+                    "type": "integer"
+                },
+                "walletId": {
+                    "type": "string"
+                }
+            }
+        },
+        "entity.Event": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "type": "object"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "occurredAt": {
+                    "type": "string"
+                },
+                "type": {
+                    "type": "string"
+                },
+                "walletId": {
+                    "type": "string"
+                }
+            }
+        },
         "entity.Transaction": {
             "type": "object",
             "required": [
                 "amount",
                 "from",
+                "id",
                 "time",
                 "to"
             ],
@@ -172,6 +1473,19 @@ const docTemplate = `{
                     "type": "string",
                     "example": "5b53700ed469fa6a09ea72bb78f36fd9"
                 },
+                "id": {
+                    "type": "integer",
+                    "example": 42
+                },
+                "reversalOf": {
+                    "type": "integer",
+                    "example": 41
+                },
+                "reversedAt": {
+                    "type": "string",
+                    "format": "date-time",
+                    "example": "2024-02-04T18:25:35.448Z"
+                },
                 "time": {
                     "type": "string",
                     "format": "date-time",
@@ -194,7 +1508,209 @@ const docTemplate = `{
                     "type": "integer",
                     "example": 100
                 },
+                "frozen": {
+                    "type": "boolean",
+                    "example": false
+                },
+                "id": {
+                    "type": "string",
+                    "example": "5b53700ed469fa6a09ea72bb78f36fd9"
+                },
+                "ownerId": {
+                    "type": "string",
+                    "example": "user-42"
+                }
+            }
+        },
+        "entity.Webhook": {
+            "type": "object",
+            "properties": {
+                "createdAt": {
+                    "type": "string"
+                },
+                "createdBy": {
+                    "type": "string"
+                },
+                "events": {
+                    "description": "Events - types of the events, all events if empty.",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "id": {
+                    "type": "string"
+                },
+                "ownerId": {
+                    "type": "string"
+                },
+                "secret": {
+                    "description": "Secret - key of the payload signature, returned only when the webhook is created.",
+                    "type": "string"
+                },
+                "url": {
+                    "description": "URL - receiver of the POST requests.",
+                    "type": "string"
+                }
+            }
+        },
+        "entity.WebhookDelivery": {
+            "type": "object",
+            "properties": {
+                "attempts": {
+                    "type": "integer"
+                },
+                "createdAt": {
+                    "type": "string"
+                },
+                "deliveredAt": {
+                    "type": "string"
+                },
+                "eventId": {
+                    "type": "string"
+                },
+                "eventType": {
+                    "type": "string"
+                },
                 "id": {
+                    "type": "integer"
+                },
+                "lastError": {
+                    "type": "string"
+                },
+                "nextAttemptAt": {
+                    "type": "string"
+                },
+                "responseStatus": {
+                    "type": "integer"
+                },
+                "status": {
+                    "type": "string"
+                },
+                "webhookId": {
+                    "type": "string"
+                }
+            }
+        },
+        "v1.adjustmentRequest": {
+            "description": "Запрос ручного изменения баланса.",
+            "type": "object",
+            "required": [
+                "amount",
+                "reason"
+            ],
+            "properties": {
+                "amount": {
+                    "type": "integer",
+                    "example": -30
+                },
+                "reason": {
+                    "type": "string",
+                    "example": "Компенсация по тикету 42"
+                }
+            }
+        },
+        "v1.errorDetail": {
+            "description": "Подробности ошибки валидации поля.",
+            "type": "object",
+            "properties": {
+                "field": {
+                    "type": "string",
+                    "example": "amount"
+                },
+                "rule": {
+                    "type": "string",
+                    "example": "required"
+                }
+            }
+        },
+        "v1.problemDetails": {
+            "description": "Описание ошибки в формате RFC 7807 (application/problem+json).",
+            "type": "object",
+            "properties": {
+                "detail": {
+                    "type": "string",
+                    "example": "request body has invalid fields"
+                },
+                "errors": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/v1.errorDetail"
+                    }
+                },
+                "instance": {
+                    "type": "string",
+                    "example": "/api/v1/wallet/5b53700ed469fa6a09ea72bb78f36fd9/send"
+                },
+                "requestId": {
+                    "type": "string",
+                    "example": "0b4e5a3c-5a3f-4c4b-9a5e-2f0d1f7f6c11"
+                },
+                "status": {
+                    "type": "integer",
+                    "example": 400
+                },
+                "title": {
+                    "type": "string",
+                    "example": "Request validation failed"
+                },
+                "type": {
+                    "type": "string",
+                    "example": "urn:wallet-rielta:problem:validation-failed"
+                }
+            }
+        },
+        "v1.reasonRequest": {
+            "description": "Причина операции.",
+            "type": "object",
+            "required": [
+                "reason"
+            ],
+            "properties": {
+                "reason": {
+                    "type": "string",
+                    "example": "Подозрительная активность"
+                }
+            }
+        },
+        "v1.streamCommand": {
+            "description": "Команда клиента websocket соединения.",
+            "type": "object",
+            "properties": {
+                "action": {
+                    "type": "string",
+                    "example": "subscribe"
+                },
+                "lastEventId": {
+                    "type": "string",
+                    "example": "1f0e7c9a-3b1e-4a43-9d7e-5d3c2a1b0f9e"
+                },
+                "walletId": {
+                    "type": "string",
+                    "example": "5b53700ed469fa6a09ea72bb78f36fd9"
+                }
+            }
+        },
+        "v1.streamMessage": {
+            "description": "Сообщение сервера websocket соединения.",
+            "type": "object",
+            "properties": {
+                "event": {
+                    "$ref": "#/definitions/entity.Event"
+                },
+                "problem": {
+                    "type": "string",
+                    "example": "urn:wallet-rielta:problem:wallet-not-found"
+                },
+                "title": {
+                    "type": "string",
+                    "example": "Wallet not found"
+                },
+                "type": {
+                    "type": "string",
+                    "example": "event"
+                },
+                "walletId": {
                     "type": "string",
                     "example": "5b53700ed469fa6a09ea72bb78f36fd9"
                 }
@@ -217,6 +1733,46 @@ const docTemplate = `{
                     "example": "eb376add88bf8e70f80787266a0801d5"
                 }
             }
+        },
+        "v1.webhookRequest": {
+            "description": "Запрос подписки на события кошельков.",
+            "type": "object",
+            "required": [
+                "url"
+            ],
+            "properties": {
+                "events": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "funds.sent",
+                        "funds.received"
+                    ]
+                },
+                "secret": {
+                    "type": "string",
+                    "minLength": 16,
+                    "example": "whsec_5f2b..."
+                },
+                "url": {
+                    "type": "string",
+                    "example": "https://partner.example/hooks/wallet"
+                }
+            }
+        }
+    },
+    "securityDefinitions": {
+        "ApiKeyAuth": {
+            "type": "apiKey",
+            "name": "X-API-Key",
+            "in": "header"
+        },
+        "BearerAuth": {
+            "type": "apiKey",
+            "name": "Authorization",
+            "in": "header"
         }
     }
 }`
@@ -225,10 +1781,10 @@ const docTemplate = `{
 var SwaggerInfo = &swag.Spec{
 	Version:          "1.0",
 	Host:             "localhost:8080",
-	BasePath:         "/api/v1",
+	BasePath:         "/api",
 	Schemes:          []string{},
 	Title:            "Wallet Rielta",
-	Description:      "This is a test task.",
+	Description:      "This is a test task.\n\nErrors are returned as RFC 7807 problem details (application/problem+json).\nThe \"type\" field is one of the following URIs:\n- urn:wallet-rielta:problem:validation-failed (400) - request fields failed validation\n- urn:wallet-rielta:problem:malformed-request (400) - request body is not valid json\n- urn:wallet-rielta:problem:wrong-amount (400) - amount must be greater than zero\n- urn:wallet-rielta:problem:empty-wallet (400) - wallet address is empty\n- urn:wallet-rielta:problem:sender-is-receiver (400) - sender and receiver are the same wallet\n- urn:wallet-rielta:problem:reason-required (400) - reason of the admin operation is required\n- urn:wallet-rielta:problem:invalid-webhook-url (400) - webhook url must be an absolute http or https url\n- urn:wallet-rielta:problem:unknown-event-type (400) - unknown event type in the webhook filter\n- urn:wallet-rielta:problem:not-wallet-owner (403) - wallet belongs to another user\n- urn:wallet-rielta:problem:wallet-not-found (404) - wallet not found\n- urn:wallet-rielta:problem:transaction-not-found (404) - transaction not found\n- urn:wallet-rielta:problem:webhook-not-found (404) - webhook not found\n- urn:wallet-rielta:problem:event-not-found (404) - event of the Last-Event-ID header not found\n- urn:wallet-rielta:problem:not-found (404) - resource not found\n- urn:wallet-rielta:problem:wallet-frozen (409) - wallet is frozen\n- urn:wallet-rielta:problem:not-enough-funds (409) - not enough funds on the wallet\n- urn:wallet-rielta:problem:already-reversed (409) - transaction already reversed\n- urn:wallet-rielta:problem:reversal-not-reversible (409) - reversal transaction can't be reversed\n- urn:wallet-rielta:problem:balance-too-large (422) - balance would exceed the maximum\n- urn:wallet-rielta:problem:timeout (504) - request timed out\n- urn:wallet-rielta:problem:unauthorized (401) - missing or invalid credentials\n- urn:wallet-rielta:problem:signature-required (401) - api key requires signed requests\n- urn:wallet-rielta:problem:invalid-signature (401) - invalid or expired request signature\n- urn:wallet-rielta:problem:request-replayed (401) - request nonce has already been used\n- urn:wallet-rielta:problem:forbidden (403) - no access to the operation\n- urn:wallet-rielta:problem:request-too-large (413) - request body is too large\n- urn:wallet-rielta:problem:rate-limited (429) - too many requests, see the Retry-After header\n- urn:wallet-rielta:problem:internal (500) - internal server error\n\nRequests are authenticated with the X-API-Key header. Keys with \"require-signature\" instead send\nX-API-Key-ID, X-Timestamp (unix seconds), X-Nonce and X-Signature headers, where the signature is\nhex HMAC-SHA256 keyed with the signing secret printed when the key is created over the lines:\nmethod, path with query, timestamp, nonce, hex sha256 of the body.\n\nEnd users authenticate with \"Authorization: Bearer <jwt>\" signed with RS256 or ES256.\nUsers can send funds from and read history of only the wallets they own.\n\nThe /admin api is granted by roles of the api key or the \"roles\" token claim:\nsupport can search wallets, operator can also freeze wallets and adjust balances,\nadmin can also reverse transactions and read the audit log. Every admin operation requires a reason.\n\nRequests are rate limited for every api key, token subject or client ip with a token bucket.\nResponses contain RateLimit-Policy, RateLimit-Limit, RateLimit-Remaining and RateLimit-Reset headers.\nState-changing operations are written to the hash-chained audit log with the caller, request id and ip.",
 	InfoInstanceName: "swagger",
 	SwaggerTemplate:  docTemplate,
 	LeftDelim:        "{{",
@@ -0,0 +1,231 @@
+package app
+
+import (
+	"WalletRieltaTestTask/config"
+	"WalletRieltaTestTask/internal/entity"
+	grpcV1 "WalletRieltaTestTask/internal/wallet/controller/grpc/v1"
+	v1 "WalletRieltaTestTask/internal/wallet/controller/http/v1"
+	gateway "WalletRieltaTestTask/internal/wallet/gateway/rabbitmq"
+	walletUC "WalletRieltaTestTask/internal/wallet/usecase"
+	"WalletRieltaTestTask/pkg/grpcserver"
+	"WalletRieltaTestTask/pkg/httpserver"
+	"WalletRieltaTestTask/pkg/jwks"
+	"WalletRieltaTestTask/pkg/logger"
+	"WalletRieltaTestTask/pkg/rabbitmq/rmq_rpc"
+	"WalletRieltaTestTask/pkg/rabbitmq/rmq_rpc/client"
+	"WalletRieltaTestTask/pkg/rabbitmq/subscriber"
+	"WalletRieltaTestTask/pkg/ratelimit"
+	"github.com/gin-gonic/gin"
+	"log/slog"
+)
+
+// API - http and grpc servers, the RMQClient and the EventSubscriber are nil in the direct gateway mode.
+type API struct {
+	HTTPServer      *httpserver.Server
+	GRPCServer      *grpcserver.Server
+	RMQClient       *client.Client
+	EventSubscriber *subscriber.Subscriber
+	WalletEvents    *walletUC.WalletEventsUseCase
+}
+
+// Gateways of the api use cases, implemented over the rabbitMQ RPC or by direct calls.
+type apiGateways struct {
+	wallet   walletUC.WalletGateway
+	auth     walletUC.AuthGateway
+	admin    walletUC.AdminGateway
+	webhooks walletUC.WebhookGateway
+}
+
+// NewAPI - the api calls the worker through the rabbitMQ RPC, so it doesn't connect to postgres.
+func NewAPI(log *slog.Logger, cfg *config.Config) *API {
+	rmqClient, err := client.NewRabbitMQClient(
+		cfg.RMQ.URL,
+		cfg.RMQ.ServerExchange,
+		rmqClientOptions(cfg)...,
+	)
+	if err != nil {
+		panic("app - Run - client.NewRabbitMQClient: " + err.Error())
+	}
+
+	api := newAPI(log, cfg, apiGateways{
+		wallet:   gateway.New(rmqClient),
+		auth:     gateway.NewAuth(rmqClient),
+		admin:    gateway.NewAdmin(rmqClient),
+		webhooks: gateway.NewWebhooks(rmqClient),
+	})
+
+	api.RMQClient = rmqClient
+
+	// Init wallet events subscriber, subscribers of the stream resume if events were missed
+	api.EventSubscriber, err = subscriber.New(
+		cfg.RMQ.URL,
+		cfg.RMQ.EventsExchange,
+		[]string{"#"},
+		gateway.EventHandler(api.WalletEvents.Notify, log),
+		log,
+		subscriber.OnReconnect(api.WalletEvents.Resync),
+	)
+	if err != nil {
+		panic("app - Run - subscriber.New: " + err.Error())
+	}
+
+	return api
+}
+
+func newAPI(log *slog.Logger, cfg *config.Config, gateways apiGateways) *API {
+	// Use cases
+	walletUseCase := walletUC.NewWallet(
+		gateways.wallet,
+		walletUC.Timeout(cfg.App.Timeout),
+		walletUC.DefaultBalance(cfg.App.DefaultBalance),
+	)
+
+	walletEventsUseCase := walletUC.NewWalletEvents(
+		gateways.wallet,
+		walletUC.EventsBuffer(cfg.Stream.Buffer),
+	)
+
+	authOpts := []walletUC.AuthOption{
+		walletUC.SignatureMaxSkew(cfg.Auth.SignatureMaxSkew),
+	}
+
+	// End user tokens are accepted only if the key set is configured
+	if cfg.JWT.JWKSPath != "" {
+		keySet, err := jwks.Load(cfg.JWT.JWKSPath)
+		if err != nil {
+			panic("app - Run - jwks.Load: " + err.Error())
+		}
+
+		authOpts = append(authOpts, walletUC.JWT(keySet.Keyfunc, cfg.JWT.Issuer, cfg.JWT.Audience))
+	}
+
+	authUseCase := walletUC.NewAuth(gateways.auth, authOpts...)
+
+	adminUseCase := walletUC.NewAdmin(gateways.admin)
+
+	webhookUseCase := walletUC.NewWebhooks(gateways.webhooks)
+
+	// Init http server
+	limits := rateLimits(cfg.RateLimit)
+
+	handler := gin.New()
+	v1.NewRouter(handler, log, walletUseCase, walletEventsUseCase, authUseCase, adminUseCase, webhookUseCase, limits)
+	httpServer := httpserver.New(log, handler, httpserver.Port(cfg.HTTP.Port), httpserver.WriteTimeout(cfg.HTTP.Timeout))
+
+	// Init grpc server, it shares the limiters with the http server
+	grpcRouter := grpcV1.NewRouter(log, walletUseCase, authUseCase, grpcV1.RateLimits(limits))
+	grpcServer := grpcserver.New(log, grpcRouter, grpcserver.Port(cfg.GRPC.Port))
+
+	return &API{
+		HTTPServer:   httpServer,
+		GRPCServer:   grpcServer,
+		WalletEvents: walletEventsUseCase,
+	}
+}
+
+func (a *API) Run() {
+	go func() {
+		a.HTTPServer.MustRun()
+	}()
+
+	go func() {
+		a.GRPCServer.MustRun()
+	}()
+
+	if a.EventSubscriber != nil {
+		a.EventSubscriber.MustRun()
+	}
+}
+
+// Notify - errors of the RPC client, nil without the client, so receiving from it blocks forever.
+func (a *API) Notify() <-chan error {
+	if a.RMQClient == nil {
+		return nil
+	}
+
+	return a.RMQClient.Notify()
+}
+
+func (a *API) Shutdown(log *slog.Logger) {
+	// Event streams would hold the http server until the shutdown timeout
+	a.WalletEvents.Close()
+
+	if err := a.HTTPServer.Shutdown(); err != nil {
+		log.Error("HTTPServer.Shutdown error", logger.Err(err))
+	}
+
+	if err := a.GRPCServer.Shutdown(); err != nil {
+		log.Error("GRPCServer.Shutdown error", logger.Err(err))
+	}
+
+	if a.EventSubscriber != nil {
+		if err := a.EventSubscriber.Shutdown(); err != nil {
+			log.Error("EventSubscriber.Shutdown error", logger.Err(err))
+		}
+	}
+
+	// The servers are stopped, so no calls are left to wait for
+	if a.RMQClient != nil {
+		if err := a.RMQClient.Shutdown(); err != nil {
+			log.Error("RMQClient.Shutdown error", logger.Err(err))
+		}
+	}
+}
+
+// Builds limiters of the http api from the config, limiting is turned off if it's disabled.
+func rateLimits(cfg config.RateLimit) v1.RateLimits {
+	if !cfg.Enabled {
+		return v1.RateLimits{}
+	}
+
+	if cfg.Requests <= 0 || cfg.Period <= 0 {
+		panic("rate limit must have positive requests and period")
+	}
+
+	if cfg.IPRequests <= 0 || cfg.IPPeriod <= 0 {
+		panic("ip rate limit must have positive requests and period")
+	}
+
+	limits := v1.RateLimits{
+		Default: ratelimit.New(cfg.Requests, cfg.Period, ratelimit.Burst(cfg.Burst)),
+		Routes:  make(map[string]*ratelimit.Limiter, len(cfg.Routes)),
+		IP:      ratelimit.New(cfg.IPRequests, cfg.IPPeriod),
+	}
+
+	for route, rule := range cfg.Routes {
+		if rule.Requests <= 0 || rule.Period <= 0 {
+			panic("rate limit of the route " + route + " must have positive requests and period")
+		}
+		limits.Routes[route] = ratelimit.New(rule.Requests, rule.Period, ratelimit.Burst(rule.Burst))
+	}
+
+	return limits
+}
+
+// The worker replies in the codec and compression chosen by the api.
+func rmqClientOptions(cfg *config.Config) []client.Option {
+	opts := []client.Option{
+		client.Errors(entity.RPCErrors),
+		client.Channels(cfg.RMQ.Channels),
+	}
+
+	switch cfg.RMQ.Codec {
+	case "json":
+	case "msgpack":
+		opts = append(opts, client.Codecs(rmq_rpc.MessagePack(), rmq_rpc.JSON()))
+	default:
+		panic("app - rmqClientOptions - unknown rpc codec: " + cfg.RMQ.Codec)
+	}
+
+	switch cfg.RMQ.Compression {
+	case "":
+	case "gzip":
+		opts = append(opts, client.Compression(cfg.RMQ.CompressionThreshold, rmq_rpc.Gzip()))
+	case "zstd":
+		opts = append(opts, client.Compression(cfg.RMQ.CompressionThreshold, rmq_rpc.Zstd()))
+	default:
+		panic("app - rmqClientOptions - unknown rpc compression: " + cfg.RMQ.Compression)
+	}
+
+	return opts
+}
@@ -2,72 +2,57 @@ package app
 
 import (
 	"WalletRieltaTestTask/config"
-	v1 "WalletRieltaTestTask/internal/wallet/controller/http/v1"
-	gateway "WalletRieltaTestTask/internal/wallet/gateway/rabbitmq"
-	walletUseCase "WalletRieltaTestTask/internal/wallet/usecase"
-	"WalletRieltaTestTask/internal/walletWorker/controller/amqp_rpc"
-	worker_postgres "WalletRieltaTestTask/internal/walletWorker/repository/postgres"
-	workerUC "WalletRieltaTestTask/internal/walletWorker/usecase"
-	"WalletRieltaTestTask/pkg/httpserver"
-	"WalletRieltaTestTask/pkg/postgres"
-	"WalletRieltaTestTask/pkg/rabbitmq/rmq_rpc/client"
-	"WalletRieltaTestTask/pkg/rabbitmq/rmq_rpc/server"
-	"github.com/gin-gonic/gin"
-	_ "github.com/lib/pq"
+	directGateway "WalletRieltaTestTask/internal/wallet/gateway/direct"
+	directWorkerGateway "WalletRieltaTestTask/internal/walletWorker/gateway/direct"
 	"log/slog"
 )
 
+// App - the api and the worker in one process.
 type App struct {
-	HTTPServer *httpserver.Server
-	RMQServer  *server.Server
-	DB         *postgres.Postgres
+	*API
+	*Worker
 }
 
+// New - in the direct gateway mode the api calls the worker use cases without the rabbitMQ RPC,
+// so only postgres is needed.
 func New(log *slog.Logger, cfg *config.Config) *App {
-	// Connect postgres db
-	pg, err := postgres.NewPostgresDB(cfg.PG.URL, postgres.MaxPoolSize(cfg.PG.PoolMax))
-	if err != nil {
-		panic("app - Run - postgres.NewPostgresDB: " + err.Error())
+	switch cfg.Gateway.Mode {
+	case config.GatewayRabbitMQ:
+		return &App{
+			API:    NewAPI(log, cfg),
+			Worker: NewWorker(log, cfg),
+		}
+	case config.GatewayDirect:
+		worker := &Worker{DB: connectPostgres(cfg)}
+
+		useCases := newWorkerUseCases(worker.DB, cfg)
+
+		api := newAPI(log, cfg, apiGateways{
+			wallet:   directGateway.New(useCases.wallet),
+			auth:     directGateway.NewAuth(useCases.apiKey),
+			admin:    directGateway.NewAdmin(useCases.admin, useCases.audit),
+			webhooks: directGateway.NewWebhooks(useCases.webhook),
+		})
+
+		// Events of the outbox are passed straight to the subscribers of the stream
+		worker.initLoops(log, cfg, useCases, directWorkerGateway.NewEvents(api.WalletEvents.Notify))
+
+		return &App{
+			API:    api,
+			Worker: worker,
+		}
+	default:
+		panic("app - Run - unknown gateway mode: " + cfg.Gateway.Mode)
 	}
+}
 
-	rmqClient, err := client.NewRabbitMQClient(cfg.RMQ.URL, cfg.RMQ.ServerExchange, cfg.RMQ.ClientExchange)
-	if err != nil {
-		panic("app - Run - rmqServer - server.New" + err.Error())
-	}
-
-	// Use cases
-	walletUseCase := walletUseCase.NewWallet(
-		gateway.New(rmqClient),
-		walletUseCase.Timeout(cfg.App.Timeout),
-		walletUseCase.DefaultBalance(cfg.App.DefaultBalance),
-	)
-
-	workerUseCase := workerUC.NewWalletWorker(
-		worker_postgres.New(pg),
-	)
-
-	// Init http server
-	handler := gin.New()
-	v1.NewRouter(handler, log, walletUseCase)
-	httpServer := httpserver.New(log, handler, httpserver.Port(cfg.HTTP.Port), httpserver.WriteTimeout(cfg.HTTP.Timeout))
-
-	// Init rabbitMQ RPC Server
-	rmqRouter := amqp_rpc.NewRouter(workerUseCase)
-
-	rmqServer, err := server.New(
-		cfg.RMQ.URL,
-		cfg.RMQ.ServerExchange,
-		rmqRouter,
-		log,
-		server.DefaultGoroutinesCount(cfg.App.CountWorkers),
-	)
-	if err != nil {
-		panic("app - Run - rmqServer - server.New" + err.Error())
-	}
+func (a *App) Run() {
+	a.API.Run()
+	a.Worker.Run()
+}
 
-	return &App{
-		HTTPServer: httpServer,
-		RMQServer:  rmqServer,
-		DB:         pg,
-	}
+// Shutdown - the api is stopped first, so the worker finishes the calls it has already received.
+func (a *App) Shutdown(log *slog.Logger) {
+	a.API.Shutdown(log)
+	a.Worker.Shutdown(log)
 }
@@ -0,0 +1,279 @@
+package app
+
+import (
+	"WalletRieltaTestTask/config"
+	"WalletRieltaTestTask/internal/entity"
+	v1 "WalletRieltaTestTask/internal/wallet/controller/http/v1"
+	gateway "WalletRieltaTestTask/internal/wallet/gateway/rabbitmq"
+	walletUC "WalletRieltaTestTask/internal/wallet/usecase"
+	"WalletRieltaTestTask/internal/walletWorker/controller/amqp_rpc"
+	"WalletRieltaTestTask/pkg/rabbitmq/rmq_rpc"
+	"WalletRieltaTestTask/pkg/rabbitmq/rmq_rpc/client"
+	"WalletRieltaTestTask/pkg/rabbitmq/rmq_rpc/server"
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+const _testAPIKey = "key-1.secret"
+
+// Wallets of the worker kept in memory instead of postgres.
+type walletWorker struct {
+	mu       sync.Mutex
+	balances map[string]uint
+	caller   entity.Caller
+	key      string
+}
+
+func (w *walletWorker) CreateNewWalletWithBalance(
+	context.Context,
+	uint,
+	string,
+	entity.Caller,
+) (*entity.Wallet, error) {
+	return nil, errors.New("not implemented")
+}
+
+func (w *walletWorker) SendFunds(
+	_ context.Context,
+	from string,
+	to string,
+	amount uint,
+	idempotencyKey string,
+	caller entity.Caller,
+) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	balance, ok := w.balances[from]
+	if !ok {
+		return entity.ErrWalletNotFound
+	}
+
+	if balance < amount {
+		return entity.ErrNotEnoughFunds
+	}
+
+	w.balances[from] -= amount
+	w.balances[to] += amount
+	w.caller = caller
+	w.key = idempotencyKey
+
+	return nil
+}
+
+func (w *walletWorker) GetWalletHistoryByID(context.Context, string) ([]entity.Transaction, error) {
+	return nil, nil
+}
+
+func (w *walletWorker) GetWalletByID(_ context.Context, walletID string) (*entity.Wallet, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	balance, ok := w.balances[walletID]
+	if !ok {
+		return nil, entity.ErrWalletNotFound
+	}
+
+	return &entity.Wallet{ID: walletID, Balance: balance}, nil
+}
+
+func (w *walletWorker) ListWalletEvents(context.Context, string, string, uint) ([]entity.Event, error) {
+	return nil, nil
+}
+
+// The only key of the worker, it can read and transfer.
+type apiKeyWorker struct{}
+
+func (apiKeyWorker) CreateAPIKey(context.Context, string, []string, []string, bool) (string, *entity.APIKey, error) {
+	return "", nil, errors.New("not implemented")
+}
+
+func (apiKeyWorker) GetAPIKeyByID(_ context.Context, id string) (*entity.APIKey, error) {
+	if id != "key-1" {
+		return nil, entity.ErrUnauthorized
+	}
+
+	return &entity.APIKey{
+		ID:     id,
+		Hash:   entity.HashAPIKey(_testAPIKey),
+		Scopes: []string{entity.ScopeRead, entity.ScopeTransfer},
+	}, nil
+}
+
+func (apiKeyWorker) RegisterNonce(context.Context, string, string, time.Time) (bool, error) {
+	return true, nil
+}
+
+// The http api and the worker connected by the memory broker with the options of the config.
+func newTestAPI(t *testing.T, cfg *config.Config, worker *walletWorker) http.Handler {
+	t.Helper()
+
+	log := slog.New(slog.NewTextHandler(io.Discard, nil))
+	broker := rmq_rpc.NewMemoryBroker()
+
+	rmqServer := server.NewWithTransport(
+		broker.Server(cfg.RMQ.ServerExchange),
+		amqp_rpc.NewRouter(worker, apiKeyWorker{}, nil, nil, nil),
+		log,
+		append(rmqServerOptions(cfg), server.Timeout(10*time.Millisecond))...,
+	)
+	rmqServer.MustRun()
+
+	rmqClient := client.New(
+		broker.Client(cfg.RMQ.ServerExchange),
+		append(rmqClientOptions(cfg), client.Timeout(time.Second))...,
+	)
+
+	t.Cleanup(func() {
+		_ = rmqClient.Shutdown()
+		_ = rmqServer.Shutdown()
+	})
+
+	gin.SetMode(gin.TestMode)
+
+	handler := gin.New()
+	v1.NewRouter(handler, log,
+		walletUC.NewWallet(gateway.New(rmqClient)),
+		walletUC.NewWalletEvents(gateway.New(rmqClient)),
+		walletUC.NewAuth(gateway.NewAuth(rmqClient)),
+		walletUC.NewAdmin(gateway.NewAdmin(rmqClient)),
+		walletUC.NewWebhooks(gateway.NewWebhooks(rmqClient)),
+		v1.RateLimits{},
+	)
+
+	return handler
+}
+
+func request(handler http.Handler, method, path, key, body string) *httptest.ResponseRecorder {
+	r := httptest.NewRequest(method, path, strings.NewReader(body))
+	r.Header.Set("Content-Type", "application/json")
+	r.Header.Set("X-API-Key", key)
+	r.Header.Set("Idempotency-Key", "transfer-1")
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, r)
+
+	return w
+}
+
+func TestHTTPToWorkerOverRPC(t *testing.T) {
+	for _, codec := range []string{"json", "msgpack"} {
+		for _, compression := range []string{"", "gzip", "zstd"} {
+			t.Run(codec+" "+compression, func(t *testing.T) {
+				cfg := &config.Config{}
+				cfg.App.CountWorkers = 4
+				cfg.RMQ.ServerExchange = "rpc_server"
+				cfg.RMQ.AckAfterCall = true
+				cfg.RMQ.MaxAttempts = 3
+				cfg.RMQ.Codec = codec
+				cfg.RMQ.Compression = compression
+				cfg.RMQ.CompressionThreshold = 1
+
+				worker := &walletWorker{balances: map[string]uint{"alice": 100, "bob": 0}}
+				handler := newTestAPI(t, cfg, worker)
+
+				w := request(handler, http.MethodPost, "/api/v1/wallet/alice/send", _testAPIKey, `{"to":"bob","amount":30}`)
+				if w.Code != http.StatusOK {
+					t.Fatalf("send = %d %s, want 200", w.Code, w.Body)
+				}
+
+				// The caller and the idempotency key cross the queue with the call
+				if worker.caller.Actor == "" || !strings.HasSuffix(worker.key, "transfer-1") {
+					t.Fatalf("worker got the caller %+v and the key %q", worker.caller, worker.key)
+				}
+
+				w = request(handler, http.MethodGet, "/api/v1/wallet/bob", _testAPIKey, "")
+				if w.Code != http.StatusOK {
+					t.Fatalf("get = %d %s, want 200", w.Code, w.Body)
+				}
+
+				var wallet entity.Wallet
+				if err := json.Unmarshal(w.Body.Bytes(), &wallet); err != nil || wallet.Balance != 30 {
+					t.Fatalf("wallet = %s, want the balance of 30", w.Body)
+				}
+
+				// Errors of the worker are restored by the codes and mapped to the problems
+				for _, tt := range []struct {
+					path, key, body string
+					status          int
+					problem         string
+				}{
+					{"/api/v1/wallet/alice/send", _testAPIKey, `{"to":"bob","amount":300}`, http.StatusConflict, "not-enough-funds"},
+					{"/api/v1/wallet/carol/send", _testAPIKey, `{"to":"bob","amount":1}`, http.StatusNotFound, "wallet-not-found"},
+					{"/api/v1/wallet/alice/send", "key-1.wrong", `{"to":"bob","amount":1}`, http.StatusUnauthorized, "unauthorized"},
+				} {
+					w = request(handler, http.MethodPost, tt.path, tt.key, tt.body)
+					if w.Code != tt.status || !strings.Contains(w.Body.String(), "problem:"+tt.problem) {
+						t.Fatalf("%s = %d %s, want %d %s", tt.path, w.Code, w.Body, tt.status, tt.problem)
+					}
+				}
+			})
+		}
+	}
+}
+
+// The worker compresses the replies only by the compression of the config, even if the api accepts others.
+func TestRMQServerOptionsCompression(t *testing.T) {
+	for compression, want := range map[string]string{"": "", "gzip": "gzip", "zstd": "zstd"} {
+		t.Run("compression="+compression, func(t *testing.T) {
+			cfg := &config.Config{}
+			cfg.App.CountWorkers = 1
+			cfg.RMQ.MaxAttempts = 1
+			cfg.RMQ.Compression = compression
+			cfg.RMQ.CompressionThreshold = 1
+
+			broker := rmq_rpc.NewMemoryBroker()
+			worker := &walletWorker{balances: map[string]uint{"alice": 100}}
+
+			rmqServer := server.NewWithTransport(
+				broker.Server("rpc_server"),
+				amqp_rpc.NewRouter(worker, apiKeyWorker{}, nil, nil, nil),
+				slog.New(slog.NewTextHandler(io.Discard, nil)),
+				append(rmqServerOptions(cfg), server.Timeout(10*time.Millisecond))...,
+			)
+			rmqServer.MustRun()
+
+			t.Cleanup(func() { _ = rmqServer.Shutdown() })
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			replies := make(chan rmq_rpc.Message, 1)
+
+			transport := broker.Client("rpc_server")
+			transport.Consume(ctx, func(reply rmq_rpc.Message) { replies <- reply }, nil)
+
+			err := transport.Publish(ctx, rmq_rpc.Message{
+				Type:        "getWalletByID",
+				ContentType: rmq_rpc.JSON().ContentType(),
+				Headers: map[string]interface{}{
+					rmq_rpc.AcceptHeader:         rmq_rpc.JSON().ContentType(),
+					rmq_rpc.AcceptEncodingHeader: "zstd,gzip",
+				},
+				Body: []byte(`{"walletId":"alice"}`),
+			})
+			if err != nil {
+				t.Fatalf("Publish: %v", err)
+			}
+
+			select {
+			case reply := <-replies:
+				if reply.Type != server.Success || reply.ContentEncoding != want {
+					t.Fatalf("reply = %s encoded by %q, want success encoded by %q", reply.Type, reply.ContentEncoding, want)
+				}
+			case <-time.After(time.Second):
+				t.Fatal("no reply")
+			}
+		})
+	}
+}
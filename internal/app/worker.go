@@ -0,0 +1,246 @@
+package app
+
+import (
+	"WalletRieltaTestTask/config"
+	"WalletRieltaTestTask/internal/entity"
+	"WalletRieltaTestTask/internal/walletWorker/controller/amqp_rpc"
+	"WalletRieltaTestTask/internal/walletWorker/controller/background"
+	webhookGateway "WalletRieltaTestTask/internal/walletWorker/gateway/http"
+	workerGateway "WalletRieltaTestTask/internal/walletWorker/gateway/rabbitmq"
+	worker_postgres "WalletRieltaTestTask/internal/walletWorker/repository/postgres"
+	workerUC "WalletRieltaTestTask/internal/walletWorker/usecase"
+	"WalletRieltaTestTask/pkg/httpserver"
+	"WalletRieltaTestTask/pkg/logger"
+	"WalletRieltaTestTask/pkg/postgres"
+	"WalletRieltaTestTask/pkg/rabbitmq/publisher"
+	"WalletRieltaTestTask/pkg/rabbitmq/rmq_rpc"
+	"WalletRieltaTestTask/pkg/rabbitmq/rmq_rpc/server"
+	"WalletRieltaTestTask/pkg/sealer"
+	_ "github.com/lib/pq"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"log/slog"
+)
+
+// Worker - the RMQServer, the Publisher and the MetricsServer are nil in the direct gateway mode.
+type Worker struct {
+	RMQServer         *server.Server
+	MetricsServer     *httpserver.Server
+	OutboxRelay       *background.Loop
+	WebhookDispatcher *background.Loop
+	Publisher         *publisher.Publisher
+	DB                *postgres.Postgres
+}
+
+type workerUseCases struct {
+	wallet            *workerUC.WalletWorkerUseCase
+	apiKey            *workerUC.APIKeyWorkerUseCase
+	admin             *workerUC.AdminWorkerUseCase
+	audit             *workerUC.AuditWorkerUseCase
+	webhook           *workerUC.WebhookWorkerUseCase
+	webhookDispatcher *workerUC.WebhookDispatcherUseCase
+}
+
+// NewWorker - the worker serves the rabbitMQ RPC calls of the api and publishes wallet events.
+func NewWorker(log *slog.Logger, cfg *config.Config) *Worker {
+	w := &Worker{DB: connectPostgres(cfg)}
+
+	useCases := newWorkerUseCases(w.DB, cfg)
+
+	var err error
+
+	w.Publisher, err = publisher.New(cfg.RMQ.URL, cfg.RMQ.EventsExchange)
+	if err != nil {
+		panic("app - Run - publisher.New: " + err.Error())
+	}
+
+	// Init rabbitMQ RPC Server
+	rmqRouter := amqp_rpc.NewRouter(
+		useCases.wallet,
+		useCases.apiKey,
+		useCases.admin,
+		useCases.audit,
+		useCases.webhook,
+	)
+
+	w.RMQServer, err = server.New(
+		cfg.RMQ.URL,
+		cfg.RMQ.ServerExchange,
+		rmqRouter,
+		log,
+		rmqServerOptions(cfg)...,
+	)
+	if err != nil {
+		panic("app - Run - rmqServer - server.New" + err.Error())
+	}
+
+	w.initLoops(log, cfg, useCases, workerGateway.NewEvents(w.Publisher))
+
+	// Metrics of the rabbitMQ connections
+	w.MetricsServer = httpserver.New(log, promhttp.Handler(), httpserver.Port(cfg.Metrics.Port))
+
+	return w
+}
+
+func connectPostgres(cfg *config.Config) *postgres.Postgres {
+	if cfg.PG.URL == "" {
+		panic("app - Run - PG_URL is required")
+	}
+
+	pg, err := postgres.NewPostgresDB(cfg.PG.URL, postgres.MaxPoolSize(cfg.PG.PoolMax))
+	if err != nil {
+		panic("app - Run - postgres.NewPostgresDB: " + err.Error())
+	}
+
+	return pg
+}
+
+func newWorkerUseCases(pg *postgres.Postgres, cfg *config.Config) *workerUseCases {
+	webhookRepo := worker_postgres.NewWebhookRepo(pg)
+
+	secrets, err := sealer.New(cfg.Auth.SecretKey)
+	if err != nil {
+		panic("app - Run - sealer.New: AUTH_SECRET_KEY: " + err.Error())
+	}
+
+	return &workerUseCases{
+		wallet: workerUC.NewWalletWorker(
+			worker_postgres.New(pg),
+		),
+		apiKey: workerUC.NewAPIKeyWorker(
+			worker_postgres.NewAPIKeyRepo(pg),
+			secrets,
+		),
+		admin: workerUC.NewAdminWorker(
+			worker_postgres.NewAdminRepo(pg),
+		),
+		audit: workerUC.NewAuditWorker(
+			worker_postgres.NewAuditRepo(pg),
+		),
+		webhook: workerUC.NewWebhookWorker(webhookRepo),
+		webhookDispatcher: workerUC.NewWebhookDispatcher(
+			webhookRepo,
+			webhookGateway.NewWebhook(cfg.Webhooks.Timeout),
+			workerUC.DispatchBatchSize(cfg.Webhooks.BatchSize),
+			workerUC.MaxAttempts(cfg.Webhooks.MaxAttempts),
+			workerUC.Backoff(cfg.Webhooks.BackoffBase, cfg.Webhooks.BackoffMax),
+			workerUC.DispatchLease(2*cfg.Webhooks.Timeout),
+		),
+	}
+}
+
+// Init background loops, the outbox relay passes wallet events to the events publisher.
+func (w *Worker) initLoops(
+	log *slog.Logger,
+	cfg *config.Config,
+	useCases *workerUseCases,
+	events workerUC.EventPublisher,
+) {
+	outboxRelayUseCase := workerUC.NewOutboxRelay(
+		worker_postgres.NewOutboxRepo(w.DB),
+		events,
+		workerUC.BatchSize(cfg.Outbox.BatchSize),
+	)
+
+	w.OutboxRelay = background.New(
+		"outbox relay",
+		outboxRelayUseCase.RelayPending,
+		log,
+		background.Interval(cfg.Outbox.Interval),
+	)
+
+	w.WebhookDispatcher = background.New(
+		"webhook dispatcher",
+		useCases.webhookDispatcher.DispatchPending,
+		log,
+		background.Interval(cfg.Webhooks.Interval),
+	)
+}
+
+func (w *Worker) Run() {
+	if w.RMQServer != nil {
+		go func() {
+			w.RMQServer.MustRun()
+		}()
+	}
+
+	if w.MetricsServer != nil {
+		go func() {
+			w.MetricsServer.MustRun()
+		}()
+	}
+
+	w.OutboxRelay.MustRun()
+	w.WebhookDispatcher.MustRun()
+}
+
+// Notify - errors of the RPC server, nil without the server, so receiving from it blocks forever.
+func (w *Worker) Notify() <-chan error {
+	if w.RMQServer == nil {
+		return nil
+	}
+
+	return w.RMQServer.Notify()
+}
+
+func (w *Worker) Shutdown(log *slog.Logger) {
+	if w.RMQServer != nil {
+		if err := w.RMQServer.Shutdown(); err != nil {
+			log.Error("RMQServer.Shutdown error", logger.Err(err))
+		}
+	}
+
+	if w.MetricsServer != nil {
+		if err := w.MetricsServer.Shutdown(); err != nil {
+			log.Error("MetricsServer.Shutdown error", logger.Err(err))
+		}
+	}
+
+	if err := w.OutboxRelay.Shutdown(); err != nil {
+		log.Error("OutboxRelay.Shutdown error", logger.Err(err))
+	}
+
+	if err := w.WebhookDispatcher.Shutdown(); err != nil {
+		log.Error("WebhookDispatcher.Shutdown error", logger.Err(err))
+	}
+
+	if w.Publisher != nil {
+		if err := w.Publisher.Close(); err != nil {
+			log.Error("Publisher.Close error", logger.Err(err))
+		}
+	}
+
+	w.DB.Close()
+}
+
+// Calls failed because of postgres are retried, replies are encoded as the api accepts
+// and compressed by the compression of the config.
+func rmqServerOptions(cfg *config.Config) []server.Option {
+	opts := []server.Option{
+		server.DefaultGoroutinesCount(cfg.App.CountWorkers),
+		server.AckAfterCall(cfg.RMQ.AckAfterCall),
+		server.MaxAttempts(cfg.RMQ.MaxAttempts),
+		server.Transient(postgres.IsTransient),
+		server.Errors(entity.RPCErrors),
+	}
+
+	switch cfg.RMQ.Compression {
+	case "":
+		opts = append(opts, server.Compression(0))
+	case "gzip":
+		opts = append(opts, server.Compression(cfg.RMQ.CompressionThreshold, rmq_rpc.Gzip()))
+	case "zstd":
+		opts = append(opts, server.Compression(cfg.RMQ.CompressionThreshold, rmq_rpc.Zstd()))
+	default:
+		panic("app - rmqServerOptions - unknown rpc compression: " + cfg.RMQ.Compression)
+	}
+
+	if cfg.RMQ.Prefetch > 0 {
+		opts = append(opts, server.Prefetch(cfg.RMQ.Prefetch))
+	}
+
+	if cfg.RMQ.DeadLetterExchange != "" {
+		opts = append(opts, server.DeadLetterExchange(cfg.RMQ.DeadLetterExchange))
+	}
+
+	return opts
+}
@@ -0,0 +1,26 @@
+package entity
+
+type SearchWalletsRequest struct {
+	Filter WalletFilter `json:"filter"`
+}
+
+type AdjustBalanceRequest struct {
+	WalletID string      `json:"walletId"`
+	Amount   int64       `json:"amount"`
+	Action   AdminAction `json:"action"`
+}
+
+type SetWalletFrozenRequest struct {
+	WalletID string      `json:"walletId"`
+	Frozen   bool        `json:"frozen"`
+	Action   AdminAction `json:"action"`
+}
+
+type ReverseTransactionRequest struct {
+	TransactionID int64       `json:"transactionId"`
+	Action        AdminAction `json:"action"`
+}
+
+type SearchAuditRequest struct {
+	Filter AuditFilter `json:"filter"`
+}
@@ -0,0 +1,75 @@
+package entity
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// API key scopes.
+const (
+	ScopeRead     = "read"
+	ScopeTransfer = "transfer"
+	ScopeAdmin    = "admin"
+)
+
+type APIKey struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+	Hash string `json:"hash"`
+	// SigningSecret - secret of the HMAC request signatures, it's stored encrypted in SealedSigningSecret
+	// and is empty for keys created before signing secrets were introduced.
+	SigningSecret       string     `json:"signingSecret,omitempty"`
+	SealedSigningSecret []byte     `json:"-"`
+	Scopes              []string   `json:"scopes"`
+	Roles               []string   `json:"roles"`
+	RequireSignature    bool       `json:"requireSignature"`
+	CreatedAt           time.Time  `json:"createdAt"`
+	RevokedAt           *time.Time `json:"revokedAt,omitempty"`
+}
+
+func (k *APIKey) Revoked() bool {
+	return k.RevokedAt != nil
+}
+
+func (k *APIKey) Principal() *Principal {
+	return &Principal{
+		KeyID:  k.ID,
+		Scopes: k.Scopes,
+		Roles:  k.Roles,
+	}
+}
+
+// HashAPIKey - hex encoded sha256 of the key, the only form keys are stored in.
+func HashAPIKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+
+	return hex.EncodeToString(sum[:])
+}
+
+// SignedRequest - parts of the http request covered by the HMAC signature.
+type SignedRequest struct {
+	KeyID     string
+	Timestamp time.Time
+	Nonce     string
+	Signature string
+	Method    string
+	Path      string
+	Body      []byte
+}
+
+// Payload - canonical string to sign:
+// method, path, unix timestamp, nonce and hex sha256 of the body separated by new lines.
+func (r *SignedRequest) Payload() []byte {
+	bodyHash := sha256.Sum256(r.Body)
+
+	return []byte(strings.Join([]string{
+		r.Method,
+		r.Path,
+		strconv.FormatInt(r.Timestamp.Unix(), 10),
+		r.Nonce,
+		hex.EncodeToString(bodyHash[:]),
+	}, "\n"))
+}
@@ -0,0 +1,153 @@
+package entity
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// Audited actions.
+const (
+	ActionWalletCreated       = "wallet.created"
+	ActionFundsSent           = "funds.sent"
+	ActionBalanceAdjusted     = "balance.adjusted"
+	ActionWalletFrozen        = "wallet.frozen"
+	ActionWalletUnfrozen      = "wallet.unfrozen"
+	ActionTransactionReversed = "transaction.reversed"
+)
+
+// AuditEntry - record of the append-only audit log.
+// Every entry contains the hash of the previous one, so changing or deleting
+// any entry breaks the chain.
+type AuditEntry struct {
+	ID            int64           `json:"id"`
+	Time          time.Time       `json:"time"`
+	Actor         string          `json:"actor"`
+	Action        string          `json:"action"`
+	WalletID      string          `json:"walletId,omitempty"`
+	TransactionID int64           `json:"transactionId,omitempty"`
+	RequestID     string          `json:"requestId,omitempty"`
+	IP            string          `json:"ip,omitempty"`
+	PayloadHash   string          `json:"payloadHash,omitempty"`
+	Balances      []BalanceChange `json:"balances,omitempty"`
+	Reason        string          `json:"reason,omitempty"`
+	Details       json.RawMessage `json:"details,omitempty" swaggertype:"object"`
+	PrevHash      string          `json:"prevHash"`
+	Hash          string          `json:"hash"`
+}
+
+// AuditChainReport - result of the audit log verification.
+// Legacy entries were written before the chain was introduced, they have no hashes and aren't verified.
+type AuditChainReport struct {
+	Checked int    `json:"checked"`
+	Legacy  int    `json:"legacy"`
+	Head    string `json:"head"`
+}
+
+// BalanceChange - wallet balance before and after the audited operation.
+type BalanceChange struct {
+	WalletID string `json:"walletId"`
+	Before   uint   `json:"before"`
+	After    uint   `json:"after"`
+}
+
+// ComputeHash - sha256 over the canonical json of the entry fields and the previous hash.
+// The result doesn't depend on how the storage formats json details.
+func (e *AuditEntry) ComputeHash() (string, error) {
+	details, err := canonicalJSON(e.Details)
+	if err != nil {
+		return "", fmt.Errorf("canonicalJSON: %w", err)
+	}
+
+	data, err := json.Marshal(struct {
+		PrevHash      string          `json:"prevHash"`
+		Time          string          `json:"time"`
+		Actor         string          `json:"actor"`
+		Action        string          `json:"action"`
+		WalletID      string          `json:"walletId"`
+		TransactionID int64           `json:"transactionId"`
+		RequestID     string          `json:"requestId"`
+		IP            string          `json:"ip"`
+		PayloadHash   string          `json:"payloadHash"`
+		Balances      []BalanceChange `json:"balances"`
+		Reason        string          `json:"reason"`
+		Details       json.RawMessage `json:"details"`
+	}{
+		PrevHash:      e.PrevHash,
+		Time:          e.Time.UTC().Format(time.RFC3339Nano),
+		Actor:         e.Actor,
+		Action:        e.Action,
+		WalletID:      e.WalletID,
+		TransactionID: e.TransactionID,
+		RequestID:     e.RequestID,
+		IP:            e.IP,
+		PayloadHash:   e.PayloadHash,
+		Balances:      e.Balances,
+		Reason:        e.Reason,
+		Details:       details,
+	})
+	if err != nil {
+		return "", fmt.Errorf("json.Marshal: %w", err)
+	}
+
+	sum := sha256.Sum256(data)
+
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// Objects keys are sorted and whitespaces are removed.
+func canonicalJSON(data json.RawMessage) (json.RawMessage, error) {
+	if len(data) == 0 {
+		return json.RawMessage("{}"), nil
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+
+	var v interface{}
+	if err := decoder.Decode(&v); err != nil {
+		return nil, err //nolint:wrapcheck // wrapped by the caller
+	}
+
+	return json.Marshal(v) //nolint:wrapcheck // wrapped by the caller
+}
+
+// PayloadHash - hex sha256 of the json encoded operation payload.
+func PayloadHash(payload interface{}) string {
+	data, _ := json.Marshal(payload)
+	sum := sha256.Sum256(data)
+
+	return hex.EncodeToString(sum[:])
+}
+
+// AdminAction - who performs the manual operation and why.
+type AdminAction struct {
+	Caller
+	Reason string `json:"reason"`
+}
+
+// AuditFilter - audit log search parameters, empty fields are not filtered.
+type AuditFilter struct {
+	WalletID      string     `json:"walletId,omitempty"`
+	TransactionID int64      `json:"transactionId,omitempty"`
+	Actor         string     `json:"actor,omitempty"`
+	Action        string     `json:"action,omitempty"`
+	RequestID     string     `json:"requestId,omitempty"`
+	From          *time.Time `json:"from,omitempty"`
+	To            *time.Time `json:"to,omitempty"`
+	Limit         uint       `json:"limit"`
+	Offset        uint       `json:"offset"`
+}
+
+// WalletFilter - wallet search parameters, empty fields are not filtered.
+type WalletFilter struct {
+	OwnerID    string `json:"ownerId,omitempty"`
+	Frozen     *bool  `json:"frozen,omitempty"`
+	MinBalance *uint  `json:"minBalance,omitempty"`
+	MaxBalance *uint  `json:"maxBalance,omitempty"`
+	Limit      uint   `json:"limit"`
+	Offset     uint   `json:"offset"`
+}
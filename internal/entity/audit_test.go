@@ -0,0 +1,90 @@
+package entity
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func auditEntry() AuditEntry {
+	return AuditEntry{
+		ID:            1,
+		Time:          time.Date(2024, 5, 1, 12, 30, 0, 123456000, time.UTC),
+		Actor:         "key:key-1",
+		Action:        ActionFundsSent,
+		WalletID:      "alice",
+		TransactionID: 7,
+		RequestID:     "request-1",
+		IP:            "10.0.0.1",
+		PayloadHash:   "payload",
+		Balances:      []BalanceChange{{WalletID: "alice", Before: 100, After: 70}},
+		Details:       json.RawMessage(`{"b":1,"a":"x"}`),
+	}
+}
+
+// Hashes of the stored entries must not change, otherwise the whole chain fails the verification.
+func TestAuditEntryComputeHash(t *testing.T) {
+	const want = "7955f71770d25df37f7f2bd09ffa4a0410f3ba6cbb4ff4d4c0a207de574f4732"
+
+	same := map[string]func(e *AuditEntry){
+		"entry":             func(*AuditEntry) {},
+		"time zone":         func(e *AuditEntry) { e.Time = e.Time.In(time.FixedZone("MSK", 3*60*60)) },
+		"details keys":      func(e *AuditEntry) { e.Details = json.RawMessage(`{"a":"x","b":1}`) },
+		"details spaces":    func(e *AuditEntry) { e.Details = json.RawMessage("{ \"b\": 1,\n \"a\": \"x\" }") },
+		"id of the entry":   func(e *AuditEntry) { e.ID = 2 },
+		"hash of the entry": func(e *AuditEntry) { e.Hash = "stored" },
+	}
+
+	for name, change := range same {
+		t.Run(name, func(t *testing.T) {
+			entry := auditEntry()
+			change(&entry)
+
+			hash, err := entry.ComputeHash()
+			if err != nil || hash != want {
+				t.Fatalf("ComputeHash = %s, %v, want %s", hash, err, want)
+			}
+		})
+	}
+
+	changed := map[string]func(e *AuditEntry){
+		// Postgres keeps microseconds, so the time is truncated before the hash is computed
+		"nanoseconds": func(e *AuditEntry) { e.Time = e.Time.Add(time.Nanosecond) },
+		"prev hash":   func(e *AuditEntry) { e.PrevHash = "prev" },
+		"balance":     func(e *AuditEntry) { e.Balances[0].After = 700 },
+		"details":     func(e *AuditEntry) { e.Details = json.RawMessage(`{"b":2,"a":"x"}`) },
+		"actor":       func(e *AuditEntry) { e.Actor = "key:key-2" },
+	}
+
+	for name, change := range changed {
+		t.Run(name, func(t *testing.T) {
+			entry := auditEntry()
+			change(&entry)
+
+			hash, err := entry.ComputeHash()
+			if err != nil || hash == want {
+				t.Fatalf("ComputeHash = %s, %v, want another hash", hash, err)
+			}
+		})
+	}
+}
+
+// Entries without details are stored with the empty object.
+func TestAuditEntryComputeHashEmptyDetails(t *testing.T) {
+	entry := auditEntry()
+	entry.Details = nil
+
+	empty, _ := entry.ComputeHash()
+
+	entry.Details = json.RawMessage("{}")
+
+	if stored, _ := entry.ComputeHash(); stored != empty {
+		t.Fatalf("hash with {} details = %s, want %s", stored, empty)
+	}
+
+	entry.Details = json.RawMessage("{")
+
+	if _, err := entry.ComputeHash(); err == nil {
+		t.Fatal("ComputeHash of broken details succeeded")
+	}
+}
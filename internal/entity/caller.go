@@ -0,0 +1,37 @@
+package entity
+
+import "context"
+
+// Caller - who made the request and from where, recorded in the audit log.
+type Caller struct {
+	Actor     string `json:"actor"`
+	RequestID string `json:"requestId,omitempty"`
+	IP        string `json:"ip,omitempty"`
+}
+
+type requestInfo struct {
+	id string
+	ip string
+}
+
+type requestInfoKey struct{}
+
+func ContextWithRequestInfo(ctx context.Context, requestID, ip string) context.Context {
+	return context.WithValue(ctx, requestInfoKey{}, requestInfo{requestID, ip})
+}
+
+// CallerFromContext - combines the authenticated principal with the request info.
+func CallerFromContext(ctx context.Context) Caller {
+	var caller Caller
+
+	if p, ok := PrincipalFromContext(ctx); ok {
+		caller.Actor = p.Actor()
+	}
+
+	if info, ok := ctx.Value(requestInfoKey{}).(requestInfo); ok {
+		caller.RequestID = info.id
+		caller.IP = info.ip
+	}
+
+	return caller
+}
@@ -12,8 +12,70 @@ var (
 	ErrWrongAmount      = errors.New("wrong amount")
 	ErrSenderIsReceiver = errors.New("sender is receiver")
 	ErrEmptyWallet      = errors.New("wallet address is empty")
+	ErrNotWalletOwner   = errors.New("wallet belongs to another user")
+	ErrWalletFrozen     = errors.New("wallet is frozen")
+	ErrNotEnoughFunds   = errors.New("not enough funds")
+	ErrBalanceTooLarge  = errors.New("balance would exceed the maximum")
+
+	// Transaction errors.
+	ErrTransactionNotFound    = errors.New("transaction not found")
+	ErrAlreadyReversed        = errors.New("transaction already reversed")
+	ErrReversalNotReversible  = errors.New("reversal transaction can't be reversed")
+	ErrInvalidIdempotencyKey  = errors.New("idempotency key is longer than 255 characters")
+	ErrIdempotencyKeyConflict = errors.New("idempotency key is used by another transfer")
+
+	// Event errors.
+	ErrEventNotFound = errors.New("event not found")
+
+	// Admin errors.
+	ErrReasonRequired = errors.New("reason is required")
+
+	// Webhook errors.
+	ErrWebhookNotFound   = errors.New("webhook not found")
+	ErrInvalidWebhookURL = errors.New("webhook url must be an absolute http or https url")
+	ErrUnknownEventType  = errors.New("unknown event type")
+
+	// Audit errors.
+	ErrAuditChainBroken = errors.New("audit log hash chain is broken")
+
+	// Auth errors.
+	ErrUnauthorized      = errors.New("unauthorized")
+	ErrForbidden         = errors.New("forbidden")
+	ErrInvalidSignature  = errors.New("invalid request signature")
+	ErrRequestReplayed   = errors.New("request replayed")
+	ErrSignatureRequired = errors.New("request signature required")
 
 	// Requset errors.
-	ErrTimeout  = context.DeadlineExceeded
-	ErrNotFound = rmq_rpc.ErrNotFound
+	ErrTimeout     = context.DeadlineExceeded
+	ErrCallTimeout = rmq_rpc.ErrTimeout
+	ErrNotFound    = rmq_rpc.ErrNotFound
+	ErrUnavailable = rmq_rpc.ErrUnavailable
 )
+
+// RPCErrors - codes of the errors which cross the RabbitMQ RPC between the api and the worker,
+// errors.Is works for them on both sides.
+var RPCErrors = rmq_rpc.NewRegistry().
+	Register("wallet_not_found", ErrWalletNotFound).
+	Register("wrong_amount", ErrWrongAmount).
+	Register("sender_is_receiver", ErrSenderIsReceiver).
+	Register("empty_wallet", ErrEmptyWallet).
+	Register("not_wallet_owner", ErrNotWalletOwner).
+	Register("wallet_frozen", ErrWalletFrozen).
+	Register("not_enough_funds", ErrNotEnoughFunds).
+	Register("balance_too_large", ErrBalanceTooLarge).
+	Register("transaction_not_found", ErrTransactionNotFound).
+	Register("already_reversed", ErrAlreadyReversed).
+	Register("reversal_not_reversible", ErrReversalNotReversible).
+	Register("invalid_idempotency_key", ErrInvalidIdempotencyKey).
+	Register("idempotency_key_conflict", ErrIdempotencyKeyConflict).
+	Register("event_not_found", ErrEventNotFound).
+	Register("reason_required", ErrReasonRequired).
+	Register("webhook_not_found", ErrWebhookNotFound).
+	Register("invalid_webhook_url", ErrInvalidWebhookURL).
+	Register("unknown_event_type", ErrUnknownEventType).
+	Register("audit_chain_broken", ErrAuditChainBroken).
+	Register("unauthorized", ErrUnauthorized).
+	Register("forbidden", ErrForbidden).
+	Register("invalid_signature", ErrInvalidSignature).
+	Register("request_replayed", ErrRequestReplayed).
+	Register("signature_required", ErrSignatureRequired)
@@ -0,0 +1,48 @@
+package entity
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Wallet events published to other services.
+const (
+	EventWalletCreated   = "wallet.created"
+	EventFundsSent       = "funds.sent"
+	EventFundsReceived   = "funds.received"
+	EventBalanceAdjusted = "balance.adjusted"
+)
+
+// Event - message of the wallet events exchange, the type is used as the routing key.
+// Events are delivered at least once, consumers dedupe them by id.
+type Event struct {
+	ID         string          `json:"id"`
+	Type       string          `json:"type"`
+	OccurredAt time.Time       `json:"occurredAt"`
+	WalletID   string          `json:"walletId"`
+	Data       json.RawMessage `json:"data"       swaggertype:"object"`
+}
+
+type WalletCreatedEvent struct {
+	WalletID string `json:"walletId"`
+	OwnerID  string `json:"ownerId,omitempty"`
+	Balance  uint   `json:"balance"`
+}
+
+// FundsEvent - data of the funds.sent and funds.received events,
+// the balance is the balance of the event wallet after the transfer.
+type FundsEvent struct {
+	TransactionID int64  `json:"transactionId"`
+	From          string `json:"from"`
+	To            string `json:"to"`
+	Amount        uint   `json:"amount"`
+	Balance       uint   `json:"balance"`
+	ReversalOf    *int64 `json:"reversalOf,omitempty"`
+}
+
+// BalanceAdjustedEvent - data of the balance.adjusted event of the manual change by an operator,
+// the balance is the balance of the wallet after the change.
+type BalanceAdjustedEvent struct {
+	Amount  int64 `json:"amount"`
+	Balance uint  `json:"balance"`
+}
@@ -0,0 +1,51 @@
+package entity
+
+import (
+	"context"
+	"slices"
+)
+
+// Principal - authenticated caller of the api.
+type Principal struct {
+	// Subject - end user from the jwt, empty for requests made with api keys.
+	Subject string
+	KeyID   string
+	Scopes  []string
+	Roles   []string
+}
+
+type principalKey struct{}
+
+// HasScope - admin scope grants access to everything.
+func (p *Principal) HasScope(scope string) bool {
+	return slices.Contains(p.Scopes, ScopeAdmin) || slices.Contains(p.Scopes, scope)
+}
+
+// Actor - identity of the caller recorded in the audit log.
+func (p *Principal) Actor() string {
+	if p.Subject != "" {
+		return "user:" + p.Subject
+	}
+
+	return "key:" + p.KeyID
+}
+
+func ContextWithPrincipal(ctx context.Context, p *Principal) context.Context {
+	return context.WithValue(ctx, principalKey{}, p)
+}
+
+func PrincipalFromContext(ctx context.Context) (*Principal, bool) {
+	p, ok := ctx.Value(principalKey{}).(*Principal)
+
+	return p, ok
+}
+
+// SubjectFromContext - end user the request is made on behalf of.
+func SubjectFromContext(ctx context.Context) (string, bool) {
+	p, ok := PrincipalFromContext(ctx)
+	if !ok || p.Subject == "" {
+		return "", false
+	}
+
+	return p.Subject, true
+}
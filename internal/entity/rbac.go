@@ -0,0 +1,54 @@
+package entity
+
+import "slices"
+
+// Operator roles.
+const (
+	RoleSupport  = "support"
+	RoleOperator = "operator"
+	RoleAdmin    = "admin"
+)
+
+// Permissions of the admin api.
+const (
+	PermWalletSearch       = "wallet:search"
+	PermWalletAdjust       = "wallet:adjust"
+	PermWalletFreeze       = "wallet:freeze"
+	PermTransactionReverse = "transaction:reverse"
+	PermAuditRead          = "audit:read"
+)
+
+var rolePermissions = map[string][]string{
+	RoleSupport: {
+		PermWalletSearch,
+	},
+	RoleOperator: {
+		PermWalletSearch,
+		PermWalletFreeze,
+		PermWalletAdjust,
+	},
+	RoleAdmin: {
+		PermWalletSearch,
+		PermWalletFreeze,
+		PermWalletAdjust,
+		PermTransactionReverse,
+		PermAuditRead,
+	},
+}
+
+func IsRole(role string) bool {
+	_, ok := rolePermissions[role]
+
+	return ok
+}
+
+// Can - checks that any of the principal roles grants the permission.
+func (p *Principal) Can(permission string) bool {
+	for _, role := range p.Roles {
+		if slices.Contains(rolePermissions[role], permission) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -3,8 +3,11 @@ package entity
 import "time"
 
 type Transaction struct {
-	Time   time.Time `json:"time"   example:"2024-02-04T17:25:35.448Z"         description:"Дата и время перевода"  validate:"required" format:"date-time"`  //nolint:lll,tagalign // вот так то лучше
-	From   string    `json:"from"   example:"5b53700ed469fa6a09ea72bb78f36fd9" description:"ID исходящего кошелька" validate:"required" pg:"from_wallet_id"` //nolint:lll,tagalign // вот так то лучше
-	To     string    `json:"to"     example:"eb376add88bf8e70f80787266a0801d5" description:"ID входящего кошелька"  validate:"required" pg:"to_wallet_id"`   //nolint:lll,tagalign // вот так то лучше
-	Amount uint      `json:"amount" example:"30"                               description:"Сумма перевода"         validate:"required"`                     //nolint:lll,tagalign // вот так то лучше
+	ID         int64      `json:"id"                   example:"42"                               description:"ID перевода"                  validate:"required"`                                     //nolint:lll,tagalign // вот так то лучше
+	Time       time.Time  `json:"time"                 example:"2024-02-04T17:25:35.448Z"         description:"Дата и время перевода"        validate:"required" format:"date-time"`                  //nolint:lll,tagalign // вот так то лучше
+	From       string     `json:"from"                 example:"5b53700ed469fa6a09ea72bb78f36fd9" description:"ID исходящего кошелька"       validate:"required" pg:"from_wallet_id"`                 //nolint:lll,tagalign // вот так то лучше
+	To         string     `json:"to"                   example:"eb376add88bf8e70f80787266a0801d5" description:"ID входящего кошелька"        validate:"required" pg:"to_wallet_id"`                   //nolint:lll,tagalign // вот так то лучше
+	Amount     uint       `json:"amount"               example:"30"                               description:"Сумма перевода"               validate:"required"`                                     //nolint:lll,tagalign // вот так то лучше
+	ReversalOf *int64     `json:"reversalOf,omitempty" example:"41"                               description:"ID отмененного перевода"                          pg:"reversal_of"`                    //nolint:lll,tagalign // вот так то лучше
+	ReversedAt *time.Time `json:"reversedAt,omitempty" example:"2024-02-04T18:25:35.448Z"         description:"Дата и время отмены перевода"                     pg:"reversed_at" format:"date-time"` //nolint:lll,tagalign // вот так то лучше
 }
@@ -1,6 +1,13 @@
 package entity
 
+import "math"
+
+// MaxBalance - the largest balance the INTEGER column of the wallets holds.
+const MaxBalance = math.MaxInt32
+
 type Wallet struct {
-	ID      string `json:"id"       example:"5b53700ed469fa6a09ea72bb78f36fd9" description:"Уникальный ID кошелька" validate:"required"` //nolint:lll,tagalign // вот так то лучше
-	Balance uint   `json:"balance"  example:"100"                              description:"Баланс кошелька"        validate:"required"` //nolint:lll,tagalign // вот так то лучше
+	ID      string `json:"id"                example:"5b53700ed469fa6a09ea72bb78f36fd9" description:"Уникальный ID кошелька" validate:"required"` //nolint:lll,tagalign // вот так то лучше
+	Balance uint   `json:"balance"           example:"100"                              description:"Баланс кошелька"        validate:"required"` //nolint:lll,tagalign // вот так то лучше
+	OwnerID string `json:"ownerId,omitempty" example:"user-42"                          description:"ID владельца кошелька"`                      //nolint:lll,tagalign // вот так то лучше
+	Frozen  bool   `json:"frozen"            example:"false"                            description:"Кошелек заморожен"`
 }
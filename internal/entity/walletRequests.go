@@ -1,13 +1,19 @@
 package entity
 
+import "time"
+
 type CreateNewWalletWithBalanceRequest struct {
-	Balance uint `json:"balance"`
+	Balance uint   `json:"balance"`
+	OwnerID string `json:"ownerId"`
+	Caller  Caller `json:"caller"`
 }
 
 type SendFundsRequest struct {
-	From   string `json:"from"`
-	To     string `json:"to"`
-	Amount uint   `json:"amount"`
+	From           string `json:"from"`
+	To             string `json:"to"`
+	Amount         uint   `json:"amount"`
+	IdempotencyKey string `json:"idempotencyKey,omitempty"`
+	Caller         Caller `json:"caller"`
 }
 
 type GetWalletHistoryByIDRequest struct {
@@ -17,3 +23,43 @@ type GetWalletHistoryByIDRequest struct {
 type GetWalletByIDRequest struct {
 	WalletID string `json:"walletId"`
 }
+
+type ListWalletEventsRequest struct {
+	WalletID string `json:"walletId"`
+	After    string `json:"after"`
+	Limit    uint   `json:"limit"`
+}
+
+type GetAPIKeyByIDRequest struct {
+	ID string `json:"id"`
+}
+
+type RegisterNonceRequest struct {
+	KeyID     string    `json:"keyId"`
+	Nonce     string    `json:"nonce"`
+	ExpiresAt time.Time `json:"expiresAt"`
+}
+
+type RegisterNonceResponse struct {
+	Registered bool `json:"registered"`
+}
+
+type CreateWebhookRequest struct {
+	Webhook Webhook `json:"webhook"`
+}
+
+type ListWebhooksRequest struct {
+	CreatedBy string `json:"createdBy"`
+}
+
+type WebhookByIDRequest struct {
+	ID        string `json:"id"`
+	CreatedBy string `json:"createdBy"`
+}
+
+type ListWebhookDeliveriesRequest struct {
+	WebhookID string `json:"webhookId"`
+	CreatedBy string `json:"createdBy"`
+	Limit     uint   `json:"limit"`
+	Offset    uint   `json:"offset"`
+}
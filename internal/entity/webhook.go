@@ -0,0 +1,73 @@
+package entity
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"slices"
+	"strconv"
+	"time"
+)
+
+// Statuses of the webhook deliveries.
+const (
+	DeliveryPending   = "pending"
+	DeliveryDelivered = "delivered"
+	// DeliveryDead - delivery failed all attempts and won't be retried.
+	DeliveryDead = "dead"
+	// DeliveryCanceled - webhook was deleted before the delivery.
+	DeliveryCanceled = "canceled"
+)
+
+// Webhook - subscription of a partner to the wallet events.
+// Subscriptions of end users receive events of their own wallets only.
+type Webhook struct {
+	ID string `json:"id"`
+	// URL - receiver of the POST requests.
+	URL string `json:"url"`
+	// Secret - key of the payload signature, returned only when the webhook is created.
+	Secret string `json:"secret,omitempty"`
+	// Events - types of the events, all events if empty.
+	Events    []string  `json:"events"`
+	OwnerID   string    `json:"ownerId,omitempty"`
+	CreatedBy string    `json:"createdBy"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+type WebhookDelivery struct {
+	ID             int64      `json:"id"`
+	WebhookID      string     `json:"webhookId"`
+	EventID        string     `json:"eventId"`
+	EventType      string     `json:"eventType"`
+	Status         string     `json:"status"`
+	Attempts       int        `json:"attempts"`
+	ResponseStatus int        `json:"responseStatus,omitempty"`
+	LastError      string     `json:"lastError,omitempty"`
+	NextAttemptAt  *time.Time `json:"nextAttemptAt,omitempty"`
+	CreatedAt      time.Time  `json:"createdAt"`
+	DeliveredAt    *time.Time `json:"deliveredAt,omitempty"`
+}
+
+// WebhookDispatch - delivery claimed by the dispatcher with everything needed to send it.
+type WebhookDispatch struct {
+	Delivery WebhookDelivery
+	URL      string
+	Secret   string
+	Event    Event
+}
+
+// IsEventType - checks that the wallet events have the type.
+func IsEventType(eventType string) bool {
+	return slices.Contains([]string{EventWalletCreated, EventFundsSent, EventFundsReceived, EventBalanceAdjusted}, eventType)
+}
+
+// SignWebhook - hex HMAC-SHA256 keyed with the webhook secret over "<unix timestamp>.<body>".
+// Receivers compute the same signature to check the payload and reject old timestamps.
+func SignWebhook(secret string, timestamp time.Time, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp.Unix(), 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+
+	return hex.EncodeToString(mac.Sum(nil))
+}
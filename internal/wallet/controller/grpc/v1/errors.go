@@ -0,0 +1,60 @@
+package v1
+
+import (
+	"WalletRieltaTestTask/internal/entity"
+	"WalletRieltaTestTask/pkg/logger"
+	"context"
+	"errors"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"log/slog"
+)
+
+type statusCode struct {
+	err  error
+	code codes.Code
+}
+
+// Status codes of domain errors, the message of the status is the message of the error.
+var statusCodes = []statusCode{
+	{entity.ErrWrongAmount, codes.InvalidArgument},
+	{entity.ErrEmptyWallet, codes.InvalidArgument},
+	{entity.ErrSenderIsReceiver, codes.InvalidArgument},
+	{entity.ErrNotWalletOwner, codes.PermissionDenied},
+	{entity.ErrWalletFrozen, codes.FailedPrecondition},
+	{entity.ErrNotEnoughFunds, codes.FailedPrecondition},
+	{entity.ErrInvalidIdempotencyKey, codes.InvalidArgument},
+	{entity.ErrIdempotencyKeyConflict, codes.AlreadyExists},
+	{entity.ErrWalletNotFound, codes.NotFound},
+	{entity.ErrNotFound, codes.NotFound},
+	{entity.ErrTimeout, codes.DeadlineExceeded},
+	{entity.ErrCallTimeout, codes.DeadlineExceeded},
+	{entity.ErrUnavailable, codes.Unavailable},
+	{context.Canceled, codes.Canceled},
+	{entity.ErrUnauthorized, codes.Unauthenticated},
+	{entity.ErrSignatureRequired, codes.Unauthenticated},
+	{entity.ErrInvalidSignature, codes.Unauthenticated},
+	{entity.ErrRequestReplayed, codes.Unauthenticated},
+	{entity.ErrForbidden, codes.PermissionDenied},
+	{errRateLimited, codes.ResourceExhausted},
+}
+
+// Converts the error into the status, unknown errors are logged and hidden from the client.
+func (i *interceptors) status(ctx context.Context, method string, err error) error {
+	if _, ok := status.FromError(err); ok {
+		return err
+	}
+
+	for _, s := range statusCodes {
+		if errors.Is(err, s.err) {
+			return status.Error(s.code, s.err.Error())
+		}
+	}
+
+	i.l.Error("grpc - v1 - "+method,
+		logger.Err(err),
+		slog.String("requestId", entity.CallerFromContext(ctx).RequestID),
+	)
+
+	return status.Error(codes.Internal, "internal server error")
+}
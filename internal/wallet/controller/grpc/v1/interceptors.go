@@ -0,0 +1,153 @@
+package v1
+
+import (
+	"WalletRieltaTestTask/internal/entity"
+	"WalletRieltaTestTask/internal/wallet/usecase"
+	"context"
+	"github.com/google/uuid"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"log/slog"
+	"net"
+	"strings"
+)
+
+const (
+	mdAuthorization  = "authorization"
+	mdAPIKey         = "x-api-key"
+	mdRequestID      = "x-request-id"
+	mdIdempotencyKey = "idempotency-key"
+
+	bearerPrefix = "Bearer "
+)
+
+type interceptors struct {
+	a      usecase.Auth
+	l      *slog.Logger
+	limits RateLimits
+}
+
+func (i *interceptors) unary(
+	ctx context.Context,
+	req interface{},
+	info *grpc.UnaryServerInfo,
+	handler grpc.UnaryHandler,
+) (interface{}, error) {
+	ctx, err := i.admit(ctx, info.FullMethod)
+	if err != nil {
+		return nil, i.status(ctx, info.FullMethod, err)
+	}
+
+	resp, err := handler(ctx, req)
+	if err != nil {
+		return nil, i.status(ctx, info.FullMethod, err)
+	}
+
+	return resp, nil
+}
+
+func (i *interceptors) stream(
+	srv interface{},
+	ss grpc.ServerStream,
+	info *grpc.StreamServerInfo,
+	handler grpc.StreamHandler,
+) error {
+	ctx, err := i.admit(ss.Context(), info.FullMethod)
+	if err != nil {
+		return i.status(ctx, info.FullMethod, err)
+	}
+
+	err = handler(srv, &serverStream{ServerStream: ss, ctx: ctx})
+	if err != nil {
+		return i.status(ctx, info.FullMethod, err)
+	}
+
+	return nil
+}
+
+// Puts the request info into the context and limits the calls the same way as the http api:
+// by the client ip before authentication and by the client after it.
+func (i *interceptors) admit(ctx context.Context, method string) (context.Context, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+
+	requestID := first(md, mdRequestID)
+	if requestID == "" {
+		requestID = uuid.New().String()
+	}
+
+	_ = grpc.SetHeader(ctx, metadata.Pairs(mdRequestID, requestID))
+
+	ctx = entity.ContextWithRequestInfo(ctx, requestID, peerIP(ctx))
+
+	if err := i.ipRateLimit(ctx); err != nil {
+		return ctx, err
+	}
+
+	ctx, err := i.authorize(ctx, md, method)
+	if err != nil {
+		return ctx, err
+	}
+
+	return ctx, i.rateLimit(ctx, method)
+}
+
+// Puts the authenticated caller into the context and checks that the caller has the scope of the method.
+func (i *interceptors) authorize(ctx context.Context, md metadata.MD, method string) (context.Context, error) {
+	var (
+		principal *entity.Principal
+		err       error
+	)
+
+	// Signed requests are not supported, keys which require signatures are rejected
+	if authorization := first(md, mdAuthorization); strings.HasPrefix(authorization, bearerPrefix) {
+		principal, err = i.a.AuthenticateToken(ctx, strings.TrimPrefix(authorization, bearerPrefix))
+	} else {
+		principal, err = i.a.AuthenticateAPIKey(ctx, first(md, mdAPIKey))
+	}
+
+	if err != nil {
+		return ctx, err //nolint:wrapcheck // converted to the status by the caller
+	}
+
+	ctx = entity.ContextWithPrincipal(ctx, principal)
+
+	scope, ok := methodScopes[method]
+	if !ok || !principal.HasScope(scope) {
+		return ctx, entity.ErrForbidden
+	}
+
+	return ctx, nil
+}
+
+// serverStream - stream with the context of the authorized call.
+type serverStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *serverStream) Context() context.Context {
+	return s.ctx
+}
+
+func first(md metadata.MD, key string) string {
+	if values := md.Get(key); len(values) > 0 {
+		return values[0]
+	}
+
+	return ""
+}
+
+func peerIP(ctx context.Context) string {
+	p, ok := peer.FromContext(ctx)
+	if !ok {
+		return ""
+	}
+
+	host, _, err := net.SplitHostPort(p.Addr.String())
+	if err != nil {
+		return p.Addr.String()
+	}
+
+	return host
+}
@@ -0,0 +1,76 @@
+package v1
+
+import (
+	"WalletRieltaTestTask/internal/entity"
+	walletv1 "WalletRieltaTestTask/pkg/api/wallet/v1"
+	"WalletRieltaTestTask/pkg/ratelimit"
+	"context"
+	"errors"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"math"
+	"strconv"
+)
+
+const mdRetryAfter = "retry-after"
+
+var errRateLimited = errors.New("rate limit exceeded")
+
+// RateLimits - limiters shared with the http api, so a client has one limit over both apis.
+// Methods take tokens of the http routes of the same operations, keys of the buckets are the same as in the http api.
+type RateLimits struct {
+	Default *ratelimit.Limiter
+	Routes  map[string]*ratelimit.Limiter
+	IP      *ratelimit.Limiter
+}
+
+// Http routes of the methods.
+var methodRoutes = map[string]string{
+	walletv1.WalletService_CreateWallet_FullMethodName:     "POST /api/v1/wallet",
+	walletv1.WalletService_SendFunds_FullMethodName:        "POST /api/v1/wallet/:walletId/send",
+	walletv1.WalletService_GetWalletHistory_FullMethodName: "GET /api/v1/wallet/:walletId/history",
+	walletv1.WalletService_GetWallet_FullMethodName:        "GET /api/v1/wallet/:walletId",
+}
+
+// Limits calls of every client ip before authentication.
+func (i *interceptors) ipRateLimit(ctx context.Context) error {
+	if i.limits.IP == nil {
+		return nil
+	}
+
+	return limit(ctx, i.limits.IP, "ip:"+peerIP(ctx))
+}
+
+// Limits calls of every authenticated client separately. The client is the api key or the token subject.
+func (i *interceptors) rateLimit(ctx context.Context, method string) error {
+	route := methodRoutes[method]
+
+	limiter, ok := i.limits.Routes[route]
+	if !ok {
+		limiter, route = i.limits.Default, ""
+	}
+
+	if limiter == nil {
+		return nil
+	}
+
+	var actor string
+	if principal, ok := entity.PrincipalFromContext(ctx); ok {
+		actor = principal.Actor()
+	}
+
+	return limit(ctx, limiter, route+"|"+actor)
+}
+
+// Takes a token of the key, the time until the next allowed call is sent in the retry-after header.
+func limit(ctx context.Context, limiter *ratelimit.Limiter, key string) error {
+	result := limiter.Allow(key)
+	if result.Allowed {
+		return nil
+	}
+
+	retryAfter := int(math.Ceil(result.RetryAfter.Seconds()))
+	_ = grpc.SetHeader(ctx, metadata.Pairs(mdRetryAfter, strconv.Itoa(retryAfter)))
+
+	return errRateLimited
+}
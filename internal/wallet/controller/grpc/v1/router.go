@@ -0,0 +1,32 @@
+package v1
+
+import (
+	"WalletRieltaTestTask/internal/entity"
+	"WalletRieltaTestTask/internal/wallet/usecase"
+	walletv1 "WalletRieltaTestTask/pkg/api/wallet/v1"
+	"google.golang.org/grpc"
+	"log/slog"
+)
+
+// Scopes required by the methods, methods without a scope are rejected.
+var methodScopes = map[string]string{
+	walletv1.WalletService_CreateWallet_FullMethodName:     entity.ScopeTransfer,
+	walletv1.WalletService_SendFunds_FullMethodName:        entity.ScopeTransfer,
+	walletv1.WalletService_GetWalletHistory_FullMethodName: entity.ScopeRead,
+	walletv1.WalletService_GetWallet_FullMethodName:        entity.ScopeRead,
+}
+
+// NewRouter - grpc server with the wallet service, calls are authenticated and rate limited
+// the same way as the http api.
+func NewRouter(l *slog.Logger, w usecase.Wallet, a usecase.Auth, limits RateLimits) *grpc.Server {
+	i := &interceptors{a: a, l: l, limits: limits}
+
+	server := grpc.NewServer(
+		grpc.ChainUnaryInterceptor(i.unary),
+		grpc.ChainStreamInterceptor(i.stream),
+	)
+
+	walletv1.RegisterWalletServiceServer(server, &walletServer{w: w})
+
+	return server
+}
@@ -0,0 +1,278 @@
+package v1
+
+import (
+	"WalletRieltaTestTask/internal/entity"
+	walletUC "WalletRieltaTestTask/internal/wallet/usecase"
+	walletv1 "WalletRieltaTestTask/pkg/api/wallet/v1"
+	"WalletRieltaTestTask/pkg/rabbitmq/rmq_rpc"
+	"WalletRieltaTestTask/pkg/ratelimit"
+	"context"
+	"errors"
+	"fmt"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"io"
+	"log/slog"
+	"net"
+	"testing"
+	"time"
+)
+
+// Api keys of the service and of a client which can only read, bearer tokens are the subjects of the users.
+type auth struct{}
+
+func (auth) AuthenticateAPIKey(_ context.Context, rawKey string) (*entity.Principal, error) {
+	switch rawKey {
+	case "service.secret":
+		return &entity.Principal{KeyID: "service", Scopes: []string{entity.ScopeRead, entity.ScopeTransfer}}, nil
+	case "reader.secret":
+		return &entity.Principal{KeyID: "reader", Scopes: []string{entity.ScopeRead}}, nil
+	}
+
+	return nil, entity.ErrUnauthorized
+}
+
+func (auth) AuthenticateSignedRequest(context.Context, *entity.SignedRequest) (*entity.Principal, error) {
+	return nil, entity.ErrUnauthorized
+}
+
+func (auth) AuthenticateToken(_ context.Context, token string) (*entity.Principal, error) {
+	return &entity.Principal{Subject: token, Scopes: []string{entity.ScopeRead, entity.ScopeTransfer}}, nil
+}
+
+// Wallets of alice and bob, transfers fail with sendErr.
+type walletGateway struct {
+	sendErr error
+}
+
+func (walletGateway) CreateNewWalletWithBalance(
+	_ context.Context,
+	balance uint,
+	ownerID string,
+	_ entity.Caller,
+) (*entity.Wallet, error) {
+	return &entity.Wallet{ID: "new", Balance: balance, OwnerID: ownerID}, nil
+}
+
+func (gw walletGateway) SendFunds(context.Context, entity.SendFundsRequest) error {
+	return gw.sendErr
+}
+
+func (walletGateway) GetWalletHistoryByID(_ context.Context, walletID string) ([]entity.Transaction, error) {
+	return []entity.Transaction{{ID: 1, From: walletID, To: "bob-wallet", Amount: 10}}, nil
+}
+
+func (walletGateway) GetWalletByID(_ context.Context, walletID string) (*entity.Wallet, error) {
+	switch walletID {
+	case "alice-wallet":
+		return &entity.Wallet{ID: walletID, Balance: 100, OwnerID: "alice"}, nil
+	case "bob-wallet":
+		return &entity.Wallet{ID: walletID, Balance: 100, OwnerID: "bob"}, nil
+	}
+
+	return nil, entity.ErrWalletNotFound
+}
+
+func (walletGateway) ListWalletEvents(context.Context, string, string, uint) ([]entity.Event, error) {
+	return nil, nil
+}
+
+func newTestClient(t *testing.T, gw walletGateway, limits RateLimits) walletv1.WalletServiceClient {
+	t.Helper()
+
+	listener := bufconn.Listen(1 << 20)
+
+	server := NewRouter(slog.New(slog.NewTextHandler(io.Discard, nil)), walletUC.NewWallet(gw), auth{}, limits)
+	go func() { _ = server.Serve(listener) }()
+
+	conn, err := grpc.NewClient("passthrough:///bufconn",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return listener.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		t.Fatalf("grpc.NewClient: %v", err)
+	}
+
+	t.Cleanup(func() {
+		_ = conn.Close()
+		server.Stop()
+	})
+
+	return walletv1.NewWalletServiceClient(conn)
+}
+
+func withKey(key string) context.Context {
+	return metadata.AppendToOutgoingContext(context.Background(), mdAPIKey, key)
+}
+
+func withToken(subject string) context.Context {
+	return metadata.AppendToOutgoingContext(context.Background(), mdAuthorization, bearerPrefix+subject)
+}
+
+// History is a stream, the stream interceptor checks it the same way.
+func history(ctx context.Context, c walletv1.WalletServiceClient, walletID string) error {
+	stream, err := c.GetWalletHistory(ctx, &walletv1.GetWalletHistoryRequest{WalletId: walletID})
+	if err != nil {
+		return err //nolint:wrapcheck // the status is checked by the test
+	}
+
+	for {
+		if _, err = stream.Recv(); err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+
+			return err //nolint:wrapcheck // the status is checked by the test
+		}
+	}
+}
+
+func TestAuthorization(t *testing.T) {
+	c := newTestClient(t, walletGateway{}, RateLimits{})
+
+	send := &walletv1.SendFundsRequest{From: "alice-wallet", To: "bob-wallet", Amount: 10}
+
+	tests := []struct {
+		name string
+		call func() error
+		code codes.Code
+	}{
+		{"no key", func() error {
+			_, err := c.GetWallet(context.Background(), &walletv1.GetWalletRequest{WalletId: "alice-wallet"})
+			return err
+		}, codes.Unauthenticated},
+		{"wrong key", func() error {
+			_, err := c.GetWallet(withKey("service.wrong"), &walletv1.GetWalletRequest{WalletId: "alice-wallet"})
+			return err
+		}, codes.Unauthenticated},
+		{"stream without key", func() error {
+			return history(context.Background(), c, "alice-wallet")
+		}, codes.Unauthenticated},
+		{"read scope reads", func() error {
+			_, err := c.GetWallet(withKey("reader.secret"), &walletv1.GetWalletRequest{WalletId: "alice-wallet"})
+			return err
+		}, codes.OK},
+		{"read scope can't send", func() error {
+			_, err := c.SendFunds(withKey("reader.secret"), send)
+			return err
+		}, codes.PermissionDenied},
+		{"service sends from any wallet", func() error {
+			_, err := c.SendFunds(withKey("service.secret"), send)
+			return err
+		}, codes.OK},
+		{"user sends from own wallet", func() error {
+			_, err := c.SendFunds(withToken("alice"), send)
+			return err
+		}, codes.OK},
+		{"user can't send from another wallet", func() error {
+			_, err := c.SendFunds(withToken("bob"), send)
+			return err
+		}, codes.PermissionDenied},
+		{"user reads own history", func() error {
+			return history(withToken("alice"), c, "alice-wallet")
+		}, codes.OK},
+		{"user can't read another history", func() error {
+			return history(withToken("alice"), c, "bob-wallet")
+		}, codes.PermissionDenied},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if code := status.Code(tt.call()); code != tt.code {
+				t.Fatalf("code = %s, want %s", code, tt.code)
+			}
+		})
+	}
+}
+
+func TestStatusCodes(t *testing.T) {
+	tests := []struct {
+		err  error
+		code codes.Code
+	}{
+		{entity.ErrNotEnoughFunds, codes.FailedPrecondition},
+		{entity.ErrIdempotencyKeyConflict, codes.AlreadyExists},
+		{fmt.Errorf("RemoteCall: %w", rmq_rpc.ErrTimeout), codes.DeadlineExceeded},
+		{rmq_rpc.ErrConnectionLost, codes.Unavailable},
+		{rmq_rpc.ErrNoConsumers, codes.Unavailable},
+		{errors.New("connection refused"), codes.Internal},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.err.Error(), func(t *testing.T) {
+			c := newTestClient(t, walletGateway{sendErr: tt.err}, RateLimits{})
+
+			_, err := c.SendFunds(withKey("service.secret"),
+				&walletv1.SendFundsRequest{From: "alice-wallet", To: "bob-wallet", Amount: 10})
+
+			if code := status.Code(err); code != tt.code {
+				t.Fatalf("code = %s, want %s", code, tt.code)
+			}
+
+			// Unknown errors are hidden from the client
+			if tt.code == codes.Internal && status.Convert(err).Message() != "internal server error" {
+				t.Fatalf("message = %q, want it hidden", status.Convert(err).Message())
+			}
+		})
+	}
+}
+
+func TestRateLimit(t *testing.T) {
+	getWallet := &walletv1.GetWalletRequest{WalletId: "alice-wallet"}
+
+	t.Run("client", func(t *testing.T) {
+		limits := RateLimits{
+			Default: ratelimit.New(1, time.Minute),
+			Routes:  map[string]*ratelimit.Limiter{"GET /api/v1/wallet/:walletId": ratelimit.New(1, time.Minute)},
+		}
+
+		c := newTestClient(t, walletGateway{}, limits)
+
+		// The http request of the client took the token of the route
+		limits.Routes["GET /api/v1/wallet/:walletId"].Allow("GET /api/v1/wallet/:walletId|key:service")
+
+		var header metadata.MD
+
+		_, err := c.GetWallet(withKey("service.secret"), getWallet, grpc.Header(&header))
+		if status.Code(err) != codes.ResourceExhausted {
+			t.Fatalf("err = %v, want %s", err, codes.ResourceExhausted)
+		}
+
+		if retryAfter := header.Get(mdRetryAfter); len(retryAfter) != 1 || retryAfter[0] != "60" {
+			t.Fatalf("retry-after = %v, want 60", retryAfter)
+		}
+
+		// Other clients and methods without own limits have their buckets
+		if _, err = c.GetWallet(withKey("reader.secret"), getWallet); err != nil {
+			t.Fatalf("GetWallet of another client: %v", err)
+		}
+
+		if err = history(withKey("service.secret"), c, "alice-wallet"); err != nil {
+			t.Fatalf("GetWalletHistory: %v", err)
+		}
+
+		if err = history(withKey("service.secret"), c, "alice-wallet"); status.Code(err) != codes.ResourceExhausted {
+			t.Fatalf("err = %v, want %s", err, codes.ResourceExhausted)
+		}
+	})
+
+	t.Run("ip", func(t *testing.T) {
+		c := newTestClient(t, walletGateway{}, RateLimits{IP: ratelimit.New(1, time.Minute)})
+
+		if _, err := c.GetWallet(withKey("service.secret"), getWallet); err != nil {
+			t.Fatalf("GetWallet: %v", err)
+		}
+
+		// Calls with invalid credentials are limited too
+		_, err := c.GetWallet(withKey("service.wrong"), getWallet)
+		if status.Code(err) != codes.ResourceExhausted {
+			t.Fatalf("err = %v, want %s", err, codes.ResourceExhausted)
+		}
+	})
+}
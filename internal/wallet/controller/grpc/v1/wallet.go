@@ -0,0 +1,95 @@
+package v1
+
+import (
+	"WalletRieltaTestTask/internal/entity"
+	"WalletRieltaTestTask/internal/wallet/usecase"
+	walletv1 "WalletRieltaTestTask/pkg/api/wallet/v1"
+	"context"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+type walletServer struct {
+	walletv1.UnimplementedWalletServiceServer
+
+	w usecase.Wallet
+}
+
+func (s *walletServer) CreateWallet(ctx context.Context, _ *walletv1.CreateWalletRequest) (*walletv1.Wallet, error) {
+	wallet, err := s.w.CreateNewWalletWithDefaultBalance(ctx)
+	if err != nil {
+		return nil, err //nolint:wrapcheck // converted to the status by the interceptor
+	}
+
+	return toWallet(wallet), nil
+}
+
+func (s *walletServer) SendFunds(ctx context.Context, request *walletv1.SendFundsRequest) (*walletv1.SendFundsResponse, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+
+	err := s.w.SendFunds(
+		ctx,
+		request.GetFrom(),
+		request.GetTo(),
+		uint(request.GetAmount()),
+		first(md, mdIdempotencyKey),
+	)
+	if err != nil {
+		return nil, err //nolint:wrapcheck // converted to the status by the interceptor
+	}
+
+	return &walletv1.SendFundsResponse{}, nil
+}
+
+func (s *walletServer) GetWalletHistory(
+	request *walletv1.GetWalletHistoryRequest,
+	stream walletv1.WalletService_GetWalletHistoryServer,
+) error {
+	transactions, err := s.w.GetWalletHistoryByID(stream.Context(), request.GetWalletId())
+	if err != nil {
+		return err //nolint:wrapcheck // converted to the status by the interceptor
+	}
+
+	for i := range transactions {
+		if err = stream.Send(toTransaction(&transactions[i])); err != nil {
+			return err //nolint:wrapcheck // stream is broken, the status is already set
+		}
+	}
+
+	return nil
+}
+
+func (s *walletServer) GetWallet(ctx context.Context, request *walletv1.GetWalletRequest) (*walletv1.Wallet, error) {
+	wallet, err := s.w.GetWalletByID(ctx, request.GetWalletId())
+	if err != nil {
+		return nil, err //nolint:wrapcheck // converted to the status by the interceptor
+	}
+
+	return toWallet(wallet), nil
+}
+
+func toWallet(wallet *entity.Wallet) *walletv1.Wallet {
+	return &walletv1.Wallet{
+		Id:      wallet.ID,
+		Balance: uint64(wallet.Balance),
+		OwnerId: wallet.OwnerID,
+		Frozen:  wallet.Frozen,
+	}
+}
+
+func toTransaction(transaction *entity.Transaction) *walletv1.Transaction {
+	t := &walletv1.Transaction{
+		Id:         transaction.ID,
+		Time:       timestamppb.New(transaction.Time),
+		From:       transaction.From,
+		To:         transaction.To,
+		Amount:     uint64(transaction.Amount),
+		ReversalOf: transaction.ReversalOf,
+	}
+
+	if transaction.ReversedAt != nil {
+		t.ReversedAt = timestamppb.New(*transaction.ReversedAt)
+	}
+
+	return t
+}
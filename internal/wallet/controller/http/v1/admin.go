@@ -0,0 +1,297 @@
+package v1
+
+import (
+	"WalletRieltaTestTask/internal/entity"
+	"WalletRieltaTestTask/internal/wallet/usecase"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"time"
+)
+
+type adminRoutes struct {
+	a usecase.Admin
+}
+
+func newAdminRoutes(handler *gin.RouterGroup, a usecase.Admin) {
+	r := &adminRoutes{a}
+
+	h := handler.Group("")
+	{
+		h.GET("/wallets", requirePermission(entity.PermWalletSearch), r.searchWallets)
+		h.POST("/wallets/:walletId/adjustments", requirePermission(entity.PermWalletAdjust), r.adjustBalance)
+		h.POST("/wallets/:walletId/freeze", requirePermission(entity.PermWalletFreeze), r.freezeWallet)
+		h.POST("/wallets/:walletId/unfreeze", requirePermission(entity.PermWalletFreeze), r.unfreezeWallet)
+		h.POST("/transactions/:transactionId/reverse", requirePermission(entity.PermTransactionReverse), r.reverseTransaction)
+		h.GET("/audit", requirePermission(entity.PermAuditRead), r.searchAudit)
+	}
+}
+
+// @Description Параметры поиска кошельков.
+type walletSearchQuery struct {
+	OwnerID    string `form:"ownerId"    json:"ownerId"`
+	Frozen     *bool  `form:"frozen"     json:"frozen"`
+	MinBalance *uint  `form:"minBalance" json:"minBalance"`
+	MaxBalance *uint  `form:"maxBalance" json:"maxBalance"`
+	Limit      uint   `form:"limit"      json:"limit"      validate:"max=500"`
+	Offset     uint   `form:"offset"     json:"offset"`
+}
+
+// @Summary     Поиск кошельков
+// @Tags  	    Admin
+// @Security    ApiKeyAuth
+// @Security    BearerAuth
+// @Param ownerId    query string false "ID владельца"
+// @Param frozen     query bool   false "Заморожен"
+// @Param minBalance query int    false "Минимальный баланс"
+// @Param maxBalance query int    false "Максимальный баланс"
+// @Param limit      query int    false "Количество кошельков, до 500" default(50)
+// @Param offset     query int    false "Смещение"
+// @Success     200 {object} []entity.Wallet "Найденные кошельки"
+// @Failure     400 {object} problemDetails "Ошибка в пользовательском запросе (validation-failed)"
+// @Failure     401 {object} problemDetails "Запрос не аутентифицирован (unauthorized)"
+// @Failure     403 {object} problemDetails "Нет доступа к операции (forbidden)"
+// @Failure     429 {object} problemDetails "Превышен лимит запросов (rate-limited)"
+// @Failure     500 {object} problemDetails "Не удалось выполнить запрос (internal)"
+// @Failure     504 {object} problemDetails "Время ожидания вышло (timeout)"
+// @Router      /admin/wallets [get].
+func (r *adminRoutes) searchWallets(c *gin.Context) {
+	var query walletSearchQuery
+
+	if err := c.ShouldBindQuery(&query); err != nil {
+		_ = c.Error(err).SetType(gin.ErrorTypeBind)
+		return
+	}
+
+	wallets, err := r.a.SearchWallets(c.Request.Context(), entity.WalletFilter{
+		OwnerID:    query.OwnerID,
+		Frozen:     query.Frozen,
+		MinBalance: query.MinBalance,
+		MaxBalance: query.MaxBalance,
+		Limit:      query.Limit,
+		Offset:     query.Offset,
+	})
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, wallets)
+}
+
+// @Description Запрос ручного изменения баланса.
+type adjustmentRequest struct {
+	Amount int64  `json:"amount" example:"-30"                    description:"Изменение баланса, отрицательное для списания" validate:"required"` //nolint:lll,tagalign // вот так то лучше
+	Reason string `json:"reason" example:"Компенсация по тикету 42" description:"Причина изменения"                        validate:"required"`    //nolint:lll,tagalign // вот так то лучше
+}
+
+// @Summary     Ручное изменение баланса кошелька
+// @Tags  	    Admin
+// @Security    ApiKeyAuth
+// @Security    BearerAuth
+// @Param walletId path string true "ID кошелька"
+// @Param input body adjustmentRequest true "Запрос изменения баланса"
+// @Success     200 {object} entity.Wallet "Баланс изменен"
+// @Failure     400 {object} problemDetails "Ошибка в пользовательском запросе (validation-failed, malformed-request, reason-required)"
+// @Failure     401 {object} problemDetails "Запрос не аутентифицирован (unauthorized)"
+// @Failure     403 {object} problemDetails "Нет доступа к операции (forbidden)"
+// @Failure     404 {object} problemDetails "Кошелек не найден (wallet-not-found)"
+// @Failure     409 {object} problemDetails "Недостаточно средств (not-enough-funds)"
+// @Failure     422 {object} problemDetails "Баланс превысит максимум (balance-too-large)"
+// @Failure     429 {object} problemDetails "Превышен лимит запросов (rate-limited)"
+// @Failure     500 {object} problemDetails "Не удалось выполнить запрос (internal)"
+// @Failure     504 {object} problemDetails "Время ожидания вышло (timeout)"
+// @Router      /admin/wallets/{walletId}/adjustments [post].
+func (r *adminRoutes) adjustBalance(c *gin.Context) {
+	var request adjustmentRequest
+
+	if err := c.ShouldBindJSON(&request); err != nil {
+		_ = c.Error(err).SetType(gin.ErrorTypeBind)
+		return
+	}
+
+	wallet, err := r.a.AdjustBalance(c.Request.Context(), c.Param("walletId"), request.Amount, request.Reason)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, wallet)
+}
+
+// @Description Причина операции.
+type reasonRequest struct {
+	Reason string `json:"reason" example:"Подозрительная активность" description:"Причина операции" validate:"required"`
+}
+
+// @Summary     Заморозка кошелька
+// @Description Замороженный кошелек не может отправлять и получать переводы.
+// @Tags  	    Admin
+// @Security    ApiKeyAuth
+// @Security    BearerAuth
+// @Param walletId path string true "ID кошелька"
+// @Param input body reasonRequest true "Причина заморозки"
+// @Success     200 {object} entity.Wallet "Кошелек заморожен"
+// @Failure     400 {object} problemDetails "Ошибка в пользовательском запросе (validation-failed, malformed-request, reason-required)"
+// @Failure     401 {object} problemDetails "Запрос не аутентифицирован (unauthorized)"
+// @Failure     403 {object} problemDetails "Нет доступа к операции (forbidden)"
+// @Failure     404 {object} problemDetails "Кошелек не найден (wallet-not-found)"
+// @Failure     429 {object} problemDetails "Превышен лимит запросов (rate-limited)"
+// @Failure     500 {object} problemDetails "Не удалось выполнить запрос (internal)"
+// @Failure     504 {object} problemDetails "Время ожидания вышло (timeout)"
+// @Router      /admin/wallets/{walletId}/freeze [post].
+func (r *adminRoutes) freezeWallet(c *gin.Context) {
+	var request reasonRequest
+
+	if err := c.ShouldBindJSON(&request); err != nil {
+		_ = c.Error(err).SetType(gin.ErrorTypeBind)
+		return
+	}
+
+	wallet, err := r.a.FreezeWallet(c.Request.Context(), c.Param("walletId"), request.Reason)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, wallet)
+}
+
+// @Summary     Разморозка кошелька
+// @Tags  	    Admin
+// @Security    ApiKeyAuth
+// @Security    BearerAuth
+// @Param walletId path string true "ID кошелька"
+// @Param input body reasonRequest true "Причина разморозки"
+// @Success     200 {object} entity.Wallet "Кошелек разморожен"
+// @Failure     400 {object} problemDetails "Ошибка в пользовательском запросе (validation-failed, malformed-request, reason-required)"
+// @Failure     401 {object} problemDetails "Запрос не аутентифицирован (unauthorized)"
+// @Failure     403 {object} problemDetails "Нет доступа к операции (forbidden)"
+// @Failure     404 {object} problemDetails "Кошелек не найден (wallet-not-found)"
+// @Failure     429 {object} problemDetails "Превышен лимит запросов (rate-limited)"
+// @Failure     500 {object} problemDetails "Не удалось выполнить запрос (internal)"
+// @Failure     504 {object} problemDetails "Время ожидания вышло (timeout)"
+// @Router      /admin/wallets/{walletId}/unfreeze [post].
+func (r *adminRoutes) unfreezeWallet(c *gin.Context) {
+	var request reasonRequest
+
+	if err := c.ShouldBindJSON(&request); err != nil {
+		_ = c.Error(err).SetType(gin.ErrorTypeBind)
+		return
+	}
+
+	wallet, err := r.a.UnfreezeWallet(c.Request.Context(), c.Param("walletId"), request.Reason)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, wallet)
+}
+
+type transactionURI struct {
+	TransactionID int64 `uri:"transactionId" json:"transactionId" validate:"required,min=1"`
+}
+
+// @Summary     Отмена перевода
+// @Description Возвращает средства отправителю отдельным переводом.
+// @Tags  	    Admin
+// @Security    ApiKeyAuth
+// @Security    BearerAuth
+// @Param transactionId path int true "ID перевода"
+// @Param input body reasonRequest true "Причина отмены"
+// @Success     200 {object} entity.Transaction "Перевод отменен, возвращается обратный перевод"
+// @Failure     400 {object} problemDetails "Ошибка в пользовательском запросе (validation-failed, malformed-request, reason-required)"
+// @Failure     401 {object} problemDetails "Запрос не аутентифицирован (unauthorized)"
+// @Failure     403 {object} problemDetails "Нет доступа к операции (forbidden)"
+// @Failure     404 {object} problemDetails "Перевод не найден (transaction-not-found)"
+// @Failure     409 {object} problemDetails "Перевод уже отменен, сам является отменой или у получателя недостаточно средств (already-reversed, reversal-not-reversible, not-enough-funds)"
+// @Failure     429 {object} problemDetails "Превышен лимит запросов (rate-limited)"
+// @Failure     500 {object} problemDetails "Не удалось выполнить запрос (internal)"
+// @Failure     504 {object} problemDetails "Время ожидания вышло (timeout)"
+// @Router      /admin/transactions/{transactionId}/reverse [post].
+func (r *adminRoutes) reverseTransaction(c *gin.Context) {
+	var (
+		uri     transactionURI
+		request reasonRequest
+	)
+
+	if err := c.ShouldBindUri(&uri); err != nil {
+		_ = c.Error(err).SetType(gin.ErrorTypeBind)
+		return
+	}
+
+	if err := c.ShouldBindJSON(&request); err != nil {
+		_ = c.Error(err).SetType(gin.ErrorTypeBind)
+		return
+	}
+
+	transaction, err := r.a.ReverseTransaction(c.Request.Context(), uri.TransactionID, request.Reason)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, transaction)
+}
+
+// @Description Параметры поиска в журнале аудита.
+type auditSearchQuery struct {
+	WalletID      string     `form:"walletId"      json:"walletId"`
+	TransactionID int64      `form:"transactionId" json:"transactionId" validate:"min=0"`
+	Actor         string     `form:"actor"         json:"actor"`
+	Action        string     `form:"action"        json:"action"`
+	RequestID     string     `form:"requestId"     json:"requestId"`
+	From          *time.Time `form:"from"          json:"from"          time_format:"2006-01-02T15:04:05Z07:00"`
+	To            *time.Time `form:"to"            json:"to"            time_format:"2006-01-02T15:04:05Z07:00"`
+	Limit         uint       `form:"limit"         json:"limit"         validate:"max=500"`
+	Offset        uint       `form:"offset"        json:"offset"`
+}
+
+// @Summary     Поиск в журнале аудита
+// @Description Записи журнала неизменяемы и связаны цепочкой хешей, целостность проверяется командой audit-verify.
+// @Tags  	    Admin
+// @Security    ApiKeyAuth
+// @Security    BearerAuth
+// @Param walletId      query string false "ID кошелька"
+// @Param transactionId query int    false "ID перевода"
+// @Param actor         query string false "Инициатор операции, например user:42 или key:wr_..."
+// @Param action        query string false "Операция, например funds.sent"
+// @Param requestId     query string false "ID запроса"
+// @Param from          query string false "Начало периода (RFC 3339)"
+// @Param to            query string false "Конец периода, не включая (RFC 3339)"
+// @Param limit         query int    false "Количество записей, до 500" default(50)
+// @Param offset        query int    false "Смещение"
+// @Success     200 {object} []entity.AuditEntry "Найденные записи"
+// @Failure     400 {object} problemDetails "Ошибка в пользовательском запросе (validation-failed, malformed-request)"
+// @Failure     401 {object} problemDetails "Запрос не аутентифицирован (unauthorized)"
+// @Failure     403 {object} problemDetails "Нет доступа к операции (forbidden)"
+// @Failure     429 {object} problemDetails "Превышен лимит запросов (rate-limited)"
+// @Failure     500 {object} problemDetails "Не удалось выполнить запрос (internal)"
+// @Failure     504 {object} problemDetails "Время ожидания вышло (timeout)"
+// @Router      /admin/audit [get].
+func (r *adminRoutes) searchAudit(c *gin.Context) {
+	var query auditSearchQuery
+
+	if err := c.ShouldBindQuery(&query); err != nil {
+		_ = c.Error(err).SetType(gin.ErrorTypeBind)
+		return
+	}
+
+	entries, err := r.a.SearchAudit(c.Request.Context(), entity.AuditFilter{
+		WalletID:      query.WalletID,
+		TransactionID: query.TransactionID,
+		Actor:         query.Actor,
+		Action:        query.Action,
+		RequestID:     query.RequestID,
+		From:          query.From,
+		To:            query.To,
+		Limit:         query.Limit,
+		Offset:        query.Offset,
+	})
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, entries)
+}
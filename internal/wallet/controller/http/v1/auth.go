@@ -0,0 +1,114 @@
+package v1
+
+import (
+	"WalletRieltaTestTask/internal/entity"
+	"WalletRieltaTestTask/internal/wallet/usecase"
+	"bytes"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	headerAPIKey    = "X-API-Key"
+	headerAPIKeyID  = "X-API-Key-ID"
+	headerTimestamp = "X-Timestamp"
+	headerNonce     = "X-Nonce"
+	headerSignature = "X-Signature"
+
+	bearerPrefix = "Bearer "
+
+	_maxSignedBodySize = 1 << 20
+)
+
+// Authenticates the request by the end user bearer token, by the raw api key
+// or by the HMAC signature made with the key, and puts the caller into the request context.
+func authenticate(a usecase.Auth) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var (
+			principal *entity.Principal
+			err       error
+		)
+
+		authorization := c.GetHeader("Authorization")
+
+		switch {
+		case strings.HasPrefix(authorization, bearerPrefix):
+			principal, err = a.AuthenticateToken(c.Request.Context(), strings.TrimPrefix(authorization, bearerPrefix))
+		case c.GetHeader(headerSignature) != "":
+			principal, err = authenticateSigned(c, a)
+		default:
+			principal, err = a.AuthenticateAPIKey(c.Request.Context(), c.GetHeader(headerAPIKey))
+		}
+
+		if err != nil {
+			_ = c.Error(err)
+			c.Abort()
+
+			return
+		}
+
+		c.Request = c.Request.WithContext(entity.ContextWithPrincipal(c.Request.Context(), principal))
+
+		c.Next()
+	}
+}
+
+func authenticateSigned(c *gin.Context, a usecase.Auth) (*entity.Principal, error) {
+	timestamp, err := strconv.ParseInt(c.GetHeader(headerTimestamp), 10, 64)
+	if err != nil {
+		return nil, entity.ErrInvalidSignature
+	}
+
+	body, err := io.ReadAll(http.MaxBytesReader(c.Writer, c.Request.Body, _maxSignedBodySize))
+	if err != nil {
+		return nil, fmt.Errorf("authenticateSigned - io.ReadAll: %w", err)
+	}
+
+	// Body is consumed by the signature check, handlers need it again.
+	c.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+	return a.AuthenticateSignedRequest(c.Request.Context(), &entity.SignedRequest{
+		KeyID:     c.GetHeader(headerAPIKeyID),
+		Timestamp: time.Unix(timestamp, 0),
+		Nonce:     c.GetHeader(headerNonce),
+		Signature: c.GetHeader(headerSignature),
+		Method:    c.Request.Method,
+		Path:      c.Request.URL.RequestURI(),
+		Body:      body,
+	})
+}
+
+// Allows the request only if the caller has the scope.
+func requireScope(scope string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		principal, ok := entity.PrincipalFromContext(c.Request.Context())
+		if !ok || !principal.HasScope(scope) {
+			_ = c.Error(entity.ErrForbidden)
+			c.Abort()
+
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// Allows the request only if any of the caller roles grants the permission.
+func requirePermission(permission string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		principal, ok := entity.PrincipalFromContext(c.Request.Context())
+		if !ok || !principal.Can(permission) {
+			_ = c.Error(entity.ErrForbidden)
+			c.Abort()
+
+			return
+		}
+
+		c.Next()
+	}
+}
@@ -0,0 +1,168 @@
+package v1
+
+import (
+	"WalletRieltaTestTask/internal/entity"
+	walletUC "WalletRieltaTestTask/internal/wallet/usecase"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"github.com/gin-gonic/gin"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+const _testSigningSecret = "signing-secret"
+
+// Api key with the signing secret, nonces are registered in memory.
+type authGateway struct {
+	mu     sync.Mutex
+	nonces map[string]bool
+}
+
+func (gw *authGateway) GetAPIKeyByID(_ context.Context, id string) (*entity.APIKey, error) {
+	if id != "key-1" {
+		return nil, entity.ErrUnauthorized
+	}
+
+	return &entity.APIKey{ID: id, SigningSecret: _testSigningSecret, Scopes: []string{entity.ScopeTransfer}}, nil
+}
+
+func (gw *authGateway) RegisterNonce(_ context.Context, keyID, nonce string, _ time.Time) (bool, error) {
+	gw.mu.Lock()
+	defer gw.mu.Unlock()
+
+	if gw.nonces[keyID+nonce] {
+		return false, nil
+	}
+
+	gw.nonces[keyID+nonce] = true
+
+	return true, nil
+}
+
+// Handler replies with the body it reads after the signature check.
+func newAuthHandler() http.Handler {
+	gin.SetMode(gin.TestMode)
+
+	handler := gin.New()
+	handler.Use(errorHandler(slog.New(slog.NewTextHandler(io.Discard, nil))))
+	handler.POST("/api/v1/wallet/:walletId/send",
+		authenticate(walletUC.NewAuth(&authGateway{nonces: make(map[string]bool)})),
+		func(c *gin.Context) {
+			body, _ := io.ReadAll(c.Request.Body)
+			c.String(http.StatusOK, string(body))
+		},
+	)
+
+	return handler
+}
+
+type signedRequest struct {
+	timestamp time.Time
+	nonce     string
+	body      string
+}
+
+// Signs the request and sends the body, the body may differ from the signed one.
+func (r signedRequest) send(handler http.Handler, body string) *httptest.ResponseRecorder {
+	const path = "/api/v1/wallet/alice/send"
+
+	payload := (&entity.SignedRequest{
+		Timestamp: r.timestamp,
+		Nonce:     r.nonce,
+		Method:    http.MethodPost,
+		Path:      path,
+		Body:      []byte(r.body),
+	}).Payload()
+
+	mac := hmac.New(sha256.New, []byte(_testSigningSecret))
+	mac.Write(payload)
+
+	req := httptest.NewRequest(http.MethodPost, path, strings.NewReader(body))
+	req.Header.Set(headerAPIKeyID, "key-1")
+	req.Header.Set(headerTimestamp, strconv.FormatInt(r.timestamp.Unix(), 10))
+	req.Header.Set(headerNonce, r.nonce)
+	req.Header.Set(headerSignature, hex.EncodeToString(mac.Sum(nil)))
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+
+	return w
+}
+
+func TestSignedRequest(t *testing.T) {
+	const body = `{"to":"bob","amount":30}`
+
+	tests := []struct {
+		name    string
+		request signedRequest
+		body    string
+		status  int
+		problem string
+	}{
+		{"valid", signedRequest{time.Now(), "nonce-1", body}, body, http.StatusOK, ""},
+		{"tampered body", signedRequest{time.Now(), "nonce-2", body}, `{"to":"bob","amount":3000}`,
+			http.StatusUnauthorized, "invalid-signature"},
+		{"stale timestamp", signedRequest{time.Now().Add(-10 * time.Minute), "nonce-3", body}, body,
+			http.StatusUnauthorized, "invalid-signature"},
+		{"future timestamp", signedRequest{time.Now().Add(10 * time.Minute), "nonce-4", body}, body,
+			http.StatusUnauthorized, "invalid-signature"},
+	}
+
+	handler := newAuthHandler()
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := tt.request.send(handler, tt.body)
+
+			if w.Code != tt.status {
+				t.Fatalf("status = %d %s, want %d", w.Code, w.Body, tt.status)
+			}
+
+			if tt.problem == "" {
+				// The body is read again by the handler after the check
+				if w.Body.String() != body {
+					t.Fatalf("handler read %q, want %q", w.Body, body)
+				}
+
+				return
+			}
+
+			if !strings.Contains(w.Body.String(), problemTypePrefix+tt.problem) {
+				t.Fatalf("problem = %s, want %s", w.Body, tt.problem)
+			}
+		})
+	}
+}
+
+func TestSignedRequestReplay(t *testing.T) {
+	handler := newAuthHandler()
+	request := signedRequest{time.Now(), "nonce-1", `{"to":"bob","amount":30}`}
+
+	if w := request.send(handler, request.body); w.Code != http.StatusOK {
+		t.Fatalf("first request = %d %s, want 200", w.Code, w.Body)
+	}
+
+	w := request.send(handler, request.body)
+	if w.Code != http.StatusUnauthorized || !strings.Contains(w.Body.String(), problemTypePrefix+"request-replayed") {
+		t.Fatalf("replayed request = %d %s, want 401 request-replayed", w.Code, w.Body)
+	}
+
+	// A rejected signature doesn't use the nonce up
+	rejected := signedRequest{time.Now(), "nonce-2", request.body}
+	if w = rejected.send(handler, "{}"); w.Code != http.StatusUnauthorized {
+		t.Fatalf("tampered request = %d %s, want 401", w.Code, w.Body)
+	}
+
+	if w = rejected.send(handler, rejected.body); w.Code != http.StatusOK {
+		t.Fatalf("request with the nonce of a rejected one = %d %s, want 200", w.Code, w.Body)
+	}
+}
@@ -0,0 +1,170 @@
+package v1
+
+import (
+	"WalletRieltaTestTask/internal/entity"
+	"WalletRieltaTestTask/pkg/logger"
+	"encoding/json"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"log/slog"
+	"net/http"
+)
+
+const (
+	contentTypeProblem = "application/problem+json"
+
+	problemTypePrefix = "urn:wallet-rielta:problem:"
+)
+
+// @Description Описание ошибки в формате RFC 7807 (application/problem+json).
+type problemDetails struct {
+	Type      string        `json:"type"              example:"urn:wallet-rielta:problem:validation-failed" description:"URI типа ошибки"`               //nolint:lll,tagalign // вот так то лучше
+	Title     string        `json:"title"             example:"Request validation failed"                   description:"Краткое описание типа ошибки"`  //nolint:lll,tagalign // вот так то лучше
+	Status    int           `json:"status"            example:"400"                                         description:"HTTP статус ответа"`            //nolint:lll,tagalign // вот так то лучше
+	Detail    string        `json:"detail,omitempty"  example:"request body has invalid fields"             description:"Описание конкретной ошибки"`    //nolint:lll,tagalign // вот так то лучше
+	Instance  string        `json:"instance"          example:"/api/v1/wallet/5b53700ed469fa6a09ea72bb78f36fd9/send" description:"Путь запроса"`         //nolint:lll,tagalign // вот так то лучше
+	RequestID string        `json:"requestId"         example:"0b4e5a3c-5a3f-4c4b-9a5e-2f0d1f7f6c11"        description:"ID запроса для поиска в логах"` //nolint:lll,tagalign // вот так то лучше
+	Errors    []errorDetail `json:"errors,omitempty"                                                        description:"Ошибки валидации полей"`        //nolint:lll,tagalign // вот так то лучше
+}
+
+// @Description Подробности ошибки валидации поля.
+type errorDetail struct {
+	Field string `json:"field" example:"amount"   description:"Поле запроса"`
+	Rule  string `json:"rule"  example:"required" description:"Нарушенное правило"`
+}
+
+type problemType struct {
+	err    error
+	status int
+	name   string
+	title  string
+}
+
+var (
+	problemValidation  = problemType{nil, http.StatusBadRequest, "validation-failed", "Request validation failed"}
+	problemMalformed   = problemType{nil, http.StatusBadRequest, "malformed-request", "Malformed request body"}
+	problemTooLarge    = problemType{nil, http.StatusRequestEntityTooLarge, "request-too-large", "Request body is too large"}
+	problemRateLimited = problemType{nil, http.StatusTooManyRequests, "rate-limited", "Too many requests"}
+	problemInternal    = problemType{nil, http.StatusInternalServerError, "internal", "Internal server error"}
+)
+
+// Problem types of domain errors.
+var problemTypes = []problemType{
+	{entity.ErrWrongAmount, http.StatusBadRequest, "wrong-amount", "Amount must be greater than zero"},
+	{entity.ErrEmptyWallet, http.StatusBadRequest, "empty-wallet", "Wallet address is empty"},
+	{entity.ErrSenderIsReceiver, http.StatusBadRequest, "sender-is-receiver", "Sender and receiver are the same wallet"},
+	{entity.ErrNotWalletOwner, http.StatusForbidden, "not-wallet-owner", "Wallet belongs to another user"},
+	{entity.ErrWalletFrozen, http.StatusConflict, "wallet-frozen", "Wallet is frozen"},
+	{entity.ErrNotEnoughFunds, http.StatusConflict, "not-enough-funds", "Not enough funds on the wallet"},
+	{entity.ErrBalanceTooLarge, http.StatusUnprocessableEntity, "balance-too-large", "Balance would exceed the maximum"},
+	{entity.ErrTransactionNotFound, http.StatusNotFound, "transaction-not-found", "Transaction not found"},
+	{entity.ErrAlreadyReversed, http.StatusConflict, "already-reversed", "Transaction already reversed"},
+	{entity.ErrReversalNotReversible, http.StatusConflict, "reversal-not-reversible", "Reversal transaction can't be reversed"},
+	{entity.ErrInvalidIdempotencyKey, http.StatusBadRequest, "invalid-idempotency-key", "Idempotency key is longer than 255 characters"},
+	{entity.ErrIdempotencyKeyConflict, http.StatusUnprocessableEntity, "idempotency-key-conflict", "Idempotency key is used by another transfer"},
+	{entity.ErrReasonRequired, http.StatusBadRequest, "reason-required", "Reason of the operation is required"},
+	{entity.ErrWebhookNotFound, http.StatusNotFound, "webhook-not-found", "Webhook not found"},
+	{entity.ErrInvalidWebhookURL, http.StatusBadRequest, "invalid-webhook-url", "Webhook url must be an absolute http or https url"},
+	{entity.ErrUnknownEventType, http.StatusBadRequest, "unknown-event-type", "Unknown event type"},
+	{entity.ErrEventNotFound, http.StatusNotFound, "event-not-found", "Event not found"},
+	{entity.ErrWalletNotFound, http.StatusNotFound, "wallet-not-found", "Wallet not found"},
+	{entity.ErrNotFound, http.StatusNotFound, "not-found", "Resource not found"},
+	{entity.ErrTimeout, http.StatusGatewayTimeout, "timeout", "Request timed out"},
+	{entity.ErrCallTimeout, http.StatusGatewayTimeout, "timeout", "Request timed out"},
+	{entity.ErrUnavailable, http.StatusServiceUnavailable, "unavailable", "Service is temporarily unavailable"},
+	{entity.ErrUnauthorized, http.StatusUnauthorized, "unauthorized", "Missing or invalid credentials"},
+	{entity.ErrSignatureRequired, http.StatusUnauthorized, "signature-required", "Api key requires signed requests"},
+	{entity.ErrInvalidSignature, http.StatusUnauthorized, "invalid-signature", "Invalid or expired request signature"},
+	{entity.ErrRequestReplayed, http.StatusUnauthorized, "request-replayed", "Request nonce has already been used"},
+	{entity.ErrForbidden, http.StatusForbidden, "forbidden", "No access to the operation"},
+}
+
+func (p problemType) problem(c *gin.Context, detail string) problemDetails {
+	return problemDetails{
+		Type:      problemTypePrefix + p.name,
+		Title:     p.title,
+		Status:    p.status,
+		Detail:    detail,
+		Instance:  c.Request.URL.Path,
+		RequestID: c.GetString(ctxKeyRequestID),
+	}
+}
+
+// Converts errors attached to the context by handlers into a problem details response.
+func errorHandler(l *slog.Logger) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Next()
+
+		if len(c.Errors) == 0 || c.Writer.Written() {
+			return
+		}
+
+		ginErr := c.Errors.Last()
+
+		problem := newProblem(c, ginErr)
+
+		if problem.Status == http.StatusInternalServerError {
+			l.Error("http - v1 - "+c.FullPath(),
+				logger.Err(ginErr.Err),
+				slog.String("requestId", problem.RequestID),
+			)
+		}
+
+		c.Header("Content-Type", contentTypeProblem)
+		c.AbortWithStatusJSON(problem.Status, problem)
+	}
+}
+
+func newProblem(c *gin.Context, ginErr *gin.Error) problemDetails {
+	if ginErr.IsType(gin.ErrorTypeBind) {
+		return bindProblem(c, ginErr.Err)
+	}
+
+	for _, p := range problemTypes {
+		if errors.Is(ginErr.Err, p.err) {
+			return p.problem(c, "")
+		}
+	}
+
+	if errors.Is(ginErr.Err, errRateLimited) {
+		return problemRateLimited.problem(c, "retry after "+c.Writer.Header().Get("Retry-After")+" seconds")
+	}
+
+	var maxBytesErr *http.MaxBytesError
+	if errors.As(ginErr.Err, &maxBytesErr) {
+		return problemTooLarge.problem(c, "")
+	}
+
+	return problemInternal.problem(c, "")
+}
+
+func bindProblem(c *gin.Context, err error) problemDetails {
+	var validationErrors validator.ValidationErrors
+	if errors.As(err, &validationErrors) {
+		problem := problemValidation.problem(c, "request body has invalid fields")
+		for _, fe := range validationErrors {
+			problem.Errors = append(problem.Errors, errorDetail{Field: fe.Field(), Rule: fe.Tag()})
+		}
+
+		return problem
+	}
+
+	var (
+		syntaxErr *json.SyntaxError
+		typeErr   *json.UnmarshalTypeError
+	)
+
+	if errors.As(err, &typeErr) {
+		problem := problemMalformed.problem(c, "request body field has wrong type")
+		problem.Errors = []errorDetail{{Field: typeErr.Field, Rule: "type"}}
+
+		return problem
+	}
+
+	if errors.As(err, &syntaxErr) {
+		return problemMalformed.problem(c, "request body is not valid json")
+	}
+
+	return problemMalformed.problem(c, "")
+}
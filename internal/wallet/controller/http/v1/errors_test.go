@@ -0,0 +1,46 @@
+package v1
+
+import (
+	"WalletRieltaTestTask/internal/entity"
+	"WalletRieltaTestTask/pkg/rabbitmq/rmq_rpc"
+	"errors"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestNewProblem(t *testing.T) {
+	tests := []struct {
+		err     error
+		status  int
+		problem string
+	}{
+		{entity.ErrNotEnoughFunds, http.StatusConflict, "not-enough-funds"},
+		{entity.ErrBalanceTooLarge, http.StatusUnprocessableEntity, "balance-too-large"},
+		{entity.ErrReversalNotReversible, http.StatusConflict, "reversal-not-reversible"},
+		{fmt.Errorf("send: %w", entity.ErrIdempotencyKeyConflict), http.StatusUnprocessableEntity, "idempotency-key-conflict"},
+		{entity.ErrTimeout, http.StatusGatewayTimeout, "timeout"},
+		// The client timeout of the RPC is shorter than the context of the usecase
+		{fmt.Errorf("RemoteCall: %w", rmq_rpc.ErrTimeout), http.StatusGatewayTimeout, "timeout"},
+		{rmq_rpc.ErrConnectionLost, http.StatusServiceUnavailable, "unavailable"},
+		{rmq_rpc.ErrNoConsumers, http.StatusServiceUnavailable, "unavailable"},
+		{entity.ErrUnauthorized, http.StatusUnauthorized, "unauthorized"},
+		{&http.MaxBytesError{Limit: 1}, http.StatusRequestEntityTooLarge, "request-too-large"},
+		{errors.New("connection refused"), http.StatusInternalServerError, "internal"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.err.Error(), func(t *testing.T) {
+			c, _ := gin.CreateTestContext(httptest.NewRecorder())
+			c.Request = httptest.NewRequest(http.MethodPost, "/api/v1/wallet/alice/send", nil)
+
+			problem := newProblem(c, &gin.Error{Err: tt.err, Type: gin.ErrorTypePrivate})
+
+			if problem.Status != tt.status || problem.Type != problemTypePrefix+tt.problem {
+				t.Fatalf("problem = %d %s, want %d %s", problem.Status, problem.Type, tt.status, tt.problem)
+			}
+		})
+	}
+}
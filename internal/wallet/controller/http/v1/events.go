@@ -0,0 +1,74 @@
+package v1
+
+import (
+	"github.com/gin-contrib/sse"
+	"github.com/gin-gonic/gin"
+	"io"
+	"net/http"
+	"time"
+)
+
+const (
+	// Comment line sent to keep idle connections open through proxies.
+	_streamHeartbeat = 15 * time.Second
+
+	headerLastEventID = "Last-Event-ID"
+)
+
+// @Summary     Поток событий кошелька
+// @Description Server-Sent Events с событиями кошелька (wallet.created, funds.sent, funds.received, balance.adjusted) после их фиксации.
+// @Description Поле id события передается в заголовке Last-Event-ID при переподключении, чтобы получить пропущенные события.
+// @Description События доставляются как минимум один раз, повторы отбрасываются по id.
+// @Description Медленный клиент отключается и продолжает с последнего полученного события.
+// @Tags  	    Wallet
+// @Security    ApiKeyAuth
+// @Security    BearerAuth
+// @Produce     text/event-stream
+// @Param walletId      path   string true  "ID кошелька"
+// @Param Last-Event-ID header string false "ID последнего полученного события"
+// @Success     200 {object} entity.Event "Поток событий"
+// @Failure     401 {object} problemDetails "Запрос не аутентифицирован (unauthorized, signature-required, invalid-signature, request-replayed)"
+// @Failure     403 {object} problemDetails "Нет доступа к операции (forbidden, not-wallet-owner)"
+// @Failure     404 {object} problemDetails "Кошелек или событие не найдены (wallet-not-found, event-not-found)"
+// @Failure     429 {object} problemDetails "Превышен лимит запросов (rate-limited)"
+// @Failure     500 {object} problemDetails "Не удалось выполнить запрос (internal)"
+// @Failure     504 {object} problemDetails "Время ожидания вышло (timeout)"
+// @Router      /v1/wallet/{walletId}/events [get].
+func (r *walletRoutes) walletEvents(c *gin.Context) {
+	events, err := r.e.Subscribe(c.Request.Context(), c.Param("walletId"), c.GetHeader(headerLastEventID))
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	// The stream outlives the write timeout of the server
+	_ = http.NewResponseController(c.Writer).SetWriteDeadline(time.Time{})
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("X-Accel-Buffering", "no")
+	c.Status(http.StatusOK)
+	c.Writer.Flush()
+
+	heartbeat := time.NewTicker(_streamHeartbeat)
+	defer heartbeat.Stop()
+
+	c.Stream(func(w io.Writer) bool {
+		select {
+		case event, ok := <-events:
+			if !ok {
+				return false
+			}
+
+			c.Render(-1, sse.Event{
+				Id:    event.ID,
+				Event: event.Type,
+				Data:  event,
+			})
+		case <-heartbeat.C:
+			_, _ = io.WriteString(w, ": heartbeat\n\n")
+		}
+
+		return true
+	})
+}
@@ -0,0 +1,31 @@
+package v1
+
+import (
+	"WalletRieltaTestTask/internal/entity"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+const (
+	headerRequestID = "X-Request-ID"
+
+	ctxKeyRequestID = "requestId"
+)
+
+// Takes the request id from the incoming header or generates a new one,
+// and returns it to the client in the response headers.
+// The request id and the client ip are passed to the usecases for the audit log.
+func requestID() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id := c.GetHeader(headerRequestID)
+		if id == "" {
+			id = uuid.New().String()
+		}
+
+		c.Set(ctxKeyRequestID, id)
+		c.Header(headerRequestID, id)
+		c.Request = c.Request.WithContext(entity.ContextWithRequestInfo(c.Request.Context(), id, c.ClientIP()))
+
+		c.Next()
+	}
+}
@@ -0,0 +1,83 @@
+package v1
+
+import (
+	"WalletRieltaTestTask/internal/entity"
+	"WalletRieltaTestTask/pkg/ratelimit"
+	"errors"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"math"
+	"strconv"
+	"time"
+)
+
+var errRateLimited = errors.New("rate limit exceeded")
+
+// RateLimits - limiters of the api routes, routes without own limiter share the default one.
+// Routes are keyed by the method and the path pattern, e.g. "POST /api/v1/wallet".
+// IP limits all requests of the client ip before authentication, so invalid credentials are limited too.
+type RateLimits struct {
+	Default *ratelimit.Limiter
+	Routes  map[string]*ratelimit.Limiter
+	IP      *ratelimit.Limiter
+}
+
+// Limits requests of every client ip before authentication.
+func ipRateLimit(limits RateLimits) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if limits.IP == nil || limit(c, limits.IP, "ip:"+c.ClientIP()) {
+			c.Next()
+		}
+	}
+}
+
+// Limits requests of every authenticated client separately. The client is the api key or the token subject.
+func rateLimit(limits RateLimits) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		route := c.Request.Method + " " + c.FullPath()
+
+		limiter, ok := limits.Routes[route]
+		if !ok {
+			limiter, route = limits.Default, ""
+		}
+
+		if limiter == nil {
+			c.Next()
+			return
+		}
+
+		var actor string
+		if principal, ok := entity.PrincipalFromContext(c.Request.Context()); ok {
+			actor = principal.Actor()
+		}
+
+		if limit(c, limiter, route+"|"+actor) {
+			c.Next()
+		}
+	}
+}
+
+// Takes a token of the key and sets the headers, the request is aborted if the limit is exceeded.
+func limit(c *gin.Context, limiter *ratelimit.Limiter, key string) bool {
+	result := limiter.Allow(key)
+
+	c.Header("RateLimit-Policy", fmt.Sprintf("%d;w=%d", limiter.Requests(), seconds(limiter.Period())))
+	c.Header("RateLimit-Limit", strconv.Itoa(result.Limit))
+	c.Header("RateLimit-Remaining", strconv.Itoa(result.Remaining))
+	c.Header("RateLimit-Reset", strconv.Itoa(seconds(result.Reset)))
+
+	if !result.Allowed {
+		c.Header("Retry-After", strconv.Itoa(seconds(result.RetryAfter)))
+		_ = c.Error(errRateLimited)
+		c.Abort()
+
+		return false
+	}
+
+	return true
+}
+
+// Headers contain whole seconds, rounded up so clients don't retry too early.
+func seconds(d time.Duration) int {
+	return int(math.Ceil(d.Seconds()))
+}
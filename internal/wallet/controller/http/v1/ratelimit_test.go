@@ -0,0 +1,112 @@
+package v1
+
+import (
+	"WalletRieltaTestTask/internal/entity"
+	"WalletRieltaTestTask/pkg/ratelimit"
+	"encoding/json"
+	"github.com/gin-gonic/gin"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// Routes behind the limits, the api key header is the caller.
+func newRateLimitedHandler(limits RateLimits) http.Handler {
+	gin.SetMode(gin.TestMode)
+
+	handler := gin.New()
+	handler.Use(errorHandler(slog.New(slog.NewTextHandler(io.Discard, nil))))
+
+	h := handler.Group("/api/v1", ipRateLimit(limits), func(c *gin.Context) {
+		principal := &entity.Principal{KeyID: c.GetHeader(headerAPIKey)}
+		c.Request = c.Request.WithContext(entity.ContextWithPrincipal(c.Request.Context(), principal))
+	}, rateLimit(limits))
+
+	h.POST("/wallet", func(c *gin.Context) { c.Status(http.StatusCreated) })
+	h.GET("/wallet/:walletId", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	return handler
+}
+
+func limitedRequest(handler http.Handler, method, path, key, ip string) *httptest.ResponseRecorder {
+	r := httptest.NewRequest(method, path, nil)
+	r.Header.Set(headerAPIKey, key)
+	r.RemoteAddr = ip + ":40000"
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, r)
+
+	return w
+}
+
+func checkRateLimited(t *testing.T, w *httptest.ResponseRecorder, retryAfter string) {
+	t.Helper()
+
+	if w.Code != http.StatusTooManyRequests || w.Header().Get("Content-Type") != contentTypeProblem {
+		t.Fatalf("response = %d %s, want 429 problem", w.Code, w.Header().Get("Content-Type"))
+	}
+
+	if w.Header().Get("Retry-After") != retryAfter {
+		t.Fatalf("Retry-After = %q, want %q", w.Header().Get("Retry-After"), retryAfter)
+	}
+
+	var problem problemDetails
+	if err := json.Unmarshal(w.Body.Bytes(), &problem); err != nil {
+		t.Fatalf("json.Unmarshal: %v", err)
+	}
+
+	if problem.Type != problemTypePrefix+"rate-limited" || problem.Detail != "retry after "+retryAfter+" seconds" {
+		t.Fatalf("problem = %+v, want rate-limited", problem)
+	}
+}
+
+func TestRateLimit(t *testing.T) {
+	handler := newRateLimitedHandler(RateLimits{
+		Default: ratelimit.New(3, time.Minute),
+		Routes:  map[string]*ratelimit.Limiter{"POST /api/v1/wallet": ratelimit.New(1, time.Minute)},
+	})
+
+	w := limitedRequest(handler, http.MethodPost, "/api/v1/wallet", "key-1", "10.0.0.1")
+	if w.Code != http.StatusCreated {
+		t.Fatalf("first request = %d, want 201", w.Code)
+	}
+
+	for header, want := range map[string]string{
+		"RateLimit-Policy":    "1;w=60",
+		"RateLimit-Limit":     "1",
+		"RateLimit-Remaining": "0",
+		"RateLimit-Reset":     "60",
+	} {
+		if got := w.Header().Get(header); got != want {
+			t.Fatalf("%s = %q, want %q", header, got, want)
+		}
+	}
+
+	checkRateLimited(t, limitedRequest(handler, http.MethodPost, "/api/v1/wallet", "key-1", "10.0.0.1"), "60")
+
+	// Other clients and routes have their buckets
+	if w = limitedRequest(handler, http.MethodPost, "/api/v1/wallet", "key-2", "10.0.0.1"); w.Code != http.StatusCreated {
+		t.Fatalf("request of another key = %d, want 201", w.Code)
+	}
+
+	if w = limitedRequest(handler, http.MethodGet, "/api/v1/wallet/alice", "key-1", "10.0.0.1"); w.Code != http.StatusOK {
+		t.Fatalf("request of another route = %d, want 200", w.Code)
+	}
+}
+
+func TestIPRateLimit(t *testing.T) {
+	handler := newRateLimitedHandler(RateLimits{IP: ratelimit.New(2, time.Minute)})
+
+	limitedRequest(handler, http.MethodGet, "/api/v1/wallet/alice", "key-1", "10.0.0.1")
+	limitedRequest(handler, http.MethodGet, "/api/v1/wallet/alice", "key-2", "10.0.0.1")
+
+	// The bucket of the ip is shared by all keys
+	checkRateLimited(t, limitedRequest(handler, http.MethodGet, "/api/v1/wallet/alice", "key-3", "10.0.0.1"), "30")
+
+	if w := limitedRequest(handler, http.MethodGet, "/api/v1/wallet/alice", "key-1", "10.0.0.2"); w.Code != http.StatusOK {
+		t.Fatalf("request of another ip = %d, want 200", w.Code)
+	}
+}
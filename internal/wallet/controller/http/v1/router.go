@@ -4,16 +4,33 @@ import (
 	_ "WalletRieltaTestTask/docs"
 	"WalletRieltaTestTask/internal/wallet/usecase"
 	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
 	swaggerFiles "github.com/swaggo/files"
 	ginSwagger "github.com/swaggo/gin-swagger"
 	"log/slog"
 	"net/http"
+	"reflect"
+	"strings"
 )
 
-func NewRouter(handler *gin.Engine, l *slog.Logger, w usecase.Wallet) {
+func NewRouter(
+	handler *gin.Engine,
+	l *slog.Logger,
+	w usecase.Wallet,
+	e usecase.WalletEvents,
+	a usecase.Auth,
+	adm usecase.Admin,
+	wh usecase.Webhooks,
+	limits RateLimits,
+) {
+	setupValidator()
+
+	handler.Use(requestID())
 	handler.Use(gin.Logger())
 	handler.Use(gin.Recovery())
+	handler.Use(errorHandler(l))
 
 	// Swagger
 	swaggerHandler := ginSwagger.DisablingWrapHandler(swaggerFiles.Handler, "DISABLE_SWAGGER_HTTP_HANDLER")
@@ -26,8 +43,35 @@ func NewRouter(handler *gin.Engine, l *slog.Logger, w usecase.Wallet) {
 	handler.GET("/metrics", gin.WrapH(promhttp.Handler()))
 
 	// Routers
-	h := handler.Group("/api/v1")
+	h := handler.Group("/api/v1", ipRateLimit(limits), authenticate(a), rateLimit(limits))
 	{
-		newWalletRoutes(h, w, l)
+		newWalletRoutes(h, w, e, l)
+		newWebhookRoutes(h, wh)
+		newStreamRoutes(h, e, l)
 	}
+
+	// Operators api, access is granted by roles
+	admin := handler.Group("/api/admin", ipRateLimit(limits), authenticate(a), rateLimit(limits))
+	{
+		newAdminRoutes(admin, adm)
+	}
+}
+
+// Request models are annotated with `validate` tags, so gin's validator is switched to them.
+// Field names in validation errors are taken from json tags.
+func setupValidator() {
+	v, ok := binding.Validator.Engine().(*validator.Validate)
+	if !ok {
+		return
+	}
+
+	v.SetTagName("validate")
+	v.RegisterTagNameFunc(func(field reflect.StructField) string {
+		name := strings.SplitN(field.Tag.Get("json"), ",", 2)[0]
+		if name == "-" {
+			return ""
+		}
+
+		return name
+	})
 }
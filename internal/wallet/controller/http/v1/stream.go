@@ -0,0 +1,334 @@
+package v1
+
+import (
+	"WalletRieltaTestTask/internal/entity"
+	"WalletRieltaTestTask/internal/wallet/usecase"
+	"WalletRieltaTestTask/pkg/logger"
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+const (
+	_streamWriteWait = 10 * time.Second
+	// Connection without a pong for this time is dead.
+	_streamPongWait   = 60 * time.Second
+	_streamPingPeriod = _streamPongWait * 9 / 10
+
+	_streamReadLimit     = 4096
+	_streamOutBuffer     = 64
+	_streamMaxSubscribed = 100
+)
+
+// Commands of the client.
+const (
+	streamSubscribe   = "subscribe"
+	streamUnsubscribe = "unsubscribe"
+)
+
+// Messages of the server.
+const (
+	streamEvent        = "event"
+	streamSubscribed   = "subscribed"
+	streamUnsubscribed = "unsubscribed"
+	streamError        = "error"
+)
+
+var (
+	errUnknownCommand    = errors.New("unknown command")
+	errTooManySubscribed = errors.New("too many subscriptions")
+	errAlreadySubscribed = errors.New("already subscribed")
+	errNotSubscribed     = errors.New("not subscribed")
+
+	streamProblems = []problemType{
+		{errUnknownCommand, 0, "unknown-command", "Action must be subscribe or unsubscribe"},
+		{errTooManySubscribed, 0, "too-many-subscriptions", "Too many wallets on one connection"},
+		{errAlreadySubscribed, 0, "already-subscribed", "Wallet is already subscribed"},
+		{errNotSubscribed, 0, "not-subscribed", "Wallet is not subscribed"},
+	}
+)
+
+var upgrader = websocket.Upgrader{
+	ReadBufferSize:  1024,
+	WriteBufferSize: 1024,
+}
+
+// @Description Команда клиента websocket соединения.
+type streamCommand struct {
+	Action      string `json:"action"      example:"subscribe"                        description:"subscribe или unsubscribe"` //nolint:lll,tagalign // вот так то лучше
+	WalletID    string `json:"walletId"    example:"5b53700ed469fa6a09ea72bb78f36fd9" description:"ID кошелька"`
+	LastEventID string `json:"lastEventId" example:"1f0e7c9a-3b1e-4a43-9d7e-5d3c2a1b0f9e" description:"ID последнего полученного события кошелька"` //nolint:lll,tagalign // вот так то лучше
+}
+
+// @Description Сообщение сервера websocket соединения.
+type streamMessage struct {
+	Type     string        `json:"type"               example:"event"                            description:"event, subscribed, unsubscribed или error"` //nolint:lll,tagalign // вот так то лучше
+	WalletID string        `json:"walletId,omitempty" example:"5b53700ed469fa6a09ea72bb78f36fd9" description:"ID кошелька"`
+	Event    *entity.Event `json:"event,omitempty"                                               description:"Событие кошелька"`             //nolint:lll,tagalign // вот так то лучше
+	Problem  string        `json:"problem,omitempty"  example:"urn:wallet-rielta:problem:wallet-not-found" description:"URI типа ошибки"`    //nolint:lll,tagalign // вот так то лучше
+	Title    string        `json:"title,omitempty"    example:"Wallet not found"                 description:"Краткое описание типа ошибки"` //nolint:lll,tagalign // вот так то лучше
+}
+
+type streamRoutes struct {
+	e usecase.WalletEvents
+	l *slog.Logger
+}
+
+func newStreamRoutes(handler *gin.RouterGroup, e usecase.WalletEvents, l *slog.Logger) {
+	r := &streamRoutes{e, l}
+
+	handler.GET("/events", requireScope(entity.ScopeRead), r.stream)
+}
+
+// @Summary     WebSocket подписка на события кошельков
+// @Description Одно соединение получает события нескольких кошельков.
+// @Description Клиент отправляет команды {"action":"subscribe","walletId":"...","lastEventId":"..."}
+// @Description и {"action":"unsubscribe","walletId":"..."}, сервер отвечает сообщениями subscribed, unsubscribed, error
+// @Description и присылает события кошельков в сообщениях event.
+// @Description
+// @Description Сервер отправляет ping каждые 54 секунды и закрывает соединение без pong в течение минуты.
+// @Description Клиент, не успевающий читать события, отключается с кодом 1013 и переподписывается с lastEventId.
+// @Tags  	    Wallet
+// @Security    ApiKeyAuth
+// @Security    BearerAuth
+// @Param input body streamCommand false "Команда клиента"
+// @Success     101 {object} streamMessage "Соединение установлено"
+// @Failure     401 {object} problemDetails "Запрос не аутентифицирован (unauthorized, signature-required, invalid-signature, request-replayed)"
+// @Failure     403 {object} problemDetails "Нет доступа к операции (forbidden)"
+// @Failure     429 {object} problemDetails "Превышен лимит запросов (rate-limited)"
+// @Router      /v1/events [get].
+func (r *streamRoutes) stream(c *gin.Context) {
+	ws, err := upgrader.Upgrade(c.Writer, c.Request, nil)
+	if err != nil {
+		// Upgrader has already responded with the error status
+		return
+	}
+
+	ctx, cancel := context.WithCancel(c.Request.Context())
+
+	conn := &streamConn{
+		ws:        ws,
+		e:         r.e,
+		l:         r.l,
+		ctx:       ctx,
+		cancel:    cancel,
+		out:       make(chan streamMessage, _streamOutBuffer),
+		subs:      make(map[string]context.CancelFunc),
+		closeCode: websocket.CloseNormalClosure,
+	}
+
+	conn.run()
+}
+
+// streamConn - websocket connection with its wallet subscriptions.
+// All writes are made by the writer goroutine, commands are read by the handler goroutine.
+type streamConn struct {
+	ws *websocket.Conn
+	e  usecase.WalletEvents
+	l  *slog.Logger
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	out    chan streamMessage
+
+	mu   sync.Mutex
+	subs map[string]context.CancelFunc
+	wg   sync.WaitGroup
+
+	closeOnce sync.Once
+	closeCode int
+	closeText string
+}
+
+func (s *streamConn) run() {
+	writerDone := make(chan struct{})
+
+	go func() {
+		defer close(writerDone)
+		s.write()
+	}()
+
+	s.read()
+
+	s.cancel()
+	s.wg.Wait()
+	<-writerDone
+}
+
+func (s *streamConn) read() {
+	s.ws.SetReadLimit(_streamReadLimit)
+	_ = s.ws.SetReadDeadline(time.Now().Add(_streamPongWait))
+	s.ws.SetPongHandler(func(string) error {
+		return s.ws.SetReadDeadline(time.Now().Add(_streamPongWait))
+	})
+
+	for {
+		_, data, err := s.ws.ReadMessage()
+		if err != nil {
+			return
+		}
+
+		var cmd streamCommand
+
+		if err = json.Unmarshal(data, &cmd); err != nil {
+			s.send(streamErrorMessage("", problemMalformed))
+			continue
+		}
+
+		switch cmd.Action {
+		case streamSubscribe:
+			err = s.subscribe(cmd.WalletID, cmd.LastEventID)
+		case streamUnsubscribe:
+			err = s.unsubscribe(cmd.WalletID)
+		default:
+			err = errUnknownCommand
+		}
+
+		if err != nil {
+			s.send(streamErrorMessage(cmd.WalletID, streamProblem(err)))
+
+			if streamProblem(err) == problemInternal {
+				s.l.Error("http - v1 - stream", logger.Err(err))
+			}
+		}
+	}
+}
+
+func (s *streamConn) write() {
+	ping := time.NewTicker(_streamPingPeriod)
+	defer ping.Stop()
+	defer s.ws.Close()
+
+	for {
+		select {
+		case msg := <-s.out:
+			_ = s.ws.SetWriteDeadline(time.Now().Add(_streamWriteWait))
+
+			if err := s.ws.WriteJSON(msg); err != nil {
+				s.cancel()
+				return
+			}
+		case <-ping.C:
+			err := s.ws.WriteControl(websocket.PingMessage, nil, time.Now().Add(_streamWriteWait))
+			if err != nil {
+				s.cancel()
+				return
+			}
+		case <-s.ctx.Done():
+			// Later close calls must not change the code being written
+			s.closeOnce.Do(func() {})
+
+			_ = s.ws.WriteControl(
+				websocket.CloseMessage,
+				websocket.FormatCloseMessage(s.closeCode, s.closeText),
+				time.Now().Add(_streamWriteWait),
+			)
+
+			return
+		}
+	}
+}
+
+// Subscribing to the wallet and forwarding its events until unsubscribe.
+func (s *streamConn) subscribe(walletID, lastEventID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.subs[walletID]; ok {
+		return errAlreadySubscribed
+	}
+
+	if len(s.subs) >= _streamMaxSubscribed {
+		return errTooManySubscribed
+	}
+
+	ctx, cancel := context.WithCancel(s.ctx)
+
+	events, err := s.e.Subscribe(ctx, walletID, lastEventID)
+	if err != nil {
+		cancel()
+		return err //nolint:wrapcheck // error is sent to the client
+	}
+
+	s.subs[walletID] = cancel
+	s.send(streamMessage{Type: streamSubscribed, WalletID: walletID})
+
+	s.wg.Add(1)
+
+	go func() {
+		defer s.wg.Done()
+
+		for event := range events {
+			select {
+			case s.out <- streamMessage{Type: streamEvent, WalletID: walletID, Event: event}:
+			case <-ctx.Done():
+				return
+			}
+		}
+
+		// Events end without unsubscribe only if the client fell behind or the server stops
+		if ctx.Err() == nil {
+			s.close(websocket.CloseTryAgainLater, "resubscribe with lastEventId")
+		}
+	}()
+
+	return nil
+}
+
+func (s *streamConn) unsubscribe(walletID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	cancel, ok := s.subs[walletID]
+	if !ok {
+		return errNotSubscribed
+	}
+
+	cancel()
+	delete(s.subs, walletID)
+
+	s.send(streamMessage{Type: streamUnsubscribed, WalletID: walletID})
+
+	return nil
+}
+
+func (s *streamConn) send(msg streamMessage) {
+	select {
+	case s.out <- msg:
+	case <-s.ctx.Done():
+	}
+}
+
+// Closing the connection with the code, the first reason wins.
+func (s *streamConn) close(code int, text string) {
+	s.closeOnce.Do(func() {
+		s.closeCode = code
+		s.closeText = text
+	})
+
+	s.cancel()
+}
+
+func streamErrorMessage(walletID string, p problemType) streamMessage {
+	return streamMessage{
+		Type:     streamError,
+		WalletID: walletID,
+		Problem:  problemTypePrefix + p.name,
+		Title:    p.title,
+	}
+}
+
+func streamProblem(err error) problemType {
+	for _, p := range append(streamProblems, problemTypes...) {
+		if errors.Is(err, p.err) {
+			return p
+		}
+	}
+
+	return problemInternal
+}
@@ -3,27 +3,29 @@ package v1
 import (
 	"WalletRieltaTestTask/internal/entity"
 	"WalletRieltaTestTask/internal/wallet/usecase"
-	"WalletRieltaTestTask/pkg/logger"
-	"errors"
 	"github.com/gin-gonic/gin"
 	"log/slog"
 	"net/http"
 )
 
+const headerIdempotencyKey = "Idempotency-Key"
+
 type walletRoutes struct {
 	w usecase.Wallet
+	e usecase.WalletEvents
 	l *slog.Logger
 }
 
-func newWalletRoutes(handler *gin.RouterGroup, w usecase.Wallet, l *slog.Logger) {
-	r := &walletRoutes{w, l}
+func newWalletRoutes(handler *gin.RouterGroup, w usecase.Wallet, e usecase.WalletEvents, l *slog.Logger) {
+	r := &walletRoutes{w, e, l}
 
 	h := handler.Group("/wallet")
 	{
-		h.POST("", r.createNewWallet)
-		h.POST("/:walletId/send", r.sendFunds)
-		h.GET("/:walletId/history", r.GetWalletHistoryByID)
-		h.GET("/:walletId", r.GetWalletByID)
+		h.POST("", requireScope(entity.ScopeTransfer), r.createNewWallet)
+		h.POST("/:walletId/send", requireScope(entity.ScopeTransfer), r.sendFunds)
+		h.GET("/:walletId/history", requireScope(entity.ScopeRead), r.GetWalletHistoryByID)
+		h.GET("/:walletId/events", requireScope(entity.ScopeRead), r.walletEvents)
+		h.GET("/:walletId", requireScope(entity.ScopeRead), r.GetWalletByID)
 	}
 }
 
@@ -32,21 +34,19 @@ func newWalletRoutes(handler *gin.RouterGroup, w usecase.Wallet, l *slog.Logger)
 // @Description
 // @Description Созданный кошелек должен иметь сумму 100.0 у.е. на балансе
 // @Tags  	    Wallet
+// @Security    ApiKeyAuth
+// @Security    BearerAuth
 // @Success     200 {object} entity.Wallet "Кошелек создан"
-// @Failure     500 "Не удалось создать кошелек"
-// @Failure     504 "Время ожидания вышло"
-// @Router      /wallet [post].
+// @Failure     401 {object} problemDetails "Запрос не аутентифицирован (unauthorized, signature-required, invalid-signature, request-replayed)"
+// @Failure     403 {object} problemDetails "Нет доступа к операции (forbidden)"
+// @Failure     429 {object} problemDetails "Превышен лимит запросов (rate-limited)"
+// @Failure     500 {object} problemDetails "Не удалось создать кошелек (internal)"
+// @Failure     504 {object} problemDetails "Время ожидания вышло (timeout)"
+// @Router      /v1/wallet [post].
 func (r *walletRoutes) createNewWallet(c *gin.Context) {
 	wallet, err := r.w.CreateNewWalletWithDefaultBalance(c.Request.Context())
 	if err != nil {
-		if errors.Is(err, entity.ErrTimeout) {
-			c.AbortWithStatus(http.StatusGatewayTimeout)
-			return
-		}
-
-		r.l.Error("http - v1 - createNewWallet", logger.Err(err))
-		c.AbortWithStatus(http.StatusInternalServerError)
-
+		_ = c.Error(err)
 		return
 	}
 
@@ -61,46 +61,40 @@ type transactionRequest struct {
 
 // @Summary     Перевод средств с одного кошелька на другой
 // @Tags  	    Wallet
+// @Security    ApiKeyAuth
+// @Security    BearerAuth
 // @Param walletId path string true "ID кошелька"
+// @Param Idempotency-Key header string false "Ключ идемпотентности, повторный запрос с ним не переводит средства повторно"
 // @Param input body transactionRequest true "Запрос перевода средств"
 // @Success     200 "Перевод успешно проведен"
-// @Failure     400 "Ошибка в пользовательском запросе"
-// @Failure     404 "Исходящий кошелек не найден"
-// @Failure     500 "Ошибка перевода"
-// @Failure     504 "Время ожидания вышло"
-// @Router      /wallet/{walletId}/send [post].
+// @Failure     400 {object} problemDetails "Ошибка в пользовательском запросе (validation-failed, malformed-request, wrong-amount, empty-wallet, sender-is-receiver, invalid-idempotency-key)"
+// @Failure     401 {object} problemDetails "Запрос не аутентифицирован (unauthorized, signature-required, invalid-signature, request-replayed)"
+// @Failure     403 {object} problemDetails "Нет доступа к операции (forbidden, not-wallet-owner)"
+// @Failure     404 {object} problemDetails "Исходящий кошелек не найден (wallet-not-found)"
+// @Failure     422 {object} problemDetails "Ключ идемпотентности использован другим переводом (idempotency-key-conflict)"
+// @Failure     429 {object} problemDetails "Превышен лимит запросов (rate-limited)"
+// @Failure     500 {object} problemDetails "Ошибка перевода (internal)"
+// @Failure     504 {object} problemDetails "Время ожидания вышло (timeout)"
+// @Router      /v1/wallet/{walletId}/send [post].
 func (r *walletRoutes) sendFunds(c *gin.Context) {
 	var transactionRequest transactionRequest
 
-	if err := c.BindJSON(&transactionRequest); err != nil {
-		c.AbortWithStatus(http.StatusBadRequest)
+	if err := c.ShouldBindJSON(&transactionRequest); err != nil {
+		_ = c.Error(err).SetType(gin.ErrorTypeBind)
 		return
 	}
 
 	walletID := c.Param("walletId")
 
-	err := r.w.SendFunds(c.Request.Context(), walletID, transactionRequest.To, transactionRequest.Amount)
+	err := r.w.SendFunds(
+		c.Request.Context(),
+		walletID,
+		transactionRequest.To,
+		transactionRequest.Amount,
+		c.GetHeader(headerIdempotencyKey),
+	)
 	if err != nil {
-		if errors.Is(err, entity.ErrSenderIsReceiver) ||
-			errors.Is(err, entity.ErrWrongAmount) ||
-			errors.Is(err, entity.ErrEmptyWallet) {
-			c.AbortWithStatus(http.StatusBadRequest)
-			return
-		}
-
-		if errors.Is(err, entity.ErrWalletNotFound) {
-			c.AbortWithStatus(http.StatusNotFound)
-			return
-		}
-
-		if errors.Is(err, entity.ErrTimeout) {
-			c.AbortWithStatus(http.StatusGatewayTimeout)
-			return
-		}
-
-		r.l.Error("http - v1 - sendFunds", logger.Err(err))
-		c.AbortWithStatus(http.StatusInternalServerError)
-
+		_ = c.Error(err)
 		return
 	}
 
@@ -110,28 +104,21 @@ func (r *walletRoutes) sendFunds(c *gin.Context) {
 // @Summary     Получение историй входящих и исходящих транзакций
 // @Description Возвращает историю транзакций по указанному кошельку.
 // @Tags  	    Wallet
+// @Security    ApiKeyAuth
+// @Security    BearerAuth
 // @Param walletId path string true "ID кошелька"
 // @Success     200 {object} []entity.Transaction "История транзакций получена"
-// @Failure     404 "Указанный кошелек не найден"
-// @Failure     500 "Не удалось выполнить запрос"
-// @Failure     504 "Время ожидания вышло"
-// @Router      /wallet/{walletId}/history [get].
+// @Failure     401 {object} problemDetails "Запрос не аутентифицирован (unauthorized, signature-required, invalid-signature, request-replayed)"
+// @Failure     403 {object} problemDetails "Нет доступа к операции (forbidden, not-wallet-owner)"
+// @Failure     404 {object} problemDetails "Указанный кошелек не найден (wallet-not-found)"
+// @Failure     429 {object} problemDetails "Превышен лимит запросов (rate-limited)"
+// @Failure     500 {object} problemDetails "Не удалось выполнить запрос (internal)"
+// @Failure     504 {object} problemDetails "Время ожидания вышло (timeout)"
+// @Router      /v1/wallet/{walletId}/history [get].
 func (r *walletRoutes) GetWalletHistoryByID(c *gin.Context) {
 	transactions, err := r.w.GetWalletHistoryByID(c.Request.Context(), c.Param("walletId"))
 	if err != nil {
-		if errors.Is(err, entity.ErrTimeout) {
-			c.AbortWithStatus(http.StatusGatewayTimeout)
-			return
-		}
-
-		if errors.Is(err, entity.ErrWalletNotFound) {
-			c.AbortWithStatus(http.StatusNotFound)
-			return
-		}
-
-		r.l.Error("http - v1 - GetWalletHistoryByID", logger.Err(err))
-		c.AbortWithStatus(http.StatusInternalServerError)
-
+		_ = c.Error(err)
 		return
 	}
 
@@ -140,28 +127,21 @@ func (r *walletRoutes) GetWalletHistoryByID(c *gin.Context) {
 
 // @Summary     Получение текущего состояния кошелька
 // @Tags  	    Wallet
+// @Security    ApiKeyAuth
+// @Security    BearerAuth
 // @Param walletId path string true "ID кошелька"
 // @Success     200 {object} entity.Wallet "OK"
-// @Failure     404 "Указанный кошелек не найден"
-// @Failure     500 "Не удалось выполнить запрос"
-// @Failure     504 "Время ожидания вышло"
-// @Router      /wallet/{walletId} [get].
+// @Failure     401 {object} problemDetails "Запрос не аутентифицирован (unauthorized, signature-required, invalid-signature, request-replayed)"
+// @Failure     403 {object} problemDetails "Нет доступа к операции (forbidden)"
+// @Failure     404 {object} problemDetails "Указанный кошелек не найден (wallet-not-found)"
+// @Failure     429 {object} problemDetails "Превышен лимит запросов (rate-limited)"
+// @Failure     500 {object} problemDetails "Не удалось выполнить запрос (internal)"
+// @Failure     504 {object} problemDetails "Время ожидания вышло (timeout)"
+// @Router      /v1/wallet/{walletId} [get].
 func (r *walletRoutes) GetWalletByID(c *gin.Context) {
 	wallet, err := r.w.GetWalletByID(c.Request.Context(), c.Param("walletId"))
 	if err != nil {
-		if errors.Is(err, entity.ErrTimeout) {
-			c.AbortWithStatus(http.StatusGatewayTimeout)
-			return
-		}
-
-		if errors.Is(err, entity.ErrWalletNotFound) {
-			c.AbortWithStatus(http.StatusNotFound)
-			return
-		}
-
-		r.l.Error("http - v1 - GetWalletByID", logger.Err(err))
-		c.AbortWithStatus(http.StatusInternalServerError)
-
+		_ = c.Error(err)
 		return
 	}
 
@@ -0,0 +1,176 @@
+package v1
+
+import (
+	"WalletRieltaTestTask/internal/entity"
+	"WalletRieltaTestTask/internal/wallet/usecase"
+	"github.com/gin-gonic/gin"
+	"net/http"
+)
+
+type webhookRoutes struct {
+	wh usecase.Webhooks
+}
+
+func newWebhookRoutes(handler *gin.RouterGroup, wh usecase.Webhooks) {
+	r := &webhookRoutes{wh}
+
+	// Subscriptions send the wallet events to any url, so changing them needs the write scope
+	h := handler.Group("/webhooks")
+	{
+		h.POST("", requireScope(entity.ScopeTransfer), r.createWebhook)
+		h.GET("", requireScope(entity.ScopeRead), r.listWebhooks)
+		h.GET("/:webhookId", requireScope(entity.ScopeRead), r.getWebhook)
+		h.DELETE("/:webhookId", requireScope(entity.ScopeTransfer), r.deleteWebhook)
+		h.GET("/:webhookId/deliveries", requireScope(entity.ScopeRead), r.listDeliveries)
+	}
+}
+
+// @Description Запрос подписки на события кошельков.
+type webhookRequest struct {
+	URL    string   `json:"url"    example:"https://partner.example/hooks/wallet" description:"Адрес получателя POST запросов"                          validate:"required"`                              //nolint:lll,tagalign // вот так то лучше
+	Events []string `json:"events" example:"funds.sent,funds.received"             description:"Типы событий: wallet.created, funds.sent, funds.received, balance.adjusted. Все события если не указаны"` //nolint:lll,tagalign // вот так то лучше
+	Secret string   `json:"secret" example:"whsec_5f2b..."                         description:"Ключ подписи, генерируется сервером если не указан" validate:"omitempty,min=16"`                          //nolint:lll,tagalign // вот так то лучше
+}
+
+// @Summary     Создание вебхука
+// @Description Подписывает адрес на события кошельков. События доставляются POST запросом с JSON телом события.
+// @Description
+// @Description Тело подписывается HMAC-SHA256 с ключом secret от строки "<X-Webhook-Timestamp>.<тело>",
+// @Description подпись передается в заголовке X-Webhook-Signature в виде "v1=<hex>".
+// @Description Ключ возвращается только при создании. Пользователи получают события только своих кошельков.
+// @Tags  	    Webhooks
+// @Security    ApiKeyAuth
+// @Security    BearerAuth
+// @Param input body webhookRequest true "Запрос подписки"
+// @Success     201 {object} entity.Webhook "Вебхук создан"
+// @Failure     400 {object} problemDetails "Ошибка в пользовательском запросе (validation-failed, malformed-request, invalid-webhook-url, unknown-event-type)"
+// @Failure     401 {object} problemDetails "Запрос не аутентифицирован (unauthorized, signature-required, invalid-signature, request-replayed)"
+// @Failure     403 {object} problemDetails "Нет доступа к операции (forbidden)"
+// @Failure     429 {object} problemDetails "Превышен лимит запросов (rate-limited)"
+// @Failure     500 {object} problemDetails "Не удалось выполнить запрос (internal)"
+// @Failure     504 {object} problemDetails "Время ожидания вышло (timeout)"
+// @Router      /v1/webhooks [post].
+func (r *webhookRoutes) createWebhook(c *gin.Context) {
+	var request webhookRequest
+
+	if err := c.ShouldBindJSON(&request); err != nil {
+		_ = c.Error(err).SetType(gin.ErrorTypeBind)
+		return
+	}
+
+	webhook, err := r.wh.CreateWebhook(c.Request.Context(), request.URL, request.Events, request.Secret)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, webhook)
+}
+
+// @Summary     Список вебхуков
+// @Tags  	    Webhooks
+// @Security    ApiKeyAuth
+// @Security    BearerAuth
+// @Success     200 {object} []entity.Webhook "Вебхуки вызывающего"
+// @Failure     401 {object} problemDetails "Запрос не аутентифицирован (unauthorized, signature-required, invalid-signature, request-replayed)"
+// @Failure     403 {object} problemDetails "Нет доступа к операции (forbidden)"
+// @Failure     429 {object} problemDetails "Превышен лимит запросов (rate-limited)"
+// @Failure     500 {object} problemDetails "Не удалось выполнить запрос (internal)"
+// @Failure     504 {object} problemDetails "Время ожидания вышло (timeout)"
+// @Router      /v1/webhooks [get].
+func (r *webhookRoutes) listWebhooks(c *gin.Context) {
+	webhooks, err := r.wh.ListWebhooks(c.Request.Context())
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, webhooks)
+}
+
+// @Summary     Получение вебхука
+// @Tags  	    Webhooks
+// @Security    ApiKeyAuth
+// @Security    BearerAuth
+// @Param webhookId path string true "ID вебхука"
+// @Success     200 {object} entity.Webhook "Вебхук"
+// @Failure     401 {object} problemDetails "Запрос не аутентифицирован (unauthorized, signature-required, invalid-signature, request-replayed)"
+// @Failure     403 {object} problemDetails "Нет доступа к операции (forbidden)"
+// @Failure     404 {object} problemDetails "Вебхук не найден (webhook-not-found)"
+// @Failure     429 {object} problemDetails "Превышен лимит запросов (rate-limited)"
+// @Failure     500 {object} problemDetails "Не удалось выполнить запрос (internal)"
+// @Failure     504 {object} problemDetails "Время ожидания вышло (timeout)"
+// @Router      /v1/webhooks/{webhookId} [get].
+func (r *webhookRoutes) getWebhook(c *gin.Context) {
+	webhook, err := r.wh.GetWebhook(c.Request.Context(), c.Param("webhookId"))
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, webhook)
+}
+
+// @Summary     Удаление вебхука
+// @Description Недоставленные события вебхука отменяются.
+// @Tags  	    Webhooks
+// @Security    ApiKeyAuth
+// @Security    BearerAuth
+// @Param webhookId path string true "ID вебхука"
+// @Success     204 "Вебхук удален"
+// @Failure     401 {object} problemDetails "Запрос не аутентифицирован (unauthorized, signature-required, invalid-signature, request-replayed)"
+// @Failure     403 {object} problemDetails "Нет доступа к операции (forbidden)"
+// @Failure     404 {object} problemDetails "Вебхук не найден (webhook-not-found)"
+// @Failure     429 {object} problemDetails "Превышен лимит запросов (rate-limited)"
+// @Failure     500 {object} problemDetails "Не удалось выполнить запрос (internal)"
+// @Failure     504 {object} problemDetails "Время ожидания вышло (timeout)"
+// @Router      /v1/webhooks/{webhookId} [delete].
+func (r *webhookRoutes) deleteWebhook(c *gin.Context) {
+	if err := r.wh.DeleteWebhook(c.Request.Context(), c.Param("webhookId")); err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// @Description Параметры журнала доставок.
+type deliveriesQuery struct {
+	Limit  uint `form:"limit"  json:"limit"  validate:"max=500"`
+	Offset uint `form:"offset" json:"offset"`
+}
+
+// @Summary     Журнал доставок вебхука
+// @Description Доставки в статусе pending повторяются с экспоненциальной задержкой,
+// @Description после исчерпания попыток доставка переходит в статус dead.
+// @Tags  	    Webhooks
+// @Security    ApiKeyAuth
+// @Security    BearerAuth
+// @Param webhookId path  string true  "ID вебхука"
+// @Param limit     query int    false "Количество доставок, до 500" default(50)
+// @Param offset    query int    false "Смещение"
+// @Success     200 {object} []entity.WebhookDelivery "Доставки, начиная с последней"
+// @Failure     400 {object} problemDetails "Ошибка в пользовательском запросе (validation-failed)"
+// @Failure     401 {object} problemDetails "Запрос не аутентифицирован (unauthorized, signature-required, invalid-signature, request-replayed)"
+// @Failure     403 {object} problemDetails "Нет доступа к операции (forbidden)"
+// @Failure     404 {object} problemDetails "Вебхук не найден (webhook-not-found)"
+// @Failure     429 {object} problemDetails "Превышен лимит запросов (rate-limited)"
+// @Failure     500 {object} problemDetails "Не удалось выполнить запрос (internal)"
+// @Failure     504 {object} problemDetails "Время ожидания вышло (timeout)"
+// @Router      /v1/webhooks/{webhookId}/deliveries [get].
+func (r *webhookRoutes) listDeliveries(c *gin.Context) {
+	var query deliveriesQuery
+
+	if err := c.ShouldBindQuery(&query); err != nil {
+		_ = c.Error(err).SetType(gin.ErrorTypeBind)
+		return
+	}
+
+	deliveries, err := r.wh.ListDeliveries(c.Request.Context(), c.Param("webhookId"), query.Limit, query.Offset)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, deliveries)
+}
@@ -0,0 +1,92 @@
+package gateway
+
+import (
+	"WalletRieltaTestTask/internal/entity"
+	"context"
+	"fmt"
+)
+
+type AdminWorker interface {
+	SearchWallets(ctx context.Context, filter entity.WalletFilter) ([]entity.Wallet, error)
+	AdjustBalance(ctx context.Context, walletID string, amount int64, action entity.AdminAction) (*entity.Wallet, error)
+	SetWalletFrozen(ctx context.Context, walletID string, frozen bool, action entity.AdminAction) (*entity.Wallet, error)
+	ReverseTransaction(ctx context.Context, transactionID int64, action entity.AdminAction) (*entity.Transaction, error)
+}
+
+type AuditWorker interface {
+	SearchAudit(ctx context.Context, filter entity.AuditFilter) ([]entity.AuditEntry, error)
+}
+
+type AdminGateway struct {
+	a     AdminWorker
+	audit AuditWorker
+}
+
+// Init of admin gateway, through we will calling the worker use cases in the same process.
+func NewAdmin(a AdminWorker, audit AuditWorker) *AdminGateway {
+	return &AdminGateway{a, audit}
+}
+
+// Searching wallets, through direct call to the worker.
+func (gw *AdminGateway) SearchWallets(ctx context.Context, filter entity.WalletFilter) ([]entity.Wallet, error) {
+	wallets, err := gw.a.SearchWallets(ctx, filter)
+	if err != nil {
+		return nil, fmt.Errorf("AdminGateway - SearchWallets - gw.a.SearchWallets: %w", err)
+	}
+
+	return wallets, nil
+}
+
+// Searching audit log entries, through direct call to the worker.
+func (gw *AdminGateway) SearchAudit(ctx context.Context, filter entity.AuditFilter) ([]entity.AuditEntry, error) {
+	entries, err := gw.audit.SearchAudit(ctx, filter)
+	if err != nil {
+		return nil, fmt.Errorf("AdminGateway - SearchAudit - gw.audit.SearchAudit: %w", err)
+	}
+
+	return entries, nil
+}
+
+// Adjusting wallet balance, through direct call to the worker.
+func (gw *AdminGateway) AdjustBalance(
+	ctx context.Context,
+	walletID string,
+	amount int64,
+	action entity.AdminAction,
+) (*entity.Wallet, error) {
+	wallet, err := gw.a.AdjustBalance(ctx, walletID, amount, action)
+	if err != nil {
+		return nil, fmt.Errorf("AdminGateway - AdjustBalance - gw.a.AdjustBalance: %w", err)
+	}
+
+	return wallet, nil
+}
+
+// Freezing or unfreezing wallet, through direct call to the worker.
+func (gw *AdminGateway) SetWalletFrozen(
+	ctx context.Context,
+	walletID string,
+	frozen bool,
+	action entity.AdminAction,
+) (*entity.Wallet, error) {
+	wallet, err := gw.a.SetWalletFrozen(ctx, walletID, frozen, action)
+	if err != nil {
+		return nil, fmt.Errorf("AdminGateway - SetWalletFrozen - gw.a.SetWalletFrozen: %w", err)
+	}
+
+	return wallet, nil
+}
+
+// Reversing transaction, through direct call to the worker.
+func (gw *AdminGateway) ReverseTransaction(
+	ctx context.Context,
+	transactionID int64,
+	action entity.AdminAction,
+) (*entity.Transaction, error) {
+	transaction, err := gw.a.ReverseTransaction(ctx, transactionID, action)
+	if err != nil {
+		return nil, fmt.Errorf("AdminGateway - ReverseTransaction - gw.a.ReverseTransaction: %w", err)
+	}
+
+	return transaction, nil
+}
@@ -0,0 +1,47 @@
+package gateway
+
+import (
+	"WalletRieltaTestTask/internal/entity"
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+type APIKeyWorker interface {
+	GetAPIKeyByID(ctx context.Context, id string) (*entity.APIKey, error)
+	RegisterNonce(ctx context.Context, keyID, nonce string, expiresAt time.Time) (bool, error)
+}
+
+type AuthGateway struct {
+	k APIKeyWorker
+}
+
+// Init of auth gateway, api keys are read by the worker use case in the same process.
+func NewAuth(k APIKeyWorker) *AuthGateway {
+	return &AuthGateway{k}
+}
+
+// Getting api key by ID, through direct call to the worker.
+func (gw *AuthGateway) GetAPIKeyByID(ctx context.Context, id string) (*entity.APIKey, error) {
+	key, err := gw.k.GetAPIKeyByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, entity.ErrNotFound) {
+			return nil, entity.ErrUnauthorized
+		}
+
+		return nil, fmt.Errorf("AuthGateway - GetAPIKeyByID - gw.k.GetAPIKeyByID: %w", err)
+	}
+
+	return key, nil
+}
+
+// Registering nonce of a signed request, through direct call to the worker.
+func (gw *AuthGateway) RegisterNonce(ctx context.Context, keyID, nonce string, expiresAt time.Time) (bool, error) {
+	registered, err := gw.k.RegisterNonce(ctx, keyID, nonce, expiresAt)
+	if err != nil {
+		return false, fmt.Errorf("AuthGateway - RegisterNonce - gw.k.RegisterNonce: %w", err)
+	}
+
+	return registered, nil
+}
@@ -0,0 +1,89 @@
+package gateway
+
+import (
+	"WalletRieltaTestTask/internal/entity"
+	"context"
+	"fmt"
+)
+
+type WalletWorker interface {
+	CreateNewWalletWithBalance(
+		ctx context.Context,
+		balance uint,
+		ownerID string,
+		caller entity.Caller,
+	) (*entity.Wallet, error)
+	SendFunds(ctx context.Context, from string, to string, amount uint, idempotencyKey string, caller entity.Caller) error
+	GetWalletHistoryByID(ctx context.Context, walletID string) ([]entity.Transaction, error)
+	GetWalletByID(ctx context.Context, walletID string) (*entity.Wallet, error)
+	ListWalletEvents(ctx context.Context, walletID, after string, limit uint) ([]entity.Event, error)
+}
+
+type WalletGateway struct {
+	w WalletWorker
+}
+
+// Init of wallet gateway, through we will calling the worker use case in the same process.
+func New(w WalletWorker) *WalletGateway {
+	return &WalletGateway{w}
+}
+
+// Creating new wallet with balance, through direct call to the worker.
+func (gw *WalletGateway) CreateNewWalletWithBalance(
+	ctx context.Context,
+	balance uint,
+	ownerID string,
+	caller entity.Caller,
+) (*entity.Wallet, error) {
+	wallet, err := gw.w.CreateNewWalletWithBalance(ctx, balance, ownerID, caller)
+	if err != nil {
+		return nil, fmt.Errorf("WalletGateway - CreateNewWalletWithBalance - gw.w.CreateNewWalletWithBalance: %w", err)
+	}
+
+	return wallet, nil
+}
+
+// Sending funds, through direct call to the worker.
+func (gw *WalletGateway) SendFunds(ctx context.Context, request entity.SendFundsRequest) error {
+	err := gw.w.SendFunds(ctx, request.From, request.To, request.Amount, request.IdempotencyKey, request.Caller)
+	if err != nil {
+		return fmt.Errorf("WalletGateway - SendFunds - gw.w.SendFunds: %w", err)
+	}
+
+	return nil
+}
+
+// Getting transactions history by wallet ID, through direct call to the worker.
+func (gw *WalletGateway) GetWalletHistoryByID(ctx context.Context, walletID string) ([]entity.Transaction, error) {
+	transactions, err := gw.w.GetWalletHistoryByID(ctx, walletID)
+	if err != nil {
+		return nil, fmt.Errorf("WalletGateway - GetWalletHistoryByID - gw.w.GetWalletHistoryByID: %w", err)
+	}
+
+	return transactions, nil
+}
+
+// Getting wallet info by ID, through direct call to the worker.
+func (gw *WalletGateway) GetWalletByID(ctx context.Context, walletID string) (*entity.Wallet, error) {
+	wallet, err := gw.w.GetWalletByID(ctx, walletID)
+	if err != nil {
+		return nil, fmt.Errorf("WalletGateway - GetWalletByID - gw.w.GetWalletByID: %w", err)
+	}
+
+	return wallet, nil
+}
+
+// Getting events of the wallet added after the event, through direct call to the worker.
+func (gw *WalletGateway) ListWalletEvents(
+	ctx context.Context,
+	walletID string,
+	after string,
+	limit uint,
+) ([]entity.Event, error) {
+	events, err := gw.w.ListWalletEvents(ctx, walletID, after, limit)
+	if err != nil {
+		return nil, fmt.Errorf("WalletGateway - ListWalletEvents - gw.w.ListWalletEvents: %w", err)
+	}
+
+	return events, nil
+}
@@ -0,0 +1,86 @@
+package gateway
+
+import (
+	"WalletRieltaTestTask/internal/entity"
+	"context"
+	"fmt"
+)
+
+type WebhookWorker interface {
+	CreateWebhook(ctx context.Context, webhook *entity.Webhook) (*entity.Webhook, error)
+	ListWebhooks(ctx context.Context, createdBy string) ([]entity.Webhook, error)
+	GetWebhook(ctx context.Context, id, createdBy string) (*entity.Webhook, error)
+	DeleteWebhook(ctx context.Context, id, createdBy string) error
+	ListDeliveries(
+		ctx context.Context,
+		webhookID string,
+		createdBy string,
+		limit uint,
+		offset uint,
+	) ([]entity.WebhookDelivery, error)
+}
+
+type WebhookGateway struct {
+	w WebhookWorker
+}
+
+// Init of webhook gateway, through we will calling the worker use case in the same process.
+func NewWebhooks(w WebhookWorker) *WebhookGateway {
+	return &WebhookGateway{w}
+}
+
+// Creating webhook subscription, through direct call to the worker.
+func (gw *WebhookGateway) CreateWebhook(ctx context.Context, webhook *entity.Webhook) (*entity.Webhook, error) {
+	created, err := gw.w.CreateWebhook(ctx, webhook)
+	if err != nil {
+		return nil, fmt.Errorf("WebhookGateway - CreateWebhook - gw.w.CreateWebhook: %w", err)
+	}
+
+	return created, nil
+}
+
+// Getting webhooks of the actor, through direct call to the worker.
+func (gw *WebhookGateway) ListWebhooks(ctx context.Context, createdBy string) ([]entity.Webhook, error) {
+	webhooks, err := gw.w.ListWebhooks(ctx, createdBy)
+	if err != nil {
+		return nil, fmt.Errorf("WebhookGateway - ListWebhooks - gw.w.ListWebhooks: %w", err)
+	}
+
+	return webhooks, nil
+}
+
+// Getting webhook of the actor, through direct call to the worker.
+func (gw *WebhookGateway) GetWebhook(ctx context.Context, id, createdBy string) (*entity.Webhook, error) {
+	webhook, err := gw.w.GetWebhook(ctx, id, createdBy)
+	if err != nil {
+		return nil, fmt.Errorf("WebhookGateway - GetWebhook - gw.w.GetWebhook: %w", err)
+	}
+
+	return webhook, nil
+}
+
+// Deleting webhook of the actor, through direct call to the worker.
+func (gw *WebhookGateway) DeleteWebhook(ctx context.Context, id, createdBy string) error {
+	err := gw.w.DeleteWebhook(ctx, id, createdBy)
+	if err != nil {
+		return fmt.Errorf("WebhookGateway - DeleteWebhook - gw.w.DeleteWebhook: %w", err)
+	}
+
+	return nil
+}
+
+// Getting deliveries of the webhook, through direct call to the worker.
+func (gw *WebhookGateway) ListDeliveries(
+	ctx context.Context,
+	webhookID string,
+	createdBy string,
+	limit uint,
+	offset uint,
+) ([]entity.WebhookDelivery, error) {
+	deliveries, err := gw.w.ListDeliveries(ctx, webhookID, createdBy, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("WebhookGateway - ListDeliveries - gw.w.ListDeliveries: %w", err)
+	}
+
+	return deliveries, nil
+}
@@ -0,0 +1,130 @@
+package gateway
+
+import (
+	"WalletRieltaTestTask/internal/entity"
+	"context"
+	"fmt"
+)
+
+type AdminGateway struct {
+	rmq WalletGatewayRMQ
+}
+
+// Init of admin gateway, through we will making requests to rmq server.
+func NewAdmin(rmq WalletGatewayRMQ) *AdminGateway {
+	return &AdminGateway{rmq}
+}
+
+// Searching wallets, through remote call to rmq server.
+func (gw *AdminGateway) SearchWallets(ctx context.Context, filter entity.WalletFilter) ([]entity.Wallet, error) {
+	var wallets []entity.Wallet
+
+	request := entity.SearchWalletsRequest{
+		Filter: filter,
+	}
+
+	err := wrapper(ctx, func() error {
+		return gw.rmq.RemoteCall(ctx, "searchWallets", request, &wallets)
+	})
+
+	if err != nil {
+		return nil, fmt.Errorf("AdminGateway - SearchWallets - gw.rmq.RemoteCall: %w", err)
+	}
+
+	return wallets, nil
+}
+
+// Searching audit log entries, through remote call to rmq server.
+func (gw *AdminGateway) SearchAudit(ctx context.Context, filter entity.AuditFilter) ([]entity.AuditEntry, error) {
+	var entries []entity.AuditEntry
+
+	request := entity.SearchAuditRequest{
+		Filter: filter,
+	}
+
+	err := wrapper(ctx, func() error {
+		return gw.rmq.RemoteCall(ctx, "searchAudit", request, &entries)
+	})
+
+	if err != nil {
+		return nil, fmt.Errorf("AdminGateway - SearchAudit - gw.rmq.RemoteCall: %w", err)
+	}
+
+	return entries, nil
+}
+
+// Adjusting wallet balance, through remote call to rmq server.
+func (gw *AdminGateway) AdjustBalance(
+	ctx context.Context,
+	walletID string,
+	amount int64,
+	action entity.AdminAction,
+) (*entity.Wallet, error) {
+	var wallet entity.Wallet
+
+	request := entity.AdjustBalanceRequest{
+		WalletID: walletID,
+		Amount:   amount,
+		Action:   action,
+	}
+
+	err := wrapper(ctx, func() error {
+		return gw.rmq.RemoteCall(ctx, "adjustBalance", request, &wallet)
+	})
+
+	if err != nil {
+		return nil, fmt.Errorf("AdminGateway - AdjustBalance - gw.rmq.RemoteCall: %w", err)
+	}
+
+	return &wallet, nil
+}
+
+// Freezing or unfreezing wallet, through remote call to rmq server.
+func (gw *AdminGateway) SetWalletFrozen(
+	ctx context.Context,
+	walletID string,
+	frozen bool,
+	action entity.AdminAction,
+) (*entity.Wallet, error) {
+	var wallet entity.Wallet
+
+	request := entity.SetWalletFrozenRequest{
+		WalletID: walletID,
+		Frozen:   frozen,
+		Action:   action,
+	}
+
+	err := wrapper(ctx, func() error {
+		return gw.rmq.RemoteCall(ctx, "setWalletFrozen", request, &wallet)
+	})
+
+	if err != nil {
+		return nil, fmt.Errorf("AdminGateway - SetWalletFrozen - gw.rmq.RemoteCall: %w", err)
+	}
+
+	return &wallet, nil
+}
+
+// Reversing transaction, through remote call to rmq server.
+func (gw *AdminGateway) ReverseTransaction(
+	ctx context.Context,
+	transactionID int64,
+	action entity.AdminAction,
+) (*entity.Transaction, error) {
+	var transaction entity.Transaction
+
+	request := entity.ReverseTransactionRequest{
+		TransactionID: transactionID,
+		Action:        action,
+	}
+
+	err := wrapper(ctx, func() error {
+		return gw.rmq.RemoteCall(ctx, "reverseTransaction", request, &transaction)
+	})
+
+	if err != nil {
+		return nil, fmt.Errorf("AdminGateway - ReverseTransaction - gw.rmq.RemoteCall: %w", err)
+	}
+
+	return &transaction, nil
+}
@@ -0,0 +1,62 @@
+package gateway
+
+import (
+	"WalletRieltaTestTask/internal/entity"
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+type AuthGateway struct {
+	rmq WalletGatewayRMQ
+}
+
+// Init of auth gateway, api keys are stored on the rmq server side.
+func NewAuth(rmq WalletGatewayRMQ) *AuthGateway {
+	return &AuthGateway{rmq}
+}
+
+// Getting api key by ID, through remote call to rmq server.
+func (gw *AuthGateway) GetAPIKeyByID(ctx context.Context, id string) (*entity.APIKey, error) {
+	var key entity.APIKey
+
+	request := entity.GetAPIKeyByIDRequest{
+		ID: id,
+	}
+
+	err := wrapper(ctx, func() error {
+		return gw.rmq.RemoteCall(ctx, "getAPIKeyByID", request, &key)
+	})
+
+	if err != nil {
+		if errors.Is(err, entity.ErrNotFound) {
+			return nil, entity.ErrUnauthorized
+		}
+
+		return nil, fmt.Errorf("AuthGateway - GetAPIKeyByID - gw.rmq.RemoteCall: %w", err)
+	}
+
+	return &key, nil
+}
+
+// Registering nonce of a signed request, through remote call to rmq server.
+func (gw *AuthGateway) RegisterNonce(ctx context.Context, keyID, nonce string, expiresAt time.Time) (bool, error) {
+	var response entity.RegisterNonceResponse
+
+	request := entity.RegisterNonceRequest{
+		KeyID:     keyID,
+		Nonce:     nonce,
+		ExpiresAt: expiresAt,
+	}
+
+	err := wrapper(ctx, func() error {
+		return gw.rmq.RemoteCall(ctx, "registerNonce", request, &response)
+	})
+
+	if err != nil {
+		return false, fmt.Errorf("AuthGateway - RegisterNonce - gw.rmq.RemoteCall: %w", err)
+	}
+
+	return response.Registered, nil
+}
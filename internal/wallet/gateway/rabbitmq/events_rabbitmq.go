@@ -0,0 +1,28 @@
+package gateway
+
+import (
+	"WalletRieltaTestTask/internal/entity"
+	"WalletRieltaTestTask/pkg/logger"
+	"WalletRieltaTestTask/pkg/rabbitmq/subscriber"
+	"encoding/json"
+	amqp "github.com/rabbitmq/amqp091-go"
+	"log/slog"
+)
+
+// EventHandler - decoding wallet events of the events exchange and passing them to notify.
+func EventHandler(notify func(event *entity.Event), l *slog.Logger) subscriber.Handler {
+	return func(d amqp.Delivery) {
+		var event entity.Event
+
+		if err := json.Unmarshal(d.Body, &event); err != nil {
+			l.Error("gateway - EventHandler - json.Unmarshal",
+				logger.Err(err),
+				slog.String("messageId", d.MessageId),
+			)
+
+			return
+		}
+
+		notify(&event)
+	}
+}
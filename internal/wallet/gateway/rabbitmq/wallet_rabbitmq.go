@@ -3,7 +3,6 @@ package gateway
 import (
 	"WalletRieltaTestTask/internal/entity"
 	"context"
-	"errors"
 	"fmt"
 )
 
@@ -21,11 +20,18 @@ func New(rmq WalletGatewayRMQ) *WalletGateway {
 }
 
 // Creating new wallet with balance, through remote call to rmq server.
-func (gw *WalletGateway) CreateNewWalletWithBalance(ctx context.Context, balance uint) (*entity.Wallet, error) {
+func (gw *WalletGateway) CreateNewWalletWithBalance(
+	ctx context.Context,
+	balance uint,
+	ownerID string,
+	caller entity.Caller,
+) (*entity.Wallet, error) {
 	var wallet entity.Wallet
 
 	request := entity.CreateNewWalletWithBalanceRequest{
 		Balance: balance,
+		OwnerID: ownerID,
+		Caller:  caller,
 	}
 
 	err := wrapper(ctx, func() error {
@@ -40,22 +46,12 @@ func (gw *WalletGateway) CreateNewWalletWithBalance(ctx context.Context, balance
 }
 
 // Sending funds, through remote call to rmq server.
-func (gw *WalletGateway) SendFunds(ctx context.Context, from string, to string, amount uint) error {
-	request := entity.SendFundsRequest{
-		From:   from,
-		To:     to,
-		Amount: amount,
-	}
-
+func (gw *WalletGateway) SendFunds(ctx context.Context, request entity.SendFundsRequest) error {
 	err := wrapper(ctx, func() error {
 		return gw.rmq.RemoteCall(ctx, "sendFunds", request, nil)
 	})
 
 	if err != nil {
-		if errors.Is(err, entity.ErrNotFound) {
-			return entity.ErrWalletNotFound
-		}
-
 		return fmt.Errorf("WalletGateway - SendFunds - gw.rmq.RemoteCall: %w", err)
 	}
 
@@ -75,10 +71,6 @@ func (gw *WalletGateway) GetWalletHistoryByID(ctx context.Context, walletID stri
 	})
 
 	if err != nil {
-		if errors.Is(err, entity.ErrNotFound) {
-			return nil, entity.ErrWalletNotFound
-		}
-
 		return nil, fmt.Errorf("WalletGateway - GetWalletHistoryByID - gw.rmq.RemoteCall: %w", err)
 	}
 
@@ -98,10 +90,6 @@ func (gw *WalletGateway) GetWalletByID(ctx context.Context, walletID string) (*e
 	})
 
 	if err != nil {
-		if errors.Is(err, entity.ErrNotFound) {
-			return nil, entity.ErrWalletNotFound
-		}
-
 		return nil, fmt.Errorf("WalletGateway - GetWalletByID - gw.rmq.RemoteCall: %w", err)
 	}
 
@@ -124,3 +112,29 @@ func wrapper(ctx context.Context, f func() error) error {
 		return err
 	}
 }
+
+// Getting events of the wallet added after the event, through remote call to rmq server.
+func (gw *WalletGateway) ListWalletEvents(
+	ctx context.Context,
+	walletID string,
+	after string,
+	limit uint,
+) ([]entity.Event, error) {
+	var events []entity.Event
+
+	request := entity.ListWalletEventsRequest{
+		WalletID: walletID,
+		After:    after,
+		Limit:    limit,
+	}
+
+	err := wrapper(ctx, func() error {
+		return gw.rmq.RemoteCall(ctx, "listWalletEvents", request, &events)
+	})
+
+	if err != nil {
+		return nil, fmt.Errorf("WalletGateway - ListWalletEvents - gw.rmq.RemoteCall: %w", err)
+	}
+
+	return events, nil
+}
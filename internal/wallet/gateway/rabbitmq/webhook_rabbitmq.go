@@ -0,0 +1,120 @@
+package gateway
+
+import (
+	"WalletRieltaTestTask/internal/entity"
+	"context"
+	"fmt"
+)
+
+type WebhookGateway struct {
+	rmq WalletGatewayRMQ
+}
+
+// Init of webhook gateway, through we will making requests to rmq server.
+func NewWebhooks(rmq WalletGatewayRMQ) *WebhookGateway {
+	return &WebhookGateway{rmq}
+}
+
+// Creating webhook subscription, through remote call to rmq server.
+func (gw *WebhookGateway) CreateWebhook(ctx context.Context, webhook *entity.Webhook) (*entity.Webhook, error) {
+	var created entity.Webhook
+
+	request := entity.CreateWebhookRequest{
+		Webhook: *webhook,
+	}
+
+	err := wrapper(ctx, func() error {
+		return gw.rmq.RemoteCall(ctx, "createWebhook", request, &created)
+	})
+
+	if err != nil {
+		return nil, fmt.Errorf("WebhookGateway - CreateWebhook - gw.rmq.RemoteCall: %w", err)
+	}
+
+	return &created, nil
+}
+
+// Getting webhooks of the actor, through remote call to rmq server.
+func (gw *WebhookGateway) ListWebhooks(ctx context.Context, createdBy string) ([]entity.Webhook, error) {
+	var webhooks []entity.Webhook
+
+	request := entity.ListWebhooksRequest{
+		CreatedBy: createdBy,
+	}
+
+	err := wrapper(ctx, func() error {
+		return gw.rmq.RemoteCall(ctx, "listWebhooks", request, &webhooks)
+	})
+
+	if err != nil {
+		return nil, fmt.Errorf("WebhookGateway - ListWebhooks - gw.rmq.RemoteCall: %w", err)
+	}
+
+	return webhooks, nil
+}
+
+// Getting webhook of the actor, through remote call to rmq server.
+func (gw *WebhookGateway) GetWebhook(ctx context.Context, id, createdBy string) (*entity.Webhook, error) {
+	var webhook entity.Webhook
+
+	request := entity.WebhookByIDRequest{
+		ID:        id,
+		CreatedBy: createdBy,
+	}
+
+	err := wrapper(ctx, func() error {
+		return gw.rmq.RemoteCall(ctx, "getWebhook", request, &webhook)
+	})
+
+	if err != nil {
+		return nil, fmt.Errorf("WebhookGateway - GetWebhook - gw.rmq.RemoteCall: %w", err)
+	}
+
+	return &webhook, nil
+}
+
+// Deleting webhook of the actor, through remote call to rmq server.
+func (gw *WebhookGateway) DeleteWebhook(ctx context.Context, id, createdBy string) error {
+	request := entity.WebhookByIDRequest{
+		ID:        id,
+		CreatedBy: createdBy,
+	}
+
+	err := wrapper(ctx, func() error {
+		return gw.rmq.RemoteCall(ctx, "deleteWebhook", request, nil)
+	})
+
+	if err != nil {
+		return fmt.Errorf("WebhookGateway - DeleteWebhook - gw.rmq.RemoteCall: %w", err)
+	}
+
+	return nil
+}
+
+// Getting delivery log of the webhook, through remote call to rmq server.
+func (gw *WebhookGateway) ListDeliveries(
+	ctx context.Context,
+	webhookID string,
+	createdBy string,
+	limit uint,
+	offset uint,
+) ([]entity.WebhookDelivery, error) {
+	var deliveries []entity.WebhookDelivery
+
+	request := entity.ListWebhookDeliveriesRequest{
+		WebhookID: webhookID,
+		CreatedBy: createdBy,
+		Limit:     limit,
+		Offset:    offset,
+	}
+
+	err := wrapper(ctx, func() error {
+		return gw.rmq.RemoteCall(ctx, "listWebhookDeliveries", request, &deliveries)
+	})
+
+	if err != nil {
+		return nil, fmt.Errorf("WebhookGateway - ListDeliveries - gw.rmq.RemoteCall: %w", err)
+	}
+
+	return deliveries, nil
+}
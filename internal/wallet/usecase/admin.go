@@ -0,0 +1,142 @@
+package usecase
+
+import (
+	"WalletRieltaTestTask/internal/entity"
+	"context"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+)
+
+// AdminUseCase - manual operations of the operators.
+type AdminUseCase struct {
+	gateway AdminGateway
+	timeout time.Duration
+}
+
+func NewAdmin(gw AdminGateway) *AdminUseCase {
+	return &AdminUseCase{
+		gateway: gw,
+		timeout: _defaultTimeout,
+	}
+}
+
+func (uc *AdminUseCase) SearchWallets(ctx context.Context, filter entity.WalletFilter) ([]entity.Wallet, error) {
+	ctxTimeout, cancel := context.WithTimeout(ctx, uc.timeout)
+	defer cancel()
+
+	wallets, err := uc.gateway.SearchWallets(ctxTimeout, filter)
+	if err != nil {
+		return nil, fmt.Errorf("AdminUseCase - SearchWallets - uc.gateway.SearchWallets: %w", err)
+	}
+
+	return wallets, nil
+}
+
+func (uc *AdminUseCase) SearchAudit(ctx context.Context, filter entity.AuditFilter) ([]entity.AuditEntry, error) {
+	ctxTimeout, cancel := context.WithTimeout(ctx, uc.timeout)
+	defer cancel()
+
+	entries, err := uc.gateway.SearchAudit(ctxTimeout, filter)
+	if err != nil {
+		return nil, fmt.Errorf("AdminUseCase - SearchAudit - uc.gateway.SearchAudit: %w", err)
+	}
+
+	return entries, nil
+}
+
+func (uc *AdminUseCase) AdjustBalance(
+	ctx context.Context,
+	walletID string,
+	amount int64,
+	reason string,
+) (*entity.Wallet, error) {
+	ctxTimeout, cancel := context.WithTimeout(ctx, uc.timeout)
+	defer cancel()
+
+	// The debit of math.MinInt64 doesn't fit int64 once negated, so it can't be checked against the balance.
+	if amount == 0 || amount == math.MinInt64 {
+		return nil, entity.ErrWrongAmount
+	}
+
+	action, err := adminAction(ctx, reason)
+	if err != nil {
+		return nil, err
+	}
+
+	wallet, err := uc.gateway.AdjustBalance(ctxTimeout, walletID, amount, action)
+	if err != nil {
+		return nil, fmt.Errorf("AdminUseCase - AdjustBalance - uc.gateway.AdjustBalance: %w", err)
+	}
+
+	return wallet, nil
+}
+
+func (uc *AdminUseCase) FreezeWallet(ctx context.Context, walletID string, reason string) (*entity.Wallet, error) {
+	return uc.setWalletFrozen(ctx, walletID, true, reason)
+}
+
+func (uc *AdminUseCase) UnfreezeWallet(ctx context.Context, walletID string, reason string) (*entity.Wallet, error) {
+	return uc.setWalletFrozen(ctx, walletID, false, reason)
+}
+
+func (uc *AdminUseCase) setWalletFrozen(
+	ctx context.Context,
+	walletID string,
+	frozen bool,
+	reason string,
+) (*entity.Wallet, error) {
+	ctxTimeout, cancel := context.WithTimeout(ctx, uc.timeout)
+	defer cancel()
+
+	action, err := adminAction(ctx, reason)
+	if err != nil {
+		return nil, err
+	}
+
+	wallet, err := uc.gateway.SetWalletFrozen(ctxTimeout, walletID, frozen, action)
+	if err != nil {
+		return nil, fmt.Errorf("AdminUseCase - setWalletFrozen - uc.gateway.SetWalletFrozen: %w", err)
+	}
+
+	return wallet, nil
+}
+
+func (uc *AdminUseCase) ReverseTransaction(
+	ctx context.Context,
+	transactionID int64,
+	reason string,
+) (*entity.Transaction, error) {
+	ctxTimeout, cancel := context.WithTimeout(ctx, uc.timeout)
+	defer cancel()
+
+	action, err := adminAction(ctx, reason)
+	if err != nil {
+		return nil, err
+	}
+
+	transaction, err := uc.gateway.ReverseTransaction(ctxTimeout, transactionID, action)
+	if err != nil {
+		return nil, fmt.Errorf("AdminUseCase - ReverseTransaction - uc.gateway.ReverseTransaction: %w", err)
+	}
+
+	return transaction, nil
+}
+
+// Every manual operation is recorded with the caller and the reason.
+func adminAction(ctx context.Context, reason string) (entity.AdminAction, error) {
+	if _, ok := entity.PrincipalFromContext(ctx); !ok {
+		return entity.AdminAction{}, entity.ErrUnauthorized
+	}
+
+	reason = strings.TrimSpace(reason)
+	if reason == "" {
+		return entity.AdminAction{}, entity.ErrReasonRequired
+	}
+
+	return entity.AdminAction{
+		Caller: entity.CallerFromContext(ctx),
+		Reason: reason,
+	}, nil
+}
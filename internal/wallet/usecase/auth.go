@@ -0,0 +1,189 @@
+package usecase
+
+import (
+	"WalletRieltaTestTask/internal/entity"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"github.com/golang-jwt/jwt/v5"
+	"strings"
+	"time"
+)
+
+const _defaultSignatureMaxSkew = 5 * time.Minute
+
+// Scopes of end user tokens without the "scope" claim.
+var _defaultTokenScopes = []string{entity.ScopeRead, entity.ScopeTransfer}
+
+// AuthUseCase -.
+type AuthUseCase struct {
+	gateway AuthGateway
+	timeout time.Duration
+	maxSkew time.Duration
+	now     func() time.Time
+
+	// Bearer tokens are rejected if keyfunc is not set.
+	keyfunc  jwt.Keyfunc
+	issuer   string
+	audience string
+}
+
+type tokenClaims struct {
+	jwt.RegisteredClaims
+	Scope string   `json:"scope,omitempty"`
+	Roles []string `json:"roles,omitempty"`
+}
+
+func NewAuth(gw AuthGateway, opts ...AuthOption) *AuthUseCase {
+	uc := &AuthUseCase{
+		gateway: gw,
+		timeout: _defaultTimeout,
+		maxSkew: _defaultSignatureMaxSkew,
+		now:     time.Now,
+	}
+
+	for _, opt := range opts {
+		opt(uc)
+	}
+
+	return uc
+}
+
+// AuthenticateAPIKey - checks the raw key sent in the request against the stored hash.
+// Keys have the form "<id>.<secret>".
+func (uc *AuthUseCase) AuthenticateAPIKey(ctx context.Context, rawKey string) (*entity.Principal, error) {
+	id, _, ok := strings.Cut(rawKey, ".")
+	if !ok || id == "" {
+		return nil, entity.ErrUnauthorized
+	}
+
+	key, err := uc.getActiveKey(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("AuthUseCase - AuthenticateAPIKey - uc.getActiveKey: %w", err)
+	}
+
+	if subtle.ConstantTimeCompare([]byte(entity.HashAPIKey(rawKey)), []byte(key.Hash)) != 1 {
+		return nil, entity.ErrUnauthorized
+	}
+
+	if key.RequireSignature {
+		return nil, entity.ErrSignatureRequired
+	}
+
+	return key.Principal(), nil
+}
+
+// AuthenticateSignedRequest - checks the HMAC-SHA256 signature of the request made with the signing secret,
+// the request timestamp and that the nonce has not been used before.
+func (uc *AuthUseCase) AuthenticateSignedRequest(
+	ctx context.Context,
+	request *entity.SignedRequest,
+) (*entity.Principal, error) {
+	if request.KeyID == "" || request.Nonce == "" || request.Signature == "" {
+		return nil, entity.ErrUnauthorized
+	}
+
+	now := uc.now()
+	if request.Timestamp.Before(now.Add(-uc.maxSkew)) || request.Timestamp.After(now.Add(uc.maxSkew)) {
+		return nil, entity.ErrInvalidSignature
+	}
+
+	key, err := uc.getActiveKey(ctx, request.KeyID)
+	if err != nil {
+		return nil, fmt.Errorf("AuthUseCase - AuthenticateSignedRequest - uc.getActiveKey: %w", err)
+	}
+
+	// Keys created before signing secrets were introduced can't sign requests
+	if key.SigningSecret == "" {
+		return nil, entity.ErrInvalidSignature
+	}
+
+	signature, err := hex.DecodeString(request.Signature)
+	if err != nil {
+		return nil, entity.ErrInvalidSignature
+	}
+
+	mac := hmac.New(sha256.New, []byte(key.SigningSecret))
+	mac.Write(request.Payload())
+
+	if !hmac.Equal(signature, mac.Sum(nil)) {
+		return nil, entity.ErrInvalidSignature
+	}
+
+	ctxTimeout, cancel := context.WithTimeout(ctx, uc.timeout)
+	defer cancel()
+
+	// Nonce must be kept at least as long as the timestamp is accepted.
+	registered, err := uc.gateway.RegisterNonce(ctxTimeout, key.ID, request.Nonce, request.Timestamp.Add(uc.maxSkew))
+	if err != nil {
+		return nil, fmt.Errorf("AuthUseCase - AuthenticateSignedRequest - uc.gateway.RegisterNonce: %w", err)
+	}
+
+	if !registered {
+		return nil, entity.ErrRequestReplayed
+	}
+
+	return key.Principal(), nil
+}
+
+// AuthenticateToken - verifies the RS256/ES256 signed jwt of an end user.
+// The "sub" claim becomes the subject of the request.
+func (uc *AuthUseCase) AuthenticateToken(_ context.Context, token string) (*entity.Principal, error) {
+	if uc.keyfunc == nil {
+		return nil, entity.ErrUnauthorized
+	}
+
+	opts := []jwt.ParserOption{
+		jwt.WithValidMethods([]string{jwt.SigningMethodRS256.Alg(), jwt.SigningMethodES256.Alg()}),
+		jwt.WithExpirationRequired(),
+		jwt.WithTimeFunc(uc.now),
+	}
+	if uc.issuer != "" {
+		opts = append(opts, jwt.WithIssuer(uc.issuer))
+	}
+	if uc.audience != "" {
+		opts = append(opts, jwt.WithAudience(uc.audience))
+	}
+
+	var claims tokenClaims
+
+	_, err := jwt.ParseWithClaims(token, &claims, uc.keyfunc, opts...)
+	if err != nil {
+		return nil, errors.Join(entity.ErrUnauthorized, err)
+	}
+
+	if claims.Subject == "" {
+		return nil, entity.ErrUnauthorized
+	}
+
+	scopes := _defaultTokenScopes
+	if claims.Scope != "" {
+		scopes = strings.Fields(claims.Scope)
+	}
+
+	return &entity.Principal{
+		Subject: claims.Subject,
+		Scopes:  scopes,
+		Roles:   claims.Roles,
+	}, nil
+}
+
+func (uc *AuthUseCase) getActiveKey(ctx context.Context, id string) (*entity.APIKey, error) {
+	ctxTimeout, cancel := context.WithTimeout(ctx, uc.timeout)
+	defer cancel()
+
+	key, err := uc.gateway.GetAPIKeyByID(ctxTimeout, id)
+	if err != nil {
+		return nil, fmt.Errorf("uc.gateway.GetAPIKeyByID: %w", err)
+	}
+
+	if key.Revoked() {
+		return nil, entity.ErrUnauthorized
+	}
+
+	return key, nil
+}
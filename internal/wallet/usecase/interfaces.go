@@ -3,20 +3,81 @@ package usecase
 import (
 	"WalletRieltaTestTask/internal/entity"
 	"context"
+	"time"
 )
 
 type (
 	Wallet interface {
 		CreateNewWalletWithDefaultBalance(ctx context.Context) (*entity.Wallet, error)
-		SendFunds(ctx context.Context, from string, to string, amount uint) error
+		SendFunds(ctx context.Context, from string, to string, amount uint, idempotencyKey string) error
 		GetWalletHistoryByID(ctx context.Context, walletID string) ([]entity.Transaction, error)
 		GetWalletByID(ctx context.Context, walletID string) (*entity.Wallet, error)
 	}
 
 	WalletGateway interface {
-		CreateNewWalletWithBalance(ctx context.Context, balance uint) (*entity.Wallet, error)
-		SendFunds(ctx context.Context, from string, to string, amount uint) error
+		CreateNewWalletWithBalance(
+			ctx context.Context,
+			balance uint,
+			ownerID string,
+			caller entity.Caller,
+		) (*entity.Wallet, error)
+		SendFunds(ctx context.Context, request entity.SendFundsRequest) error
 		GetWalletHistoryByID(ctx context.Context, walletID string) ([]entity.Transaction, error)
 		GetWalletByID(ctx context.Context, walletID string) (*entity.Wallet, error)
+		ListWalletEvents(ctx context.Context, walletID, after string, limit uint) ([]entity.Event, error)
+	}
+
+	WalletEvents interface {
+		Subscribe(ctx context.Context, walletID, lastEventID string) (<-chan *entity.Event, error)
+	}
+
+	Auth interface {
+		AuthenticateAPIKey(ctx context.Context, rawKey string) (*entity.Principal, error)
+		AuthenticateSignedRequest(ctx context.Context, request *entity.SignedRequest) (*entity.Principal, error)
+		AuthenticateToken(ctx context.Context, token string) (*entity.Principal, error)
+	}
+
+	Admin interface {
+		SearchWallets(ctx context.Context, filter entity.WalletFilter) ([]entity.Wallet, error)
+		SearchAudit(ctx context.Context, filter entity.AuditFilter) ([]entity.AuditEntry, error)
+		AdjustBalance(ctx context.Context, walletID string, amount int64, reason string) (*entity.Wallet, error)
+		FreezeWallet(ctx context.Context, walletID string, reason string) (*entity.Wallet, error)
+		UnfreezeWallet(ctx context.Context, walletID string, reason string) (*entity.Wallet, error)
+		ReverseTransaction(ctx context.Context, transactionID int64, reason string) (*entity.Transaction, error)
+	}
+
+	AdminGateway interface {
+		SearchWallets(ctx context.Context, filter entity.WalletFilter) ([]entity.Wallet, error)
+		SearchAudit(ctx context.Context, filter entity.AuditFilter) ([]entity.AuditEntry, error)
+		AdjustBalance(ctx context.Context, walletID string, amount int64, action entity.AdminAction) (*entity.Wallet, error)
+		SetWalletFrozen(ctx context.Context, walletID string, frozen bool, action entity.AdminAction) (*entity.Wallet, error)
+		ReverseTransaction(ctx context.Context, transactionID int64, action entity.AdminAction) (*entity.Transaction, error)
+	}
+
+	Webhooks interface {
+		CreateWebhook(ctx context.Context, url string, events []string, secret string) (*entity.Webhook, error)
+		ListWebhooks(ctx context.Context) ([]entity.Webhook, error)
+		GetWebhook(ctx context.Context, id string) (*entity.Webhook, error)
+		DeleteWebhook(ctx context.Context, id string) error
+		ListDeliveries(ctx context.Context, webhookID string, limit, offset uint) ([]entity.WebhookDelivery, error)
+	}
+
+	WebhookGateway interface {
+		CreateWebhook(ctx context.Context, webhook *entity.Webhook) (*entity.Webhook, error)
+		ListWebhooks(ctx context.Context, createdBy string) ([]entity.Webhook, error)
+		GetWebhook(ctx context.Context, id, createdBy string) (*entity.Webhook, error)
+		DeleteWebhook(ctx context.Context, id, createdBy string) error
+		ListDeliveries(
+			ctx context.Context,
+			webhookID string,
+			createdBy string,
+			limit uint,
+			offset uint,
+		) ([]entity.WebhookDelivery, error)
+	}
+
+	AuthGateway interface {
+		GetAPIKeyByID(ctx context.Context, id string) (*entity.APIKey, error)
+		RegisterNonce(ctx context.Context, keyID, nonce string, expiresAt time.Time) (bool, error)
 	}
 )
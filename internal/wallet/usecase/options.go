@@ -1,6 +1,9 @@
 package usecase
 
-import "time"
+import (
+	"github.com/golang-jwt/jwt/v5"
+	"time"
+)
 
 type Option func(*WalletUseCase)
 
@@ -15,3 +18,31 @@ func DefaultBalance(balance uint) Option {
 		uc.defaultBalance = balance
 	}
 }
+
+type AuthOption func(*AuthUseCase)
+
+func SignatureMaxSkew(skew time.Duration) AuthOption {
+	return func(uc *AuthUseCase) {
+		uc.maxSkew = skew
+	}
+}
+
+// JWT - enables bearer tokens verified with keyfunc. Empty issuer or audience are not checked.
+func JWT(keyfunc jwt.Keyfunc, issuer, audience string) AuthOption {
+	return func(uc *AuthUseCase) {
+		uc.keyfunc = keyfunc
+		uc.issuer = issuer
+		uc.audience = audience
+	}
+}
+
+type EventsOption func(*WalletEventsUseCase)
+
+// EventsBuffer - events kept for a slow subscriber before it is disconnected.
+func EventsBuffer(size int) EventsOption {
+	return func(uc *WalletEventsUseCase) {
+		if size > 0 {
+			uc.buffer = size
+		}
+	}
+}
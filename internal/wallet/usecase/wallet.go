@@ -4,12 +4,16 @@ import (
 	"WalletRieltaTestTask/internal/entity"
 	"context"
 	"fmt"
+	"github.com/google/uuid"
 	"time"
 )
 
 const (
 	_defaultTimeout      = 5 * time.Second
 	_defaultBalance uint = 100
+
+	// Keys are stored in the unique index of the transactions.
+	_maxIdempotencyKeyLength = 255
 )
 
 // WalletUseCase -.
@@ -39,7 +43,15 @@ func (uc *WalletUseCase) CreateNewWalletWithDefaultBalance(ctx context.Context)
 	ctxTimeout, cancel := context.WithTimeout(ctx, _defaultTimeout)
 	defer cancel()
 
-	wallet, err := uc.gateway.CreateNewWalletWithBalance(ctxTimeout, uc.defaultBalance)
+	// Wallets created by end users belong to them
+	ownerID, _ := entity.SubjectFromContext(ctx)
+
+	wallet, err := uc.gateway.CreateNewWalletWithBalance(
+		ctxTimeout,
+		uc.defaultBalance,
+		ownerID,
+		entity.CallerFromContext(ctx),
+	)
 	if err != nil {
 		return nil,
 			fmt.Errorf("WalletUseCase - CreateNewWalletWithDefaultBalance - uc.gateway.CreateNewWalletWithBalance: %w", err)
@@ -48,7 +60,15 @@ func (uc *WalletUseCase) CreateNewWalletWithDefaultBalance(ctx context.Context)
 	return wallet, nil
 }
 
-func (uc *WalletUseCase) SendFunds(ctx context.Context, from string, to string, amount uint) error {
+// SendFunds - the transfer is made once for the idempotency key, repeated requests with it succeed
+// without moving the funds again. Without the key the transfer still isn't repeated by the retries of the call.
+func (uc *WalletUseCase) SendFunds(
+	ctx context.Context,
+	from string,
+	to string,
+	amount uint,
+	idempotencyKey string,
+) error {
 	ctxTimeout, cancel := context.WithTimeout(ctx, _defaultTimeout)
 	defer cancel()
 
@@ -64,7 +84,26 @@ func (uc *WalletUseCase) SendFunds(ctx context.Context, from string, to string,
 		return entity.ErrSenderIsReceiver
 	}
 
-	err := uc.gateway.SendFunds(ctxTimeout, from, to, amount)
+	if len(idempotencyKey) > _maxIdempotencyKeyLength {
+		return entity.ErrInvalidIdempotencyKey
+	}
+
+	if idempotencyKey == "" {
+		idempotencyKey = uuid.New().String()
+	}
+
+	err := uc.checkOwner(ctxTimeout, from)
+	if err != nil {
+		return fmt.Errorf("WalletUseCase - SendFunds - uc.checkOwner: %w", err)
+	}
+
+	err = uc.gateway.SendFunds(ctxTimeout, entity.SendFundsRequest{
+		From:           from,
+		To:             to,
+		Amount:         amount,
+		IdempotencyKey: idempotencyKey,
+		Caller:         entity.CallerFromContext(ctx),
+	})
 	if err != nil {
 		return fmt.Errorf("WalletUseCase - SendFunds - uc.gateway.SendFunds: %w", err)
 	}
@@ -76,6 +115,11 @@ func (uc *WalletUseCase) GetWalletHistoryByID(ctx context.Context, walletID stri
 	ctxTimeout, cancel := context.WithTimeout(ctx, _defaultTimeout)
 	defer cancel()
 
+	err := uc.checkOwner(ctxTimeout, walletID)
+	if err != nil {
+		return nil, fmt.Errorf("WalletUseCase - GetWalletHistoryByID - uc.checkOwner: %w", err)
+	}
+
 	transactions, err := uc.gateway.GetWalletHistoryByID(ctxTimeout, walletID)
 	if err != nil {
 		return nil,
@@ -97,3 +141,23 @@ func (uc *WalletUseCase) GetWalletByID(ctx context.Context, walletID string) (*e
 
 	return wallet, nil
 }
+
+// Requests made on behalf of an end user are allowed only for the wallets the user owns.
+// Service level requests are not restricted.
+func (uc *WalletUseCase) checkOwner(ctx context.Context, walletID string) error {
+	subject, ok := entity.SubjectFromContext(ctx)
+	if !ok {
+		return nil
+	}
+
+	wallet, err := uc.gateway.GetWalletByID(ctx, walletID)
+	if err != nil {
+		return fmt.Errorf("uc.gateway.GetWalletByID: %w", err)
+	}
+
+	if wallet.OwnerID != subject {
+		return entity.ErrNotWalletOwner
+	}
+
+	return nil
+}
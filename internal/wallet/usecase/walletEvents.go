@@ -0,0 +1,241 @@
+package usecase
+
+import (
+	"WalletRieltaTestTask/internal/entity"
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+const (
+	_defaultEventsBuffer      = 64
+	_replayPageSize      uint = 500
+)
+
+// WalletEventsUseCase - fans out the wallet events of the events exchange to the subscribers.
+// Events are delivered at least once, the subscriber resumes from its last event after reconnect.
+type WalletEventsUseCase struct {
+	gateway WalletGateway
+	timeout time.Duration
+	buffer  int
+
+	mu          sync.Mutex
+	subscribers map[string]map[*eventSubscriber]struct{}
+	closed      bool
+}
+
+type eventSubscriber struct {
+	walletID string
+	live     chan *entity.Event
+}
+
+func NewWalletEvents(gw WalletGateway, opts ...EventsOption) *WalletEventsUseCase {
+	uc := &WalletEventsUseCase{
+		gateway:     gw,
+		timeout:     _defaultTimeout,
+		buffer:      _defaultEventsBuffer,
+		subscribers: make(map[string]map[*eventSubscriber]struct{}),
+	}
+
+	for _, opt := range opts {
+		opt(uc)
+	}
+
+	return uc
+}
+
+// Subscribe - streaming events of the wallet committed after the last event, or new events if it's empty.
+// The channel is closed when the context is done, the stream is closed or the subscriber falls behind.
+func (uc *WalletEventsUseCase) Subscribe(
+	ctx context.Context,
+	walletID string,
+	lastEventID string,
+) (<-chan *entity.Event, error) {
+	ctxTimeout, cancel := context.WithTimeout(ctx, uc.timeout)
+	defer cancel()
+
+	wallet, err := uc.gateway.GetWalletByID(ctxTimeout, walletID)
+	if err != nil {
+		return nil, fmt.Errorf("WalletEventsUseCase - Subscribe - uc.gateway.GetWalletByID: %w", err)
+	}
+
+	if subject, ok := entity.SubjectFromContext(ctx); ok && wallet.OwnerID != subject {
+		return nil, entity.ErrNotWalletOwner
+	}
+
+	// Subscribing before the replay, so events committed meanwhile are not missed
+	sub := uc.subscribe(walletID)
+
+	var replay []entity.Event
+
+	if lastEventID != "" {
+		replay, err = uc.gateway.ListWalletEvents(ctxTimeout, walletID, lastEventID, _replayPageSize)
+		if err != nil {
+			uc.unsubscribe(sub)
+			return nil, fmt.Errorf("WalletEventsUseCase - Subscribe - uc.gateway.ListWalletEvents: %w", err)
+		}
+	}
+
+	events := make(chan *entity.Event)
+
+	go uc.forward(ctx, sub, replay, events)
+
+	return events, nil
+}
+
+// Passing the missed events page by page and then the live ones.
+// Live events which were already replayed are skipped.
+func (uc *WalletEventsUseCase) forward(
+	ctx context.Context,
+	sub *eventSubscriber,
+	replay []entity.Event,
+	events chan<- *entity.Event,
+) {
+	defer close(events)
+	defer uc.unsubscribe(sub)
+
+	replayed := make(map[string]struct{})
+
+	for len(replay) > 0 {
+		for i := range replay {
+			select {
+			case events <- &replay[i]:
+				replayed[replay[i].ID] = struct{}{}
+			case <-ctx.Done():
+				return
+			}
+		}
+
+		if uint(len(replay)) < _replayPageSize {
+			break
+		}
+
+		var err error
+
+		replay, err = uc.nextPage(ctx, sub.walletID, replay[len(replay)-1].ID)
+		if err != nil {
+			// Subscriber reconnects and resumes from the last passed event
+			return
+		}
+	}
+
+	for {
+		select {
+		case event, ok := <-sub.live:
+			if !ok {
+				return
+			}
+
+			if _, ok = replayed[event.ID]; ok {
+				delete(replayed, event.ID)
+				continue
+			}
+
+			select {
+			case events <- event:
+			case <-ctx.Done():
+				return
+			}
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+func (uc *WalletEventsUseCase) nextPage(ctx context.Context, walletID, after string) ([]entity.Event, error) {
+	ctxTimeout, cancel := context.WithTimeout(ctx, uc.timeout)
+	defer cancel()
+
+	events, err := uc.gateway.ListWalletEvents(ctxTimeout, walletID, after, _replayPageSize)
+	if err != nil {
+		return nil, fmt.Errorf("uc.gateway.ListWalletEvents: %w", err)
+	}
+
+	return events, nil
+}
+
+// Notify - passing the event to the subscribers of its wallet.
+// Subscribers with a full buffer are disconnected instead of blocking the others.
+func (uc *WalletEventsUseCase) Notify(event *entity.Event) {
+	uc.mu.Lock()
+	defer uc.mu.Unlock()
+
+	for sub := range uc.subscribers[event.WalletID] {
+		select {
+		case sub.live <- event:
+		default:
+			uc.remove(sub)
+		}
+	}
+}
+
+// Resync - disconnecting all subscribers, so they resume from their last events.
+// Used when events of the exchange may have been missed.
+func (uc *WalletEventsUseCase) Resync() {
+	uc.mu.Lock()
+	defer uc.mu.Unlock()
+
+	for _, subs := range uc.subscribers {
+		for sub := range subs {
+			uc.remove(sub)
+		}
+	}
+}
+
+// Close - disconnecting all subscribers, new subscriptions are closed at once.
+func (uc *WalletEventsUseCase) Close() {
+	uc.Resync()
+
+	uc.mu.Lock()
+	uc.closed = true
+	uc.mu.Unlock()
+}
+
+func (uc *WalletEventsUseCase) subscribe(walletID string) *eventSubscriber {
+	sub := &eventSubscriber{
+		walletID: walletID,
+		live:     make(chan *entity.Event, uc.buffer),
+	}
+
+	uc.mu.Lock()
+	defer uc.mu.Unlock()
+
+	if uc.closed {
+		close(sub.live)
+		return sub
+	}
+
+	if uc.subscribers[walletID] == nil {
+		uc.subscribers[walletID] = make(map[*eventSubscriber]struct{})
+	}
+	uc.subscribers[walletID][sub] = struct{}{}
+
+	return sub
+}
+
+func (uc *WalletEventsUseCase) unsubscribe(sub *eventSubscriber) {
+	uc.mu.Lock()
+	defer uc.mu.Unlock()
+
+	uc.remove(sub)
+}
+
+// Must be called with the lock held.
+func (uc *WalletEventsUseCase) remove(sub *eventSubscriber) {
+	subs, ok := uc.subscribers[sub.walletID]
+	if !ok {
+		return
+	}
+
+	if _, ok = subs[sub]; !ok {
+		return
+	}
+
+	delete(subs, sub)
+	if len(subs) == 0 {
+		delete(uc.subscribers, sub.walletID)
+	}
+
+	close(sub.live)
+}
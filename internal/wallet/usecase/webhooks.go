@@ -0,0 +1,171 @@
+package usecase
+
+import (
+	"WalletRieltaTestTask/internal/entity"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"net/url"
+	"slices"
+	"time"
+)
+
+const _webhookSecretPrefix = "whsec_"
+
+// WebhookUseCase - webhook subscriptions of the caller.
+type WebhookUseCase struct {
+	gateway WebhookGateway
+	timeout time.Duration
+}
+
+func NewWebhooks(gw WebhookGateway) *WebhookUseCase {
+	return &WebhookUseCase{
+		gateway: gw,
+		timeout: _defaultTimeout,
+	}
+}
+
+// CreateWebhook - subscribing the caller to the events, the secret is generated if it's empty.
+// Subscriptions of end users receive events of their own wallets only.
+func (uc *WebhookUseCase) CreateWebhook(
+	ctx context.Context,
+	rawURL string,
+	events []string,
+	secret string,
+) (*entity.Webhook, error) {
+	ctxTimeout, cancel := context.WithTimeout(ctx, uc.timeout)
+	defer cancel()
+
+	principal, ok := entity.PrincipalFromContext(ctx)
+	if !ok {
+		return nil, entity.ErrUnauthorized
+	}
+
+	u, err := url.Parse(rawURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil, entity.ErrInvalidWebhookURL
+	}
+
+	events = slices.Clone(events)
+	slices.Sort(events)
+	events = slices.Compact(events)
+
+	for _, event := range events {
+		if !entity.IsEventType(event) {
+			return nil, entity.ErrUnknownEventType
+		}
+	}
+
+	if secret == "" {
+		secret, err = newWebhookSecret()
+		if err != nil {
+			return nil, fmt.Errorf("WebhookUseCase - CreateWebhook - newWebhookSecret: %w", err)
+		}
+	}
+
+	webhook, err := uc.gateway.CreateWebhook(ctxTimeout, &entity.Webhook{
+		URL:       u.String(),
+		Secret:    secret,
+		Events:    events,
+		OwnerID:   principal.Subject,
+		CreatedBy: principal.Actor(),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("WebhookUseCase - CreateWebhook - uc.gateway.CreateWebhook: %w", err)
+	}
+
+	return webhook, nil
+}
+
+func (uc *WebhookUseCase) ListWebhooks(ctx context.Context) ([]entity.Webhook, error) {
+	ctxTimeout, cancel := context.WithTimeout(ctx, uc.timeout)
+	defer cancel()
+
+	actor, err := webhookActor(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	webhooks, err := uc.gateway.ListWebhooks(ctxTimeout, actor)
+	if err != nil {
+		return nil, fmt.Errorf("WebhookUseCase - ListWebhooks - uc.gateway.ListWebhooks: %w", err)
+	}
+
+	return webhooks, nil
+}
+
+func (uc *WebhookUseCase) GetWebhook(ctx context.Context, id string) (*entity.Webhook, error) {
+	ctxTimeout, cancel := context.WithTimeout(ctx, uc.timeout)
+	defer cancel()
+
+	actor, err := webhookActor(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	webhook, err := uc.gateway.GetWebhook(ctxTimeout, id, actor)
+	if err != nil {
+		return nil, fmt.Errorf("WebhookUseCase - GetWebhook - uc.gateway.GetWebhook: %w", err)
+	}
+
+	return webhook, nil
+}
+
+func (uc *WebhookUseCase) DeleteWebhook(ctx context.Context, id string) error {
+	ctxTimeout, cancel := context.WithTimeout(ctx, uc.timeout)
+	defer cancel()
+
+	actor, err := webhookActor(ctx)
+	if err != nil {
+		return err
+	}
+
+	err = uc.gateway.DeleteWebhook(ctxTimeout, id, actor)
+	if err != nil {
+		return fmt.Errorf("WebhookUseCase - DeleteWebhook - uc.gateway.DeleteWebhook: %w", err)
+	}
+
+	return nil
+}
+
+func (uc *WebhookUseCase) ListDeliveries(
+	ctx context.Context,
+	webhookID string,
+	limit uint,
+	offset uint,
+) ([]entity.WebhookDelivery, error) {
+	ctxTimeout, cancel := context.WithTimeout(ctx, uc.timeout)
+	defer cancel()
+
+	actor, err := webhookActor(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	deliveries, err := uc.gateway.ListDeliveries(ctxTimeout, webhookID, actor, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("WebhookUseCase - ListDeliveries - uc.gateway.ListDeliveries: %w", err)
+	}
+
+	return deliveries, nil
+}
+
+// Webhooks are available only to the caller who created them.
+func webhookActor(ctx context.Context) (string, error) {
+	principal, ok := entity.PrincipalFromContext(ctx)
+	if !ok {
+		return "", entity.ErrUnauthorized
+	}
+
+	return principal.Actor(), nil
+}
+
+func newWebhookSecret() (string, error) {
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return "", fmt.Errorf("rand.Read: %w", err)
+	}
+
+	return _webhookSecretPrefix + hex.EncodeToString(secret), nil
+}
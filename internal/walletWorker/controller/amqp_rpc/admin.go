@@ -0,0 +1,82 @@
+package amqp_rpc
+
+import (
+	"WalletRieltaTestTask/internal/entity"
+	"WalletRieltaTestTask/internal/walletWorker/usecase"
+	"WalletRieltaTestTask/pkg/rabbitmq/rmq_rpc/server"
+	"context"
+	"fmt"
+)
+
+type adminRoutes struct {
+	a     usecase.AdminWorker
+	audit usecase.AuditWorker
+}
+
+// Declaring admin routes for rmq rpc.
+func newAdminRoutes(routes map[string]server.CallHandler, a usecase.AdminWorker, audit usecase.AuditWorker) {
+	r := &adminRoutes{a, audit}
+	{
+		routes["searchWallets"] = server.Handle(r.searchWallets)
+		routes["searchAudit"] = server.Handle(r.searchAudit)
+		routes["adjustBalance"] = server.Handle(r.adjustBalance)
+		routes["setWalletFrozen"] = server.Handle(r.setWalletFrozen)
+		routes["reverseTransaction"] = server.Handle(r.reverseTransaction)
+	}
+}
+
+// Handles a remote "searchWallets" call.
+func (r *adminRoutes) searchWallets(ctx context.Context, request entity.SearchWalletsRequest) ([]entity.Wallet, error) {
+	wallets, err := r.a.SearchWallets(ctx, request.Filter)
+	if err != nil {
+		return nil, fmt.Errorf("amqp_rpc - adminRoutes - searchWallets - r.a.SearchWallets: %w", err)
+	}
+
+	return wallets, nil
+}
+
+// Handles a remote "searchAudit" call.
+func (r *adminRoutes) searchAudit(ctx context.Context, request entity.SearchAuditRequest) ([]entity.AuditEntry, error) {
+	entries, err := r.audit.SearchAudit(ctx, request.Filter)
+	if err != nil {
+		return nil, fmt.Errorf("amqp_rpc - adminRoutes - searchAudit - r.audit.SearchAudit: %w", err)
+	}
+
+	return entries, nil
+}
+
+// Handles a remote "adjustBalance" call.
+func (r *adminRoutes) adjustBalance(ctx context.Context, request entity.AdjustBalanceRequest) (*entity.Wallet, error) {
+	wallet, err := r.a.AdjustBalance(ctx, request.WalletID, request.Amount, request.Action)
+	if err != nil {
+		return nil, fmt.Errorf("amqp_rpc - adminRoutes - adjustBalance - r.a.AdjustBalance: %w", err)
+	}
+
+	return wallet, nil
+}
+
+// Handles a remote "setWalletFrozen" call.
+func (r *adminRoutes) setWalletFrozen(
+	ctx context.Context,
+	request entity.SetWalletFrozenRequest,
+) (*entity.Wallet, error) {
+	wallet, err := r.a.SetWalletFrozen(ctx, request.WalletID, request.Frozen, request.Action)
+	if err != nil {
+		return nil, fmt.Errorf("amqp_rpc - adminRoutes - setWalletFrozen - r.a.SetWalletFrozen: %w", err)
+	}
+
+	return wallet, nil
+}
+
+// Handles a remote "reverseTransaction" call.
+func (r *adminRoutes) reverseTransaction(
+	ctx context.Context,
+	request entity.ReverseTransactionRequest,
+) (*entity.Transaction, error) {
+	transaction, err := r.a.ReverseTransaction(ctx, request.TransactionID, request.Action)
+	if err != nil {
+		return nil, fmt.Errorf("amqp_rpc - adminRoutes - reverseTransaction - r.a.ReverseTransaction: %w", err)
+	}
+
+	return transaction, nil
+}
@@ -0,0 +1,45 @@
+package amqp_rpc
+
+import (
+	"WalletRieltaTestTask/internal/entity"
+	"WalletRieltaTestTask/internal/walletWorker/usecase"
+	"WalletRieltaTestTask/pkg/rabbitmq/rmq_rpc/server"
+	"context"
+	"fmt"
+)
+
+type apiKeyRoutes struct {
+	k usecase.APIKeyWorker
+}
+
+// Declaring api key routes for rmq rpc.
+func newAPIKeyRoutes(routes map[string]server.CallHandler, k usecase.APIKeyWorker) {
+	r := &apiKeyRoutes{k}
+	{
+		routes["getAPIKeyByID"] = server.Handle(r.getAPIKeyByID)
+		routes["registerNonce"] = server.Handle(r.registerNonce)
+	}
+}
+
+// Handles a remote "getAPIKeyByID" call.
+func (r *apiKeyRoutes) getAPIKeyByID(ctx context.Context, request entity.GetAPIKeyByIDRequest) (*entity.APIKey, error) {
+	key, err := r.k.GetAPIKeyByID(ctx, request.ID)
+	if err != nil {
+		return nil, fmt.Errorf("amqp_rpc - apiKeyRoutes - getAPIKeyByID - r.k.GetAPIKeyByID: %w", err)
+	}
+
+	return key, nil
+}
+
+// Handles a remote "registerNonce" call.
+func (r *apiKeyRoutes) registerNonce(
+	ctx context.Context,
+	request entity.RegisterNonceRequest,
+) (*entity.RegisterNonceResponse, error) {
+	registered, err := r.k.RegisterNonce(ctx, request.KeyID, request.Nonce, request.ExpiresAt)
+	if err != nil {
+		return nil, fmt.Errorf("amqp_rpc - apiKeyRoutes - registerNonce - r.k.RegisterNonce: %w", err)
+	}
+
+	return &entity.RegisterNonceResponse{Registered: registered}, nil
+}
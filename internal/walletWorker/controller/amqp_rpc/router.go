@@ -5,10 +5,19 @@ import (
 	"WalletRieltaTestTask/pkg/rabbitmq/rmq_rpc/server"
 )
 
-func NewRouter(r usecase.WalletWorker) map[string]server.CallHandler {
+func NewRouter(
+	r usecase.WalletWorker,
+	k usecase.APIKeyWorker,
+	a usecase.AdminWorker,
+	audit usecase.AuditWorker,
+	webhooks usecase.WebhookWorker,
+) map[string]server.CallHandler {
 	routes := make(map[string]server.CallHandler)
 	{
 		newWalletWorkerRoutes(routes, r)
+		newAPIKeyRoutes(routes, k)
+		newAdminRoutes(routes, a, audit)
+		newWebhookRoutes(routes, webhooks)
 	}
 
 	return routes
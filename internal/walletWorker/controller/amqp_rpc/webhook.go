@@ -0,0 +1,90 @@
+package amqp_rpc
+
+import (
+	"WalletRieltaTestTask/internal/entity"
+	"WalletRieltaTestTask/internal/walletWorker/usecase"
+	"WalletRieltaTestTask/pkg/rabbitmq/rmq_rpc/server"
+	"context"
+	"fmt"
+)
+
+type webhookRoutes struct {
+	w usecase.WebhookWorker
+}
+
+// Declaring webhook routes for rmq rpc.
+func newWebhookRoutes(routes map[string]server.CallHandler, w usecase.WebhookWorker) {
+	r := &webhookRoutes{w}
+	{
+		routes["createWebhook"] = server.Handle(r.createWebhook)
+		routes["listWebhooks"] = server.Handle(r.listWebhooks)
+		routes["getWebhook"] = server.Handle(r.getWebhook)
+		routes["deleteWebhook"] = server.Handle(r.deleteWebhook)
+		routes["listWebhookDeliveries"] = server.Handle(r.listWebhookDeliveries)
+	}
+}
+
+// Handles a remote "createWebhook" call.
+func (r *webhookRoutes) createWebhook(
+	ctx context.Context,
+	request entity.CreateWebhookRequest,
+) (*entity.Webhook, error) {
+	webhook, err := r.w.CreateWebhook(ctx, &request.Webhook)
+	if err != nil {
+		return nil, fmt.Errorf("amqp_rpc - webhookRoutes - createWebhook - r.w.CreateWebhook: %w", err)
+	}
+
+	return webhook, nil
+}
+
+// Handles a remote "listWebhooks" call.
+func (r *webhookRoutes) listWebhooks(
+	ctx context.Context,
+	request entity.ListWebhooksRequest,
+) ([]entity.Webhook, error) {
+	webhooks, err := r.w.ListWebhooks(ctx, request.CreatedBy)
+	if err != nil {
+		return nil, fmt.Errorf("amqp_rpc - webhookRoutes - listWebhooks - r.w.ListWebhooks: %w", err)
+	}
+
+	return webhooks, nil
+}
+
+// Handles a remote "getWebhook" call.
+func (r *webhookRoutes) getWebhook(ctx context.Context, request entity.WebhookByIDRequest) (*entity.Webhook, error) {
+	webhook, err := r.w.GetWebhook(ctx, request.ID, request.CreatedBy)
+	if err != nil {
+		return nil, fmt.Errorf("amqp_rpc - webhookRoutes - getWebhook - r.w.GetWebhook: %w", err)
+	}
+
+	return webhook, nil
+}
+
+// Handles a remote "deleteWebhook" call.
+func (r *webhookRoutes) deleteWebhook(ctx context.Context, request entity.WebhookByIDRequest) (struct{}, error) {
+	err := r.w.DeleteWebhook(ctx, request.ID, request.CreatedBy)
+	if err != nil {
+		return struct{}{}, fmt.Errorf("amqp_rpc - webhookRoutes - deleteWebhook - r.w.DeleteWebhook: %w", err)
+	}
+
+	return struct{}{}, nil
+}
+
+// Handles a remote "listWebhookDeliveries" call.
+func (r *webhookRoutes) listWebhookDeliveries(
+	ctx context.Context,
+	request entity.ListWebhookDeliveriesRequest,
+) ([]entity.WebhookDelivery, error) {
+	deliveries, err := r.w.ListDeliveries(
+		ctx,
+		request.WebhookID,
+		request.CreatedBy,
+		request.Limit,
+		request.Offset,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("amqp_rpc - webhookRoutes - listWebhookDeliveries - r.w.ListDeliveries: %w", err)
+	}
+
+	return deliveries, nil
+}
@@ -5,10 +5,7 @@ import (
 	"WalletRieltaTestTask/internal/walletWorker/usecase"
 	"WalletRieltaTestTask/pkg/rabbitmq/rmq_rpc/server"
 	"context"
-	"encoding/json"
-	"errors"
 	"fmt"
-	"github.com/streadway/amqp"
 )
 
 type walletWorkerRoutes struct {
@@ -19,94 +16,78 @@ type walletWorkerRoutes struct {
 func newWalletWorkerRoutes(routes map[string]server.CallHandler, w usecase.WalletWorker) {
 	r := &walletWorkerRoutes{w}
 	{
-		routes["createNewWallet"] = r.createNewWalletWithBalance()
-		routes["sendFunds"] = r.sendFunds()
-		routes["getWalletHistoryByID"] = r.getWalletHistoryByID()
-		routes["getWalletByID"] = r.getWalletByID()
+		routes["createNewWallet"] = server.Handle(r.createNewWalletWithBalance)
+		routes["sendFunds"] = server.Handle(r.sendFunds)
+		routes["getWalletHistoryByID"] = server.Handle(r.getWalletHistoryByID)
+		routes["getWalletByID"] = server.Handle(r.getWalletByID)
+		routes["listWalletEvents"] = server.Handle(r.listWalletEvents)
 	}
 }
 
 // Handles a remote "createNewWallet" call.
-func (r *walletWorkerRoutes) createNewWalletWithBalance() server.CallHandler {
-	return func(d *amqp.Delivery) (interface{}, error) {
-		var request entity.CreateNewWalletWithBalanceRequest
-
-		if err := json.Unmarshal(d.Body, &request); err != nil {
-			return nil, fmt.Errorf("amqp_rpc - walletWorkerRoutes - createNewWalletWithBalance - json.Unmarshal: %w", err)
-		}
-
-		wallet, err := r.w.CreateNewWalletWithBalance(context.Background(), request.Balance)
-		if err != nil {
-			return nil,
-				fmt.Errorf("amqp_rpc - walletWorkerRoutes - createNewWalletWithBalance - r.w.CreateNewWalletWithBalance: %w", err)
-		}
-
-		return wallet, nil
+func (r *walletWorkerRoutes) createNewWalletWithBalance(
+	ctx context.Context,
+	request entity.CreateNewWalletWithBalanceRequest,
+) (*entity.Wallet, error) {
+	wallet, err := r.w.CreateNewWalletWithBalance(
+		ctx,
+		request.Balance,
+		request.OwnerID,
+		request.Caller,
+	)
+	if err != nil {
+		return nil,
+			fmt.Errorf("amqp_rpc - walletWorkerRoutes - createNewWalletWithBalance - r.w.CreateNewWalletWithBalance: %w", err)
 	}
+
+	return wallet, nil
 }
 
 // Handles a remote "sendFunds" call.
-func (r *walletWorkerRoutes) sendFunds() server.CallHandler {
-	return func(d *amqp.Delivery) (interface{}, error) {
-		var request entity.SendFundsRequest
-
-		if err := json.Unmarshal(d.Body, &request); err != nil {
-			return nil, fmt.Errorf("amqp_rpc - walletWorkerRoutes - sendFunds - json.Unmarshal: %w", err)
-		}
-
-		err := r.w.SendFunds(context.Background(), request.From, request.To, request.Amount)
-		if err != nil {
-			if errors.Is(err, entity.ErrWalletNotFound) {
-				return nil, entity.ErrNotFound
-			}
-
-			return nil, fmt.Errorf("amqp_rpc - walletWorkerRoutes - sendFunds - r.w.SendFunds: %w", err)
-		}
-
-		return nil, nil
+func (r *walletWorkerRoutes) sendFunds(ctx context.Context, request entity.SendFundsRequest) (struct{}, error) {
+	err := r.w.SendFunds(ctx, request.From, request.To, request.Amount, request.IdempotencyKey, request.Caller)
+	if err != nil {
+		return struct{}{}, fmt.Errorf("amqp_rpc - walletWorkerRoutes - sendFunds - r.w.SendFunds: %w", err)
 	}
+
+	return struct{}{}, nil
 }
 
 // Handles a remote "getWalletHistoryByID" call.
-func (r *walletWorkerRoutes) getWalletHistoryByID() server.CallHandler {
-	return func(d *amqp.Delivery) (interface{}, error) {
-		var request entity.GetWalletHistoryByIDRequest
-
-		if err := json.Unmarshal(d.Body, &request); err != nil {
-			return nil, fmt.Errorf("amqp_rpc - walletWorkerRoutes - GetWalletHistoryByID - json.Unmarshal: %w", err)
-		}
-
-		transactions, err := r.w.GetWalletHistoryByID(context.Background(), request.WalletID)
-		if err != nil {
-			if errors.Is(err, entity.ErrWalletNotFound) {
-				return nil, entity.ErrNotFound
-			}
-
-			return nil, fmt.Errorf("amqp_rpc - walletWorkerRoutes - GetWalletHistoryByID - r.w.GetWalletHistoryByID: %w", err)
-		}
-
-		return transactions, nil
+func (r *walletWorkerRoutes) getWalletHistoryByID(
+	ctx context.Context,
+	request entity.GetWalletHistoryByIDRequest,
+) ([]entity.Transaction, error) {
+	transactions, err := r.w.GetWalletHistoryByID(ctx, request.WalletID)
+	if err != nil {
+		return nil, fmt.Errorf("amqp_rpc - walletWorkerRoutes - GetWalletHistoryByID - r.w.GetWalletHistoryByID: %w", err)
 	}
+
+	return transactions, nil
 }
 
 // Handles a remote "getWalletByID" call.
-func (r *walletWorkerRoutes) getWalletByID() server.CallHandler {
-	return func(d *amqp.Delivery) (interface{}, error) {
-		var request entity.GetWalletByIDRequest
-
-		if err := json.Unmarshal(d.Body, &request); err != nil {
-			return nil, fmt.Errorf("amqp_rpc - walletWorkerRoutes - GetWalletByID - json.Unmarshal: %w", err)
-		}
-
-		wallet, err := r.w.GetWalletByID(context.Background(), request.WalletID)
-		if err != nil {
-			if errors.Is(err, entity.ErrWalletNotFound) {
-				return nil, entity.ErrNotFound
-			}
+func (r *walletWorkerRoutes) getWalletByID(
+	ctx context.Context,
+	request entity.GetWalletByIDRequest,
+) (*entity.Wallet, error) {
+	wallet, err := r.w.GetWalletByID(ctx, request.WalletID)
+	if err != nil {
+		return nil, fmt.Errorf("amqp_rpc - walletWorkerRoutes - GetWalletByID - r.w.GetWalletByID: %w", err)
+	}
 
-			return nil, fmt.Errorf("amqp_rpc - walletWorkerRoutes - GetWalletByID - r.w.GetWalletByID: %w", err)
-		}
+	return wallet, nil
+}
 
-		return wallet, nil
+// Handles a remote "listWalletEvents" call.
+func (r *walletWorkerRoutes) listWalletEvents(
+	ctx context.Context,
+	request entity.ListWalletEventsRequest,
+) ([]entity.Event, error) {
+	events, err := r.w.ListWalletEvents(ctx, request.WalletID, request.After, request.Limit)
+	if err != nil {
+		return nil, fmt.Errorf("amqp_rpc - walletWorkerRoutes - listWalletEvents - r.w.ListWalletEvents: %w", err)
 	}
+
+	return events, nil
 }
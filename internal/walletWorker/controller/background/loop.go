@@ -0,0 +1,90 @@
+package background
+
+import (
+	"WalletRieltaTestTask/pkg/logger"
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+const _defaultInterval = time.Second
+
+// Batch - processes the next batch of the work, returns the number of processed items.
+type Batch func(ctx context.Context) (int, error)
+
+// Loop - periodically runs the batch in the background, like the outbox relay or the webhook dispatcher.
+// While there is work, the next batch is taken without waiting.
+type Loop struct {
+	name     string
+	batch    Batch
+	interval time.Duration
+	logger   *slog.Logger
+
+	cancel context.CancelFunc
+	done   sync.WaitGroup
+}
+
+func New(name string, batch Batch, l *slog.Logger, opts ...Option) *Loop {
+	loop := &Loop{
+		name:     name,
+		batch:    batch,
+		interval: _defaultInterval,
+		logger:   l,
+	}
+
+	for _, opt := range opts {
+		opt(loop)
+	}
+
+	return loop
+}
+
+func (l *Loop) MustRun() {
+	ctx, cancel := context.WithCancel(context.Background())
+	l.cancel = cancel
+
+	l.done.Add(1)
+
+	go func() {
+		defer l.done.Done()
+		l.run(ctx)
+	}()
+}
+
+func (l *Loop) run(ctx context.Context) {
+	timer := time.NewTimer(0)
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-timer.C:
+		}
+
+		// The started batch is finished even if the loop is stopping
+		processed, err := l.batch(context.WithoutCancel(ctx))
+		if err != nil {
+			l.logger.Error("background - Loop - run - "+l.name, logger.Err(err))
+		}
+
+		if processed > 0 && err == nil {
+			timer.Reset(0)
+			continue
+		}
+
+		timer.Reset(l.interval)
+	}
+}
+
+// Shutdown - waiting for the current batch to finish.
+func (l *Loop) Shutdown() error {
+	if l.cancel != nil {
+		l.cancel()
+	}
+
+	l.done.Wait()
+
+	return nil
+}
@@ -0,0 +1,14 @@
+package background
+
+import "time"
+
+type Option func(*Loop)
+
+// Interval - pause between batches when there is no work.
+func Interval(interval time.Duration) Option {
+	return func(l *Loop) {
+		if interval > 0 {
+			l.interval = interval
+		}
+	}
+}
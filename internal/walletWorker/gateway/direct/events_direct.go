@@ -0,0 +1,22 @@
+package gateway
+
+import (
+	"WalletRieltaTestTask/internal/entity"
+	"context"
+)
+
+type EventGateway struct {
+	notify func(event *entity.Event)
+}
+
+// Init of events gateway, through we will pass wallet events to the subscribers in the same process.
+func NewEvents(notify func(event *entity.Event)) *EventGateway {
+	return &EventGateway{notify}
+}
+
+// Publishing the event to the subscribers of its wallet, slow subscribers are dropped instead of blocking the relay.
+func (gw *EventGateway) Publish(_ context.Context, event *entity.Event) error {
+	gw.notify(event)
+
+	return nil
+}
@@ -0,0 +1,141 @@
+package gateway
+
+import (
+	"WalletRieltaTestTask/internal/entity"
+	"WalletRieltaTestTask/internal/walletWorker/usecase"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// Repository of a single delivery, it's claimed until it's delivered or dead.
+type deliveryRepo struct {
+	mu       sync.Mutex
+	dispatch entity.WebhookDispatch
+	attempts []entity.WebhookDelivery
+}
+
+func (r *deliveryRepo) ClaimDeliveries(context.Context, uint, time.Duration) ([]entity.WebhookDispatch, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.dispatch.Delivery.Status != entity.DeliveryPending {
+		return nil, nil
+	}
+
+	return []entity.WebhookDispatch{r.dispatch}, nil
+}
+
+func (r *deliveryRepo) SaveAttempt(_ context.Context, delivery *entity.WebhookDelivery) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.dispatch.Delivery = *delivery
+	r.attempts = append(r.attempts, *delivery)
+
+	return nil
+}
+
+// Receiver failing the first requests.
+func flakyReceiver(failures int32) (*httptest.Server, *atomic.Int32) {
+	var calls atomic.Int32
+
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		if calls.Add(1) <= failures {
+			w.WriteHeader(http.StatusBadGateway)
+		}
+	}))
+
+	return receiver, &calls
+}
+
+func dispatchAll(t *testing.T, dispatcher *usecase.WebhookDispatcherUseCase) {
+	t.Helper()
+
+	for i := 0; i < 10; i++ {
+		n, err := dispatcher.DispatchPending(context.Background())
+		if err != nil {
+			t.Fatalf("DispatchPending: %v", err)
+		}
+
+		if n == 0 {
+			return
+		}
+	}
+
+	t.Fatal("delivery is still pending")
+}
+
+func TestWebhookDispatchRetries(t *testing.T) {
+	receiver, calls := flakyReceiver(2)
+	defer receiver.Close()
+
+	dispatch := testDispatch(receiver.URL)
+	dispatch.Delivery.Status = entity.DeliveryPending
+
+	repo := &deliveryRepo{dispatch: *dispatch}
+	dispatcher := usecase.NewWebhookDispatcher(repo, newTestWebhook(),
+		usecase.MaxAttempts(5),
+		usecase.Backoff(time.Second, time.Minute),
+	)
+
+	start := time.Now()
+	dispatchAll(t, dispatcher)
+	end := time.Now()
+
+	if calls.Load() != 3 {
+		t.Fatalf("receiver was called %d times, want 3", calls.Load())
+	}
+
+	if len(repo.attempts) != 3 {
+		t.Fatalf("%d attempts saved, want 3", len(repo.attempts))
+	}
+
+	// Failed attempts are scheduled with the doubling backoff
+	for i, delay := range []time.Duration{time.Second, 2 * time.Second} {
+		attempt := repo.attempts[i]
+
+		if attempt.Status != entity.DeliveryPending || attempt.ResponseStatus != http.StatusBadGateway {
+			t.Fatalf("attempt %d = %s %d, want pending %d", i+1, attempt.Status, attempt.ResponseStatus, http.StatusBadGateway)
+		}
+
+		if attempt.NextAttemptAt == nil || attempt.LastError == "" {
+			t.Fatalf("attempt %d isn't scheduled for retry", i+1)
+		}
+
+		if next := *attempt.NextAttemptAt; next.Before(start.Add(delay)) || next.After(end.Add(delay)) {
+			t.Fatalf("attempt %d is retried at %s, want %s after it", i+1, next, delay)
+		}
+	}
+
+	delivered := repo.dispatch.Delivery
+	if delivered.Status != entity.DeliveryDelivered || delivered.Attempts != 3 || delivered.DeliveredAt == nil {
+		t.Fatalf("delivery = %+v, want delivered after 3 attempts", delivered)
+	}
+}
+
+func TestWebhookDispatchDeadAfterMaxAttempts(t *testing.T) {
+	receiver, calls := flakyReceiver(100)
+	defer receiver.Close()
+
+	dispatch := testDispatch(receiver.URL)
+	dispatch.Delivery.Status = entity.DeliveryPending
+
+	repo := &deliveryRepo{dispatch: *dispatch}
+	dispatcher := usecase.NewWebhookDispatcher(repo, newTestWebhook(), usecase.MaxAttempts(3))
+
+	dispatchAll(t, dispatcher)
+
+	if calls.Load() != 3 {
+		t.Fatalf("receiver was called %d times, want 3", calls.Load())
+	}
+
+	dead := repo.dispatch.Delivery
+	if dead.Status != entity.DeliveryDead || dead.Attempts != 3 || dead.ResponseStatus != http.StatusBadGateway {
+		t.Fatalf("delivery = %+v, want dead after 3 attempts", dead)
+	}
+}
@@ -0,0 +1,142 @@
+package gateway
+
+import (
+	"WalletRieltaTestTask/internal/entity"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/netip"
+	"strconv"
+	"syscall"
+	"time"
+)
+
+const (
+	_defaultTimeout = 10 * time.Second
+
+	// Only the beginning of the receiver response is kept for the delivery log.
+	_maxResponseBody = 512
+
+	HeaderWebhookID        = "X-Webhook-ID"
+	HeaderWebhookEvent     = "X-Webhook-Event"
+	HeaderWebhookDelivery  = "X-Webhook-Delivery"
+	HeaderWebhookTimestamp = "X-Webhook-Timestamp"
+	HeaderWebhookSignature = "X-Webhook-Signature"
+)
+
+var (
+	// ErrNotPublicAddress - the webhook url resolves to an address of the internal network.
+	ErrNotPublicAddress = errors.New("webhook address isn't public")
+
+	// Carrier-grade NAT addresses, RFC 6598.
+	_sharedAddressSpace = netip.MustParsePrefix("100.64.0.0/10")
+)
+
+type WebhookGateway struct {
+	client *http.Client
+	now    func() time.Time
+	// allowed - addresses the webhooks may be delivered to.
+	allowed func(addr netip.AddrPort) bool
+}
+
+// Init of webhook gateway, through we will POST events to the partners.
+// Webhook urls are set by the clients, so deliveries to the internal network are refused.
+func NewWebhook(timeout time.Duration) *WebhookGateway {
+	if timeout <= 0 {
+		timeout = _defaultTimeout
+	}
+
+	gw := &WebhookGateway{
+		now:     time.Now,
+		allowed: func(addr netip.AddrPort) bool { return isPublic(addr.Addr().Unmap()) },
+	}
+
+	// The address is checked after it's resolved, right before connecting, so the host can't
+	// resolve to a public address at the registration and to an internal one at the delivery.
+	// Redirects are dialed the same way. Proxies are not used, they would be dialed instead of the receiver.
+	dialer := &net.Dialer{
+		Timeout: timeout,
+		Control: gw.control,
+	}
+
+	gw.client = &http.Client{
+		Timeout: timeout,
+		Transport: &http.Transport{
+			DialContext:           dialer.DialContext,
+			ForceAttemptHTTP2:     true,
+			MaxIdleConns:          100,
+			IdleConnTimeout:       90 * time.Second,
+			TLSHandshakeTimeout:   10 * time.Second,
+			ExpectContinueTimeout: 1 * time.Second,
+		},
+	}
+
+	return gw
+}
+
+func (gw *WebhookGateway) control(_, address string, _ syscall.RawConn) error {
+	addrPort, err := netip.ParseAddrPort(address)
+	if err != nil {
+		return fmt.Errorf("netip.ParseAddrPort: %w", err)
+	}
+
+	if !gw.allowed(addrPort) {
+		return fmt.Errorf("%w: %s", ErrNotPublicAddress, addrPort.Addr())
+	}
+
+	return nil
+}
+
+// Loopback, private, link-local (including the cloud metadata 169.254.169.254), shared and unspecified
+// addresses belong to the internal network.
+func isPublic(addr netip.Addr) bool {
+	return addr.IsGlobalUnicast() &&
+		!addr.IsPrivate() &&
+		!_sharedAddressSpace.Contains(addr)
+}
+
+// Send - POSTing the signed event, any 2xx response is a successful delivery.
+// Returns the response status if the receiver responded.
+func (gw *WebhookGateway) Send(ctx context.Context, dispatch *entity.WebhookDispatch) (int, error) {
+	body, err := json.Marshal(dispatch.Event)
+	if err != nil {
+		return 0, fmt.Errorf("WebhookGateway - Send - json.Marshal: %w", err)
+	}
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, dispatch.URL, bytes.NewReader(body))
+	if err != nil {
+		return 0, fmt.Errorf("WebhookGateway - Send - http.NewRequestWithContext: %w", err)
+	}
+
+	timestamp := gw.now()
+
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set("User-Agent", "wallet-rielta-webhooks/1.0")
+	request.Header.Set(HeaderWebhookID, dispatch.Event.ID)
+	request.Header.Set(HeaderWebhookEvent, dispatch.Event.Type)
+	request.Header.Set(HeaderWebhookDelivery, strconv.FormatInt(dispatch.Delivery.ID, 10))
+	request.Header.Set(HeaderWebhookTimestamp, strconv.FormatInt(timestamp.Unix(), 10))
+	request.Header.Set(HeaderWebhookSignature, "v1="+entity.SignWebhook(dispatch.Secret, timestamp, body))
+
+	response, err := gw.client.Do(request)
+	if err != nil {
+		return 0, fmt.Errorf("WebhookGateway - Send - gw.client.Do: %w", err)
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode < 200 || response.StatusCode > 299 {
+		snippet, _ := io.ReadAll(io.LimitReader(response.Body, _maxResponseBody))
+
+		return response.StatusCode, fmt.Errorf("WebhookGateway - Send - unexpected status %d: %s", response.StatusCode, snippet)
+	}
+
+	// Keep-alive connection can be reused only if the body is read
+	_, _ = io.Copy(io.Discard, io.LimitReader(response.Body, _maxResponseBody))
+
+	return response.StatusCode, nil
+}
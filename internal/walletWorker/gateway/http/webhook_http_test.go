@@ -0,0 +1,215 @@
+package gateway
+
+import (
+	"WalletRieltaTestTask/internal/entity"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+// The receiver listens on the loopback, so the tests allow it.
+func newTestWebhook() *WebhookGateway {
+	gw := NewWebhook(time.Second)
+	gw.allowed = func(netip.AddrPort) bool { return true }
+
+	return gw
+}
+
+func testDispatch(url string) *entity.WebhookDispatch {
+	return &entity.WebhookDispatch{
+		Delivery: entity.WebhookDelivery{ID: 42},
+		URL:      url,
+		Secret:   "whsec_test_secret_1234",
+		Event: entity.Event{
+			ID:         "8f0c6d0e-3c57-4a43-9d55-7d7f6a3ad6e1",
+			Type:       entity.EventFundsSent,
+			OccurredAt: time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC),
+			WalletID:   "wallet-1",
+			Data:       json.RawMessage(`{"amount":30}`),
+		},
+	}
+}
+
+func TestWebhookGatewaySendDelivers(t *testing.T) {
+	var (
+		header http.Header
+		body   []byte
+	)
+
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		header = r.Header.Clone()
+		body, _ = io.ReadAll(r.Body)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer receiver.Close()
+
+	dispatch := testDispatch(receiver.URL)
+
+	status, err := newTestWebhook().Send(context.Background(), dispatch)
+	if err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+
+	if status != http.StatusNoContent {
+		t.Fatalf("status = %d, want %d", status, http.StatusNoContent)
+	}
+
+	var event entity.Event
+	if err := json.Unmarshal(body, &event); err != nil {
+		t.Fatalf("body isn't the event: %v", err)
+	}
+
+	if event.ID != dispatch.Event.ID || event.Type != dispatch.Event.Type || event.WalletID != dispatch.Event.WalletID {
+		t.Fatalf("event = %+v, want %+v", event, dispatch.Event)
+	}
+
+	for name, want := range map[string]string{
+		"Content-Type":        "application/json",
+		HeaderWebhookID:       dispatch.Event.ID,
+		HeaderWebhookEvent:    dispatch.Event.Type,
+		HeaderWebhookDelivery: "42",
+	} {
+		if got := header.Get(name); got != want {
+			t.Errorf("%s = %q, want %q", name, got, want)
+		}
+	}
+}
+
+func TestWebhookGatewaySendSigns(t *testing.T) {
+	now := time.Date(2024, 5, 1, 12, 0, 5, 0, time.UTC)
+
+	var (
+		header http.Header
+		body   []byte
+	)
+
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		header = r.Header.Clone()
+		body, _ = io.ReadAll(r.Body)
+	}))
+	defer receiver.Close()
+
+	gw := newTestWebhook()
+	gw.now = func() time.Time { return now }
+
+	dispatch := testDispatch(receiver.URL)
+
+	if _, err := gw.Send(context.Background(), dispatch); err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+
+	if got := header.Get(HeaderWebhookTimestamp); got != strconv.FormatInt(now.Unix(), 10) {
+		t.Fatalf("timestamp = %s, want %d", got, now.Unix())
+	}
+
+	// The receiver checks the signature the same way
+	timestamp, _ := strconv.ParseInt(header.Get(HeaderWebhookTimestamp), 10, 64)
+	want := "v1=" + entity.SignWebhook(dispatch.Secret, time.Unix(timestamp, 0), body)
+
+	if got := header.Get(HeaderWebhookSignature); got != want {
+		t.Fatalf("signature = %s, want %s", got, want)
+	}
+
+	if header.Get(HeaderWebhookSignature) == "v1="+entity.SignWebhook("another secret", now, body) {
+		t.Fatal("signature doesn't depend on the secret")
+	}
+}
+
+func TestWebhookGatewaySendFails(t *testing.T) {
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		_, _ = w.Write([]byte("maintenance"))
+	}))
+	defer receiver.Close()
+
+	status, err := newTestWebhook().Send(context.Background(), testDispatch(receiver.URL))
+	if err == nil {
+		t.Fatal("Send succeeded on 503")
+	}
+
+	if status != http.StatusServiceUnavailable {
+		t.Fatalf("status = %d, want %d", status, http.StatusServiceUnavailable)
+	}
+
+	if !strings.Contains(err.Error(), "maintenance") {
+		t.Fatalf("error %q doesn't contain the response", err)
+	}
+}
+
+func TestWebhookGatewaySendRefusesInternalAddresses(t *testing.T) {
+	var called bool
+
+	receiver := httptest.NewServer(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {
+		called = true
+	}))
+	defer receiver.Close()
+
+	status, err := NewWebhook(time.Second).Send(context.Background(), testDispatch(receiver.URL))
+	if !errors.Is(err, ErrNotPublicAddress) {
+		t.Fatalf("err = %v, want %v", err, ErrNotPublicAddress)
+	}
+
+	if status != 0 || called {
+		t.Fatal("loopback receiver was called")
+	}
+}
+
+func TestWebhookGatewaySendRefusesRedirectsToInternalAddresses(t *testing.T) {
+	var called bool
+
+	internal := httptest.NewServer(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {
+		called = true
+	}))
+	defer internal.Close()
+
+	redirect := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, internal.URL, http.StatusTemporaryRedirect)
+	}))
+	defer redirect.Close()
+
+	// Both receivers listen on the loopback, only the redirecting one is treated as public
+	public := netip.MustParseAddrPort(redirect.Listener.Addr().String())
+
+	gw := NewWebhook(time.Second)
+	gw.allowed = func(addr netip.AddrPort) bool { return addr == public }
+
+	_, err := gw.Send(context.Background(), testDispatch(redirect.URL))
+	if !errors.Is(err, ErrNotPublicAddress) {
+		t.Fatalf("err = %v, want %v", err, ErrNotPublicAddress)
+	}
+
+	if called {
+		t.Fatal("internal receiver was called after the redirect")
+	}
+}
+
+func TestIsPublic(t *testing.T) {
+	for addr, want := range map[string]bool{
+		"93.184.216.34":   true,
+		"2606:4700::1111": true,
+		"127.0.0.1":       false,
+		"::1":             false,
+		"10.1.2.3":        false,
+		"172.16.0.1":      false,
+		"192.168.1.1":     false,
+		"169.254.169.254": false,
+		"fe80::1":         false,
+		"fd00:ec2::254":   false,
+		"100.64.0.1":      false,
+		"0.0.0.0":         false,
+		"::":              false,
+		"224.0.0.1":       false,
+	} {
+		if got := isPublic(netip.MustParseAddr(addr)); got != want {
+			t.Errorf("isPublic(%s) = %v, want %v", addr, got, want)
+		}
+	}
+}
@@ -0,0 +1,44 @@
+package gateway
+
+import (
+	"WalletRieltaTestTask/internal/entity"
+	"WalletRieltaTestTask/pkg/rabbitmq/publisher"
+	"context"
+	"encoding/json"
+	"fmt"
+)
+
+type EventPublisherRMQ interface {
+	Publish(ctx context.Context, m publisher.Message) error
+}
+
+type EventGateway struct {
+	rmq EventPublisherRMQ
+}
+
+// Init of events gateway, through we will publish wallet events to the topic exchange.
+func NewEvents(rmq EventPublisherRMQ) *EventGateway {
+	return &EventGateway{rmq}
+}
+
+// Publishing the event with its type as the routing key and its id as the message id.
+func (gw *EventGateway) Publish(ctx context.Context, event *entity.Event) error {
+	body, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("EventGateway - Publish - json.Marshal: %w", err)
+	}
+
+	err = gw.rmq.Publish(ctx, publisher.Message{
+		ID:          event.ID,
+		RoutingKey:  event.Type,
+		Type:        event.Type,
+		Timestamp:   event.OccurredAt,
+		ContentType: "application/json",
+		Body:        body,
+	})
+	if err != nil {
+		return fmt.Errorf("EventGateway - Publish - gw.rmq.Publish: %w", err)
+	}
+
+	return nil
+}
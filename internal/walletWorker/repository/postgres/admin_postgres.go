@@ -0,0 +1,346 @@
+package worker_postgres
+
+import (
+	"WalletRieltaTestTask/internal/entity"
+	"WalletRieltaTestTask/pkg/postgres"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/Masterminds/squirrel"
+	"github.com/jackc/pgx/v5"
+	"time"
+)
+
+const (
+	_defaultSearchLimit = 50
+	_maxSearchLimit     = 500
+)
+
+type AdminRepo struct {
+	db     *postgres.Postgres
+	wallet *WalletRepo
+}
+
+func NewAdminRepo(pg *postgres.Postgres) *AdminRepo {
+	return &AdminRepo{pg, New(pg)}
+}
+
+// SearchWallets - getting wallets matching the filter ordered by id.
+func (r *AdminRepo) SearchWallets(ctx context.Context, filter entity.WalletFilter) ([]entity.Wallet, error) {
+	limit := filter.Limit
+	if limit == 0 || limit > _maxSearchLimit {
+		limit = _defaultSearchLimit
+	}
+
+	query := r.db.Builder.
+		Select("id, balance, COALESCE(owner_id, ''), frozen").
+		From(tableWallets).
+		OrderBy("id").
+		Limit(uint64(limit)).
+		Offset(uint64(filter.Offset))
+
+	if filter.OwnerID != "" {
+		query = query.Where("owner_id = ?", filter.OwnerID)
+	}
+	if filter.Frozen != nil {
+		query = query.Where("frozen = ?", *filter.Frozen)
+	}
+	if filter.MinBalance != nil {
+		query = query.Where("balance >= ?", *filter.MinBalance)
+	}
+	if filter.MaxBalance != nil {
+		query = query.Where("balance <= ?", *filter.MaxBalance)
+	}
+
+	sql, args, _ := query.ToSql()
+
+	rows, err := r.db.Pool.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, fmt.Errorf("AdminRepo.SearchWallets - r.Pool.Query: %w", err)
+	}
+	defer rows.Close()
+
+	wallets := make([]entity.Wallet, 0)
+
+	for rows.Next() {
+		var wallet entity.Wallet
+		err = rows.Scan(&wallet.ID, &wallet.Balance, &wallet.OwnerID, &wallet.Frozen)
+		if err != nil {
+			return nil, fmt.Errorf("AdminRepo.SearchWallets - rows.Scan: %w", err)
+		}
+		wallets = append(wallets, wallet)
+	}
+
+	return wallets, nil
+}
+
+// AdjustBalance - manual change of the wallet balance by the signed amount.
+func (r *AdminRepo) AdjustBalance(
+	ctx context.Context,
+	walletID string,
+	amount int64,
+	action entity.AdminAction,
+) (*entity.Wallet, error) {
+	tx, err := r.db.Pool.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("AdminRepo.AdjustBalance - r.Pool.Begin: %w", err)
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	wallets, err := r.wallet.lockWallets(ctx, tx, walletID)
+	if err != nil {
+		return nil, fmt.Errorf("AdminRepo.AdjustBalance - r.wallet.lockWallets: %w", err)
+	}
+
+	wallet := wallets[walletID]
+	before := wallet.Balance
+
+	if amount < 0 && uint(-amount) > wallet.Balance {
+		return nil, entity.ErrNotEnoughFunds
+	}
+
+	// The balance under the maximum doesn't overflow uint with any amount
+	if amount > 0 && wallet.Balance+uint(amount) > entity.MaxBalance {
+		return nil, entity.ErrBalanceTooLarge
+	}
+
+	sql, args, _ := r.db.Builder.
+		Update(tableWallets).
+		Set("balance", squirrel.Expr("balance + ?", amount)).
+		Where("id = ?", walletID).
+		Suffix("RETURNING balance").
+		ToSql()
+
+	err = tx.QueryRow(ctx, sql, args...).Scan(&wallet.Balance)
+	if err != nil {
+		return nil, fmt.Errorf("AdminRepo.AdjustBalance - tx.QueryRow: %w", err)
+	}
+
+	details, _ := json.Marshal(map[string]interface{}{
+		"amount": amount,
+	})
+
+	err = insertAudit(ctx, tx, r.db.Builder, &entity.AuditEntry{
+		Actor:     action.Actor,
+		Action:    entity.ActionBalanceAdjusted,
+		WalletID:  walletID,
+		RequestID: action.RequestID,
+		IP:        action.IP,
+		PayloadHash: entity.PayloadHash(entity.AdjustBalanceRequest{
+			WalletID: walletID,
+			Amount:   amount,
+			Action:   action,
+		}),
+		Balances: []entity.BalanceChange{
+			{WalletID: walletID, Before: before, After: wallet.Balance},
+		},
+		Reason:  action.Reason,
+		Details: details,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("AdminRepo.AdjustBalance - insertAudit: %w", err)
+	}
+
+	err = insertEvent(ctx, tx, r.db.Builder, entity.EventBalanceAdjusted, walletID, entity.BalanceAdjustedEvent{
+		Amount:  amount,
+		Balance: wallet.Balance,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("AdminRepo.AdjustBalance - insertEvent: %w", err)
+	}
+
+	err = tx.Commit(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("AdminRepo.AdjustBalance - tx.Commit: %w", err)
+	}
+
+	return wallet, nil
+}
+
+// SetWalletFrozen - freezing or unfreezing the wallet, frozen wallets can't send or receive funds.
+func (r *AdminRepo) SetWalletFrozen(
+	ctx context.Context,
+	walletID string,
+	frozen bool,
+	action entity.AdminAction,
+) (*entity.Wallet, error) {
+	tx, err := r.db.Pool.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("AdminRepo.SetWalletFrozen - r.Pool.Begin: %w", err)
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	wallets, err := r.wallet.lockWallets(ctx, tx, walletID)
+	if err != nil {
+		return nil, fmt.Errorf("AdminRepo.SetWalletFrozen - r.wallet.lockWallets: %w", err)
+	}
+
+	wallet := wallets[walletID]
+
+	sql, args, _ := r.db.Builder.
+		Update(tableWallets).
+		Set("frozen", frozen).
+		Where("id = ?", walletID).
+		ToSql()
+
+	_, err = tx.Exec(ctx, sql, args...)
+	if err != nil {
+		return nil, fmt.Errorf("AdminRepo.SetWalletFrozen - tx.Exec: %w", err)
+	}
+
+	auditAction := entity.ActionWalletUnfrozen
+	if frozen {
+		auditAction = entity.ActionWalletFrozen
+	}
+
+	details, _ := json.Marshal(map[string]interface{}{
+		"frozenBefore": wallet.Frozen,
+		"frozenAfter":  frozen,
+	})
+
+	err = insertAudit(ctx, tx, r.db.Builder, &entity.AuditEntry{
+		Actor:     action.Actor,
+		Action:    auditAction,
+		WalletID:  walletID,
+		RequestID: action.RequestID,
+		IP:        action.IP,
+		PayloadHash: entity.PayloadHash(entity.SetWalletFrozenRequest{
+			WalletID: walletID,
+			Frozen:   frozen,
+			Action:   action,
+		}),
+		Reason:  action.Reason,
+		Details: details,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("AdminRepo.SetWalletFrozen - insertAudit: %w", err)
+	}
+
+	err = tx.Commit(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("AdminRepo.SetWalletFrozen - tx.Commit: %w", err)
+	}
+
+	wallet.Frozen = frozen
+
+	return wallet, nil
+}
+
+// ReverseTransaction - returning funds of the transaction to the sender with a compensating transaction.
+// Frozen wallets don't block the reversal, a reversal itself can't be reversed.
+func (r *AdminRepo) ReverseTransaction(
+	ctx context.Context,
+	transactionID int64,
+	action entity.AdminAction,
+) (*entity.Transaction, error) {
+	tx, err := r.db.Pool.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("AdminRepo.ReverseTransaction - r.Pool.Begin: %w", err)
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	sql, args, _ := r.db.Builder.
+		Select("from_wallet_id, to_wallet_id, amount, reversal_of, reversed_at").
+		From(tableTransactions).
+		Where("id = ?", transactionID).
+		Suffix("FOR UPDATE").
+		ToSql()
+
+	var (
+		original   entity.Transaction
+		reversedAt *time.Time
+	)
+
+	err = tx.QueryRow(ctx, sql, args...).
+		Scan(&original.From, &original.To, &original.Amount, &original.ReversalOf, &reversedAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, entity.ErrTransactionNotFound
+		}
+		return nil, fmt.Errorf("AdminRepo.ReverseTransaction - tx.QueryRow: %w", err)
+	}
+
+	if reversedAt != nil {
+		return nil, entity.ErrAlreadyReversed
+	}
+
+	if original.ReversalOf != nil {
+		return nil, entity.ErrReversalNotReversible
+	}
+
+	wallets, err := r.wallet.lockWallets(ctx, tx, original.From, original.To)
+	if err != nil {
+		return nil, fmt.Errorf("AdminRepo.ReverseTransaction - r.wallet.lockWallets: %w", err)
+	}
+
+	if wallets[original.To].Balance < original.Amount {
+		return nil, entity.ErrNotEnoughFunds
+	}
+
+	reversal := &entity.Transaction{
+		From:       original.To,
+		To:         original.From,
+		Amount:     original.Amount,
+		ReversalOf: &transactionID,
+	}
+
+	err = r.wallet.moveFunds(ctx, tx, reversal, "")
+	if err != nil {
+		return nil, fmt.Errorf("AdminRepo.ReverseTransaction - r.wallet.moveFunds: %w", err)
+	}
+
+	sql, args, _ = r.db.Builder.
+		Update(tableTransactions).
+		Set("reversed_at", reversal.Time).
+		Where("id = ?", transactionID).
+		ToSql()
+
+	_, err = tx.Exec(ctx, sql, args...)
+	if err != nil {
+		return nil, fmt.Errorf("AdminRepo.ReverseTransaction - tx.Exec: %w", err)
+	}
+
+	details, _ := json.Marshal(map[string]interface{}{
+		"reversalId": reversal.ID,
+		"from":       original.From,
+		"to":         original.To,
+		"amount":     original.Amount,
+	})
+
+	from, to := wallets[original.From], wallets[original.To]
+
+	err = insertAudit(ctx, tx, r.db.Builder, &entity.AuditEntry{
+		Actor:         action.Actor,
+		Action:        entity.ActionTransactionReversed,
+		WalletID:      original.From,
+		TransactionID: transactionID,
+		RequestID:     action.RequestID,
+		IP:            action.IP,
+		PayloadHash: entity.PayloadHash(entity.ReverseTransactionRequest{
+			TransactionID: transactionID,
+			Action:        action,
+		}),
+		Balances: []entity.BalanceChange{
+			{WalletID: to.ID, Before: to.Balance, After: to.Balance - original.Amount},
+			{WalletID: from.ID, Before: from.Balance, After: from.Balance + original.Amount},
+		},
+		Reason:  action.Reason,
+		Details: details,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("AdminRepo.ReverseTransaction - insertAudit: %w", err)
+	}
+
+	err = insertFundsEvents(ctx, tx, r.db.Builder, reversal, to, from)
+	if err != nil {
+		return nil, fmt.Errorf("AdminRepo.ReverseTransaction - insertFundsEvents: %w", err)
+	}
+
+	err = tx.Commit(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("AdminRepo.ReverseTransaction - tx.Commit: %w", err)
+	}
+
+	return reversal, nil
+}
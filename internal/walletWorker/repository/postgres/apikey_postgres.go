@@ -0,0 +1,110 @@
+package worker_postgres
+
+import (
+	"WalletRieltaTestTask/internal/entity"
+	"WalletRieltaTestTask/pkg/postgres"
+	"context"
+	"errors"
+	"fmt"
+	"github.com/jackc/pgx/v5"
+	"time"
+)
+
+const (
+	tableAPIKeys      = "api_keys"
+	tableAPIKeyNonces = "api_key_nonces"
+)
+
+type APIKeyRepo struct {
+	db *postgres.Postgres
+}
+
+func NewAPIKeyRepo(pg *postgres.Postgres) *APIKeyRepo {
+	return &APIKeyRepo{pg}
+}
+
+// CreateAPIKey - creating new api key entry in the db.
+func (r *APIKeyRepo) CreateAPIKey(ctx context.Context, key *entity.APIKey) (*entity.APIKey, error) {
+	sql, args, _ := r.db.Builder.
+		Insert(tableAPIKeys).
+		Columns("id", "name", "key_hash", "signing_secret", "scopes", "roles", "require_signature").
+		Values(key.ID, key.Name, key.Hash, key.SealedSigningSecret, key.Scopes, key.Roles, key.RequireSignature).
+		Suffix("RETURNING created_at").
+		ToSql()
+
+	err := r.db.Pool.QueryRow(ctx, sql, args...).Scan(&key.CreatedAt)
+	if err != nil {
+		return nil, fmt.Errorf("APIKeyRepo.CreateAPIKey - r.Pool.QueryRow: %w", err)
+	}
+
+	return key, nil
+}
+
+// GetAPIKeyByID - getting api key by its public id.
+func (r *APIKeyRepo) GetAPIKeyByID(ctx context.Context, id string) (*entity.APIKey, error) {
+	sql, args, _ := r.db.Builder.
+		Select("id, name, key_hash, signing_secret, scopes, roles, require_signature, created_at, revoked_at").
+		From(tableAPIKeys).
+		Where("id = ?", id).
+		ToSql()
+
+	key := new(entity.APIKey)
+	err := r.db.Pool.QueryRow(ctx, sql, args...).Scan(
+		&key.ID,
+		&key.Name,
+		&key.Hash,
+		&key.SealedSigningSecret,
+		&key.Scopes,
+		&key.Roles,
+		&key.RequireSignature,
+		&key.CreatedAt,
+		&key.RevokedAt,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, entity.ErrNotFound
+		}
+		return nil, fmt.Errorf("APIKeyRepo.GetAPIKeyByID - r.Pool.QueryRow: %w", err)
+	}
+
+	return key, nil
+}
+
+// RegisterNonce - saving the nonce of the signed request.
+// Returns false if the nonce was already used by the key and has not expired yet.
+func (r *APIKeyRepo) RegisterNonce(ctx context.Context, keyID, nonce string, expiresAt time.Time) (bool, error) {
+	tx, err := r.db.Pool.Begin(ctx)
+	if err != nil {
+		return false, fmt.Errorf("APIKeyRepo.RegisterNonce - r.Pool.Begin: %w", err)
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	sql, args, _ := r.db.Builder.
+		Delete(tableAPIKeyNonces).
+		Where("key_id = ? AND expires_at < now()", keyID).
+		ToSql()
+
+	_, err = tx.Exec(ctx, sql, args...)
+	if err != nil {
+		return false, fmt.Errorf("APIKeyRepo.RegisterNonce - tx.Exec: %w", err)
+	}
+
+	sql, args, _ = r.db.Builder.
+		Insert(tableAPIKeyNonces).
+		Columns("key_id", "nonce", "expires_at").
+		Values(keyID, nonce, expiresAt).
+		Suffix("ON CONFLICT DO NOTHING").
+		ToSql()
+
+	tag, err := tx.Exec(ctx, sql, args...)
+	if err != nil {
+		return false, fmt.Errorf("APIKeyRepo.RegisterNonce - tx.Exec: %w", err)
+	}
+
+	err = tx.Commit(ctx)
+	if err != nil {
+		return false, fmt.Errorf("APIKeyRepo.RegisterNonce - tx.Commit: %w", err)
+	}
+
+	return tag.RowsAffected() == 1, nil
+}
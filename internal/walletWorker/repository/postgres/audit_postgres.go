@@ -0,0 +1,284 @@
+package worker_postgres
+
+import (
+	"WalletRieltaTestTask/internal/entity"
+	"WalletRieltaTestTask/pkg/postgres"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/Masterminds/squirrel"
+	"github.com/jackc/pgx/v5"
+	"time"
+)
+
+const (
+	tableAuditLog = "audit_log"
+
+	// Key of the advisory lock serializing appends to the hash chain.
+	_auditChainLock = 7_310_001
+
+	auditColumns = "id, time, actor, action, COALESCE(wallet_id, ''), COALESCE(transaction_id, 0), " +
+		"request_id, ip, payload_hash, balances, reason, details, prev_hash, hash"
+)
+
+type AuditRepo struct {
+	db *postgres.Postgres
+}
+
+func NewAuditRepo(pg *postgres.Postgres) *AuditRepo {
+	return &AuditRepo{pg}
+}
+
+// insertAudit - appending an audit log entry inside the transaction of the audited operation.
+// Appends are serialized until the transaction ends, so the entry is always chained to the latest one.
+func insertAudit(ctx context.Context, tx pgx.Tx, builder squirrel.StatementBuilderType, entry *entity.AuditEntry) error {
+	_, err := tx.Exec(ctx, "SELECT pg_advisory_xact_lock($1)", _auditChainLock)
+	if err != nil {
+		return fmt.Errorf("insertAudit - tx.Exec: %w", err)
+	}
+
+	sql, args, _ := builder.
+		Select("hash").
+		From(tableAuditLog).
+		OrderBy("id DESC").
+		Limit(1).
+		ToSql()
+
+	err = tx.QueryRow(ctx, sql, args...).Scan(&entry.PrevHash)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return fmt.Errorf("insertAudit - tx.QueryRow: %w", err)
+	}
+
+	if entry.Details == nil {
+		entry.Details = json.RawMessage("{}")
+	}
+	if entry.Balances == nil {
+		entry.Balances = []entity.BalanceChange{}
+	}
+
+	// Postgres keeps microseconds, the hash must match the stored time
+	entry.Time = time.Now().UTC().Truncate(time.Microsecond)
+
+	entry.Hash, err = entry.ComputeHash()
+	if err != nil {
+		return fmt.Errorf("insertAudit - entry.ComputeHash: %w", err)
+	}
+
+	var (
+		walletID      *string
+		transactionID *int64
+	)
+
+	if entry.WalletID != "" {
+		walletID = &entry.WalletID
+	}
+	if entry.TransactionID != 0 {
+		transactionID = &entry.TransactionID
+	}
+
+	balances, _ := json.Marshal(entry.Balances)
+
+	sql, args, _ = builder.
+		Insert(tableAuditLog).
+		Columns(
+			"time", "actor", "action", "wallet_id", "transaction_id", "request_id", "ip",
+			"payload_hash", "balances", "reason", "details", "prev_hash", "hash",
+		).
+		Values(
+			entry.Time, entry.Actor, entry.Action, walletID, transactionID, entry.RequestID, entry.IP,
+			entry.PayloadHash, string(balances), entry.Reason, string(entry.Details), entry.PrevHash, entry.Hash,
+		).
+		Suffix("RETURNING id").
+		ToSql()
+
+	err = tx.QueryRow(ctx, sql, args...).Scan(&entry.ID)
+	if err != nil {
+		return fmt.Errorf("insertAudit - tx.QueryRow: %w", err)
+	}
+
+	return nil
+}
+
+// SearchAudit - getting audit log entries matching the filter ordered by id.
+func (r *AuditRepo) SearchAudit(ctx context.Context, filter entity.AuditFilter) ([]entity.AuditEntry, error) {
+	limit := filter.Limit
+	if limit == 0 || limit > _maxSearchLimit {
+		limit = _defaultSearchLimit
+	}
+
+	query := r.db.Builder.
+		Select(auditColumns).
+		From(tableAuditLog).
+		OrderBy("id").
+		Limit(uint64(limit)).
+		Offset(uint64(filter.Offset))
+
+	if filter.WalletID != "" {
+		query = query.Where("wallet_id = ?", filter.WalletID)
+	}
+	if filter.TransactionID != 0 {
+		query = query.Where("transaction_id = ?", filter.TransactionID)
+	}
+	if filter.Actor != "" {
+		query = query.Where("actor = ?", filter.Actor)
+	}
+	if filter.Action != "" {
+		query = query.Where("action = ?", filter.Action)
+	}
+	if filter.RequestID != "" {
+		query = query.Where("request_id = ?", filter.RequestID)
+	}
+	if filter.From != nil {
+		query = query.Where("time >= ?", *filter.From)
+	}
+	if filter.To != nil {
+		query = query.Where("time < ?", *filter.To)
+	}
+
+	sql, args, _ := query.ToSql()
+
+	rows, err := r.db.Pool.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, fmt.Errorf("AuditRepo.SearchAudit - r.Pool.Query: %w", err)
+	}
+	defer rows.Close()
+
+	entries := make([]entity.AuditEntry, 0)
+
+	for rows.Next() {
+		entry, err := scanAudit(rows)
+		if err != nil {
+			return nil, fmt.Errorf("AuditRepo.SearchAudit - scanAudit: %w", err)
+		}
+		entries = append(entries, *entry)
+	}
+
+	return entries, nil
+}
+
+// VerifyAuditChain - recomputing hashes of all entries in the append order.
+// Entries written before the chain was introduced have no hash, they are counted as legacy
+// and the chain is verified from the first hashed entry. A legacy entry after it breaks the chain.
+// The saved head of the previous verification must be in the chain, otherwise the latest entries were removed.
+func (r *AuditRepo) VerifyAuditChain(ctx context.Context, savedHead string) (entity.AuditChainReport, error) {
+	var report entity.AuditChainReport
+
+	sql, args, _ := r.db.Builder.
+		Select(auditColumns).
+		From(tableAuditLog).
+		OrderBy("id").
+		ToSql()
+
+	rows, err := r.db.Pool.Query(ctx, sql, args...)
+	if err != nil {
+		return report, fmt.Errorf("AuditRepo.VerifyAuditChain - r.Pool.Query: %w", err)
+	}
+	defer rows.Close()
+
+	chain := newAuditChain(savedHead)
+
+	for rows.Next() {
+		entry, err := scanAudit(rows)
+		if err != nil {
+			return chain.report, fmt.Errorf("AuditRepo.VerifyAuditChain - scanAudit: %w", err)
+		}
+
+		if err = chain.append(entry); err != nil {
+			return chain.report, fmt.Errorf("AuditRepo.VerifyAuditChain - chain.append: %w", err)
+		}
+	}
+
+	if err = rows.Err(); err != nil {
+		return chain.report, fmt.Errorf("AuditRepo.VerifyAuditChain - rows.Err: %w", err)
+	}
+
+	if err = chain.finish(); err != nil {
+		return chain.report, fmt.Errorf("AuditRepo.VerifyAuditChain - chain.finish: %w", err)
+	}
+
+	return chain.report, nil
+}
+
+// auditChain - verification of the entries read one by one in the append order.
+type auditChain struct {
+	report    entity.AuditChainReport
+	savedHead string
+	headFound bool
+}
+
+func newAuditChain(savedHead string) *auditChain {
+	return &auditChain{savedHead: savedHead, headFound: savedHead == ""}
+}
+
+func (c *auditChain) append(entry *entity.AuditEntry) error {
+	if entry.Hash == "" && c.report.Checked == 0 {
+		c.report.Legacy++
+
+		return nil
+	}
+
+	if entry.PrevHash != c.report.Head {
+		return fmt.Errorf("entry %d doesn't follow the previous one: %w", entry.ID, entity.ErrAuditChainBroken)
+	}
+
+	hash, err := entry.ComputeHash()
+	if err != nil {
+		return fmt.Errorf("entry.ComputeHash: %w", err)
+	}
+
+	if hash != entry.Hash {
+		return fmt.Errorf("entry %d was modified: %w", entry.ID, entity.ErrAuditChainBroken)
+	}
+
+	c.report.Head = entry.Hash
+	c.headFound = c.headFound || c.report.Head == c.savedHead
+	c.report.Checked++
+
+	return nil
+}
+
+// The saved head must have been passed.
+func (c *auditChain) finish() error {
+	if !c.headFound {
+		return fmt.Errorf("saved head %s is missing: %w", c.savedHead, entity.ErrAuditChainBroken)
+	}
+
+	return nil
+}
+
+func scanAudit(rows pgx.Rows) (*entity.AuditEntry, error) {
+	var (
+		entry    entity.AuditEntry
+		balances []byte
+		details  []byte
+	)
+
+	err := rows.Scan(
+		&entry.ID,
+		&entry.Time,
+		&entry.Actor,
+		&entry.Action,
+		&entry.WalletID,
+		&entry.TransactionID,
+		&entry.RequestID,
+		&entry.IP,
+		&entry.PayloadHash,
+		&balances,
+		&entry.Reason,
+		&details,
+		&entry.PrevHash,
+		&entry.Hash,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("rows.Scan: %w", err)
+	}
+
+	if err = json.Unmarshal(balances, &entry.Balances); err != nil {
+		return nil, fmt.Errorf("json.Unmarshal: %w", err)
+	}
+
+	entry.Details = details
+
+	return &entry, nil
+}
@@ -0,0 +1,117 @@
+package worker_postgres
+
+import (
+	"WalletRieltaTestTask/internal/entity"
+	"errors"
+	"strconv"
+	"testing"
+	"time"
+)
+
+// Two legacy entries without hashes followed by the chained ones, as they are stored.
+func auditLog(t *testing.T, chained int) []*entity.AuditEntry {
+	t.Helper()
+
+	entries := []*entity.AuditEntry{
+		{ID: 1, Actor: "legacy", Action: entity.ActionWalletCreated},
+		{ID: 2, Actor: "legacy", Action: entity.ActionFundsSent},
+	}
+
+	var prevHash string
+
+	for i := 0; i < chained; i++ {
+		entry := &entity.AuditEntry{
+			ID:       int64(len(entries) + 1),
+			Time:     time.Date(2024, 5, 1, 12, 0, i, 0, time.UTC),
+			Actor:    "key:key-1",
+			Action:   entity.ActionFundsSent,
+			WalletID: "wallet-" + strconv.Itoa(i),
+			Balances: []entity.BalanceChange{{WalletID: "wallet-" + strconv.Itoa(i), Before: 100, After: 70}},
+			Details:  []byte("{}"),
+			PrevHash: prevHash,
+		}
+
+		hash, err := entry.ComputeHash()
+		if err != nil {
+			t.Fatalf("ComputeHash: %v", err)
+		}
+
+		entry.Hash, prevHash = hash, hash
+		entries = append(entries, entry)
+	}
+
+	return entries
+}
+
+func verify(entries []*entity.AuditEntry, savedHead string) (entity.AuditChainReport, error) {
+	chain := newAuditChain(savedHead)
+
+	for _, entry := range entries {
+		if err := chain.append(entry); err != nil {
+			return chain.report, err
+		}
+	}
+
+	return chain.report, chain.finish()
+}
+
+func TestVerifyAuditChain(t *testing.T) {
+	entries := auditLog(t, 3)
+	head := entries[len(entries)-1].Hash
+
+	report, err := verify(entries, entries[3].Hash)
+	if err != nil {
+		t.Fatalf("verify: %v", err)
+	}
+
+	if report != (entity.AuditChainReport{Checked: 3, Legacy: 2, Head: head}) {
+		t.Fatalf("report = %+v, want 3 checked and 2 legacy entries", report)
+	}
+
+	// The first verification has no saved head
+	if _, err = verify(entries, ""); err != nil {
+		t.Fatalf("verify without the saved head: %v", err)
+	}
+}
+
+func TestVerifyAuditChainBroken(t *testing.T) {
+	tests := []struct {
+		name   string
+		change func(entries []*entity.AuditEntry) ([]*entity.AuditEntry, string)
+	}{
+		{"modified entry", func(entries []*entity.AuditEntry) ([]*entity.AuditEntry, string) {
+			entries[3].Balances[0].After = 700
+
+			return entries, ""
+		}},
+		{"modified and rehashed entry", func(entries []*entity.AuditEntry) ([]*entity.AuditEntry, string) {
+			entries[3].Actor = "key:key-2"
+			entries[3].Hash, _ = entries[3].ComputeHash()
+
+			return entries, ""
+		}},
+		{"deleted entry", func(entries []*entity.AuditEntry) ([]*entity.AuditEntry, string) {
+			return append(entries[:3], entries[4:]...), ""
+		}},
+		{"deleted tail", func(entries []*entity.AuditEntry) ([]*entity.AuditEntry, string) {
+			head := entries[len(entries)-1].Hash
+
+			return entries[:len(entries)-1], head
+		}},
+		{"legacy entry after the chain starts", func(entries []*entity.AuditEntry) ([]*entity.AuditEntry, string) {
+			legacy := &entity.AuditEntry{ID: int64(len(entries) + 1), Actor: "legacy", Action: entity.ActionFundsSent}
+
+			return append(entries, legacy), ""
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			entries, savedHead := tt.change(auditLog(t, 3))
+
+			if _, err := verify(entries, savedHead); !errors.Is(err, entity.ErrAuditChainBroken) {
+				t.Fatalf("verify = %v, want %v", err, entity.ErrAuditChainBroken)
+			}
+		})
+	}
+}
@@ -0,0 +1,258 @@
+package worker_postgres
+
+import (
+	"WalletRieltaTestTask/internal/entity"
+	"WalletRieltaTestTask/pkg/postgres"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/Masterminds/squirrel"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"time"
+)
+
+const tableOutbox = "outbox"
+
+type OutboxRepo struct {
+	db *postgres.Postgres
+}
+
+func NewOutboxRepo(pg *postgres.Postgres) *OutboxRepo {
+	return &OutboxRepo{pg}
+}
+
+// insertEvent - adding an event to the outbox and its webhook deliveries inside the transaction
+// of the operation, so the event is published only if the operation is committed.
+func insertEvent(
+	ctx context.Context,
+	tx pgx.Tx,
+	builder squirrel.StatementBuilderType,
+	eventType string,
+	walletID string,
+	data interface{},
+) error {
+	payload, err := json.Marshal(data)
+	if err != nil {
+		return fmt.Errorf("insertEvent - json.Marshal: %w", err)
+	}
+
+	eventID := uuid.New().String()
+
+	sql, args, _ := builder.
+		Insert(tableOutbox).
+		Columns("event_id", "type", "wallet_id", "data").
+		Values(eventID, eventType, walletID, string(payload)).
+		ToSql()
+
+	_, err = tx.Exec(ctx, sql, args...)
+	if err != nil {
+		return fmt.Errorf("insertEvent - tx.Exec: %w", err)
+	}
+
+	return insertWebhookDeliveries(ctx, tx, builder, eventID, eventType, walletID)
+}
+
+// insertFundsEvents - adding funds.sent and funds.received events of the transaction.
+// Balances are taken from the wallets locked before the transfer.
+func insertFundsEvents(
+	ctx context.Context,
+	tx pgx.Tx,
+	builder squirrel.StatementBuilderType,
+	transaction *entity.Transaction,
+	from, to *entity.Wallet,
+) error {
+	event := entity.FundsEvent{
+		TransactionID: transaction.ID,
+		From:          transaction.From,
+		To:            transaction.To,
+		Amount:        transaction.Amount,
+		Balance:       from.Balance - transaction.Amount,
+		ReversalOf:    transaction.ReversalOf,
+	}
+
+	err := insertEvent(ctx, tx, builder, entity.EventFundsSent, transaction.From, event)
+	if err != nil {
+		return err
+	}
+
+	event.Balance = to.Balance + transaction.Amount
+
+	return insertEvent(ctx, tx, builder, entity.EventFundsReceived, transaction.To, event)
+}
+
+// PublishPending - passing unpublished events to publish in the order they were added.
+// Events are locked until the end of the batch, so concurrent relays skip them.
+// An event is marked as published only after publish returns, so it may be published more than once.
+func (r *OutboxRepo) PublishPending(
+	ctx context.Context,
+	limit uint,
+	publish func(ctx context.Context, event *entity.Event) error,
+) (int, error) {
+	tx, err := r.db.Pool.Begin(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("OutboxRepo.PublishPending - r.Pool.Begin: %w", err)
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	sql, args, _ := r.db.Builder.
+		Select("id, event_id, type, wallet_id, data, occurred_at").
+		From(tableOutbox).
+		Where("published_at IS NULL").
+		OrderBy("id").
+		Limit(uint64(limit)).
+		Suffix("FOR UPDATE SKIP LOCKED").
+		ToSql()
+
+	rows, err := tx.Query(ctx, sql, args...)
+	if err != nil {
+		return 0, fmt.Errorf("OutboxRepo.PublishPending - tx.Query: %w", err)
+	}
+
+	var (
+		ids    []int64
+		events []*entity.Event
+	)
+
+	for rows.Next() {
+		var (
+			id    int64
+			event entity.Event
+			data  []byte
+		)
+
+		err = rows.Scan(&id, &event.ID, &event.Type, &event.WalletID, &data, &event.OccurredAt)
+		if err != nil {
+			rows.Close()
+			return 0, fmt.Errorf("OutboxRepo.PublishPending - rows.Scan: %w", err)
+		}
+
+		event.Data = data
+		ids = append(ids, id)
+		events = append(events, &event)
+	}
+	rows.Close()
+
+	if err = rows.Err(); err != nil {
+		return 0, fmt.Errorf("OutboxRepo.PublishPending - rows.Err: %w", err)
+	}
+
+	// Events after the failed one stay in the outbox to keep the order
+	published := 0
+	var publishErr error
+
+	for _, event := range events {
+		if publishErr = publish(ctx, event); publishErr != nil {
+			break
+		}
+		published++
+	}
+
+	if published > 0 {
+		sql, args, _ = r.db.Builder.
+			Update(tableOutbox).
+			Set("published_at", time.Now()).
+			Where(squirrel.Eq{"id": ids[:published]}).
+			ToSql()
+
+		_, err = tx.Exec(ctx, sql, args...)
+		if err != nil {
+			return 0, fmt.Errorf("OutboxRepo.PublishPending - tx.Exec: %w", err)
+		}
+
+		err = tx.Commit(ctx)
+		if err != nil {
+			return 0, fmt.Errorf("OutboxRepo.PublishPending - tx.Commit: %w", err)
+		}
+	}
+
+	if publishErr != nil {
+		return published, fmt.Errorf("OutboxRepo.PublishPending - publish: %w", publishErr)
+	}
+
+	return published, nil
+}
+
+// ListWalletEvents - getting events of the wallet added after the event in the order they were added.
+// Transfers lock the wallet row, so events of one wallet are added in the commit order
+// and no event committed later can appear before the one already read.
+func (r *WalletRepo) ListWalletEvents(ctx context.Context, walletID, after string, limit uint) ([]entity.Event, error) {
+	if limit == 0 || limit > _maxSearchLimit {
+		limit = _maxSearchLimit
+	}
+
+	query := r.db.Builder.
+		Select("event_id, type, wallet_id, data, occurred_at").
+		From(tableOutbox).
+		Where("wallet_id = ?", walletID).
+		OrderBy("id").
+		Limit(uint64(limit))
+
+	if after != "" {
+		afterID, err := r.eventSeq(ctx, walletID, after)
+		if err != nil {
+			return nil, fmt.Errorf("WalletRepo.ListWalletEvents - r.eventSeq: %w", err)
+		}
+
+		query = query.Where("id > ?", afterID)
+	}
+
+	sql, args, _ := query.ToSql()
+
+	rows, err := r.db.Pool.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, fmt.Errorf("WalletRepo.ListWalletEvents - r.Pool.Query: %w", err)
+	}
+	defer rows.Close()
+
+	events := make([]entity.Event, 0)
+
+	for rows.Next() {
+		var (
+			event entity.Event
+			data  []byte
+		)
+
+		err = rows.Scan(&event.ID, &event.Type, &event.WalletID, &data, &event.OccurredAt)
+		if err != nil {
+			return nil, fmt.Errorf("WalletRepo.ListWalletEvents - rows.Scan: %w", err)
+		}
+
+		event.Data = data
+		events = append(events, event)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("WalletRepo.ListWalletEvents - rows.Err: %w", err)
+	}
+
+	return events, nil
+}
+
+// Getting the outbox position of the wallet event.
+func (r *WalletRepo) eventSeq(ctx context.Context, walletID, eventID string) (int64, error) {
+	if _, err := uuid.Parse(eventID); err != nil {
+		return 0, entity.ErrEventNotFound
+	}
+
+	sql, args, _ := r.db.Builder.
+		Select("id").
+		From(tableOutbox).
+		Where("event_id = ?", eventID).
+		Where("wallet_id = ?", walletID).
+		ToSql()
+
+	var id int64
+
+	err := r.db.Pool.QueryRow(ctx, sql, args...).Scan(&id)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return 0, entity.ErrEventNotFound
+		}
+
+		return 0, fmt.Errorf("r.Pool.QueryRow: %w", err)
+	}
+
+	return id, nil
+}
@@ -0,0 +1,357 @@
+package worker_postgres
+
+import (
+	"WalletRieltaTestTask/internal/entity"
+	"WalletRieltaTestTask/pkg/postgres"
+	"context"
+	"errors"
+	"fmt"
+	"github.com/Masterminds/squirrel"
+	"github.com/jackc/pgx/v5"
+	"time"
+)
+
+const (
+	tableWebhooks          = "webhooks"
+	tableWebhookDeliveries = "webhook_deliveries"
+
+	deliveryColumns = "d.id, d.webhook_id, d.event_id, o.type, d.status, d.attempts, COALESCE(d.response_status, 0), " +
+		"d.last_error, d.next_attempt_at, d.created_at, d.delivered_at"
+)
+
+type WebhookRepo struct {
+	db *postgres.Postgres
+}
+
+func NewWebhookRepo(pg *postgres.Postgres) *WebhookRepo {
+	return &WebhookRepo{pg}
+}
+
+// insertWebhookDeliveries - adding deliveries of the outbox event to the matching webhooks
+// inside the transaction of the operation.
+func insertWebhookDeliveries(
+	ctx context.Context,
+	tx pgx.Tx,
+	builder squirrel.StatementBuilderType,
+	eventID string,
+	eventType string,
+	walletID string,
+) error {
+	subscribers := squirrel.
+		Select("id").
+		Column(squirrel.Expr("?::uuid", eventID)).
+		From(tableWebhooks).
+		Where("deleted_at IS NULL").
+		Where("(events = '{}' OR ? = ANY(events))", eventType).
+		Where("(owner_id IS NULL OR owner_id = (SELECT owner_id FROM wallets WHERE id = ?))", walletID)
+
+	sql, args, _ := builder.
+		Insert(tableWebhookDeliveries).
+		Columns("webhook_id", "event_id").
+		Select(subscribers).
+		ToSql()
+
+	_, err := tx.Exec(ctx, sql, args...)
+	if err != nil {
+		return fmt.Errorf("insertWebhookDeliveries - tx.Exec: %w", err)
+	}
+
+	return nil
+}
+
+// CreateWebhook - adding the webhook subscription.
+func (r *WebhookRepo) CreateWebhook(ctx context.Context, webhook *entity.Webhook) (*entity.Webhook, error) {
+	var ownerID *string
+	if webhook.OwnerID != "" {
+		ownerID = &webhook.OwnerID
+	}
+
+	sql, args, _ := r.db.Builder.
+		Insert(tableWebhooks).
+		Columns("url", "secret", "events", "owner_id", "created_by").
+		Values(webhook.URL, webhook.Secret, webhook.Events, ownerID, webhook.CreatedBy).
+		Suffix("RETURNING id, created_at").
+		ToSql()
+
+	err := r.db.Pool.QueryRow(ctx, sql, args...).Scan(&webhook.ID, &webhook.CreatedAt)
+	if err != nil {
+		return nil, fmt.Errorf("WebhookRepo.CreateWebhook - r.Pool.QueryRow: %w", err)
+	}
+
+	return webhook, nil
+}
+
+// ListWebhooks - getting webhooks created by the actor, secrets are not returned.
+func (r *WebhookRepo) ListWebhooks(ctx context.Context, createdBy string) ([]entity.Webhook, error) {
+	sql, args, _ := r.db.Builder.
+		Select("id, url, events, COALESCE(owner_id, ''), created_by, created_at").
+		From(tableWebhooks).
+		Where("created_by = ? AND deleted_at IS NULL", createdBy).
+		OrderBy("created_at").
+		ToSql()
+
+	rows, err := r.db.Pool.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, fmt.Errorf("WebhookRepo.ListWebhooks - r.Pool.Query: %w", err)
+	}
+	defer rows.Close()
+
+	webhooks := make([]entity.Webhook, 0)
+
+	for rows.Next() {
+		var webhook entity.Webhook
+		err = rows.Scan(
+			&webhook.ID,
+			&webhook.URL,
+			&webhook.Events,
+			&webhook.OwnerID,
+			&webhook.CreatedBy,
+			&webhook.CreatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("WebhookRepo.ListWebhooks - rows.Scan: %w", err)
+		}
+		webhooks = append(webhooks, webhook)
+	}
+
+	return webhooks, nil
+}
+
+// GetWebhook - getting the webhook created by the actor, secret is not returned.
+func (r *WebhookRepo) GetWebhook(ctx context.Context, id, createdBy string) (*entity.Webhook, error) {
+	sql, args, _ := r.db.Builder.
+		Select("id, url, events, COALESCE(owner_id, ''), created_by, created_at").
+		From(tableWebhooks).
+		Where("id = ? AND created_by = ? AND deleted_at IS NULL", id, createdBy).
+		ToSql()
+
+	webhook := new(entity.Webhook)
+	err := r.db.Pool.QueryRow(ctx, sql, args...).Scan(
+		&webhook.ID,
+		&webhook.URL,
+		&webhook.Events,
+		&webhook.OwnerID,
+		&webhook.CreatedBy,
+		&webhook.CreatedAt,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, entity.ErrWebhookNotFound
+		}
+		return nil, fmt.Errorf("WebhookRepo.GetWebhook - r.Pool.QueryRow: %w", err)
+	}
+
+	return webhook, nil
+}
+
+// DeleteWebhook - removing the subscription, its pending deliveries are canceled.
+// Deliveries stay in the log.
+func (r *WebhookRepo) DeleteWebhook(ctx context.Context, id, createdBy string) error {
+	tx, err := r.db.Pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("WebhookRepo.DeleteWebhook - r.Pool.Begin: %w", err)
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	sql, args, _ := r.db.Builder.
+		Update(tableWebhooks).
+		Set("deleted_at", squirrel.Expr("CURRENT_TIMESTAMP")).
+		Where("id = ? AND created_by = ? AND deleted_at IS NULL", id, createdBy).
+		ToSql()
+
+	tag, err := tx.Exec(ctx, sql, args...)
+	if err != nil {
+		return fmt.Errorf("WebhookRepo.DeleteWebhook - tx.Exec: %w", err)
+	}
+
+	if tag.RowsAffected() == 0 {
+		return entity.ErrWebhookNotFound
+	}
+
+	sql, args, _ = r.db.Builder.
+		Update(tableWebhookDeliveries).
+		Set("status", entity.DeliveryCanceled).
+		Where("webhook_id = ? AND status = ?", id, entity.DeliveryPending).
+		ToSql()
+
+	_, err = tx.Exec(ctx, sql, args...)
+	if err != nil {
+		return fmt.Errorf("WebhookRepo.DeleteWebhook - tx.Exec: %w", err)
+	}
+
+	err = tx.Commit(ctx)
+	if err != nil {
+		return fmt.Errorf("WebhookRepo.DeleteWebhook - tx.Commit: %w", err)
+	}
+
+	return nil
+}
+
+// ListDeliveries - getting the delivery log of the webhook, the latest deliveries first.
+func (r *WebhookRepo) ListDeliveries(
+	ctx context.Context,
+	webhookID string,
+	createdBy string,
+	limit uint,
+	offset uint,
+) ([]entity.WebhookDelivery, error) {
+	if limit == 0 || limit > _maxSearchLimit {
+		limit = _defaultSearchLimit
+	}
+
+	sql, args, _ := r.db.Builder.
+		Select(deliveryColumns).
+		From(tableWebhookDeliveries+" d").
+		Join(tableOutbox+" o ON o.event_id = d.event_id").
+		Join(tableWebhooks+" w ON w.id = d.webhook_id").
+		Where("d.webhook_id = ? AND w.created_by = ?", webhookID, createdBy).
+		OrderBy("d.id DESC").
+		Limit(uint64(limit)).
+		Offset(uint64(offset)).
+		ToSql()
+
+	rows, err := r.db.Pool.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, fmt.Errorf("WebhookRepo.ListDeliveries - r.Pool.Query: %w", err)
+	}
+	defer rows.Close()
+
+	deliveries := make([]entity.WebhookDelivery, 0)
+
+	for rows.Next() {
+		var delivery entity.WebhookDelivery
+		err = rows.Scan(
+			&delivery.ID,
+			&delivery.WebhookID,
+			&delivery.EventID,
+			&delivery.EventType,
+			&delivery.Status,
+			&delivery.Attempts,
+			&delivery.ResponseStatus,
+			&delivery.LastError,
+			&delivery.NextAttemptAt,
+			&delivery.CreatedAt,
+			&delivery.DeliveredAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("WebhookRepo.ListDeliveries - rows.Scan: %w", err)
+		}
+
+		if delivery.Status != entity.DeliveryPending {
+			delivery.NextAttemptAt = nil
+		}
+
+		deliveries = append(deliveries, delivery)
+	}
+
+	return deliveries, nil
+}
+
+// ClaimDeliveries - taking due deliveries and postponing them for the lease time,
+// so other dispatchers don't send them. Deliveries of a crashed dispatcher are retried after the lease.
+func (r *WebhookRepo) ClaimDeliveries(
+	ctx context.Context,
+	limit uint,
+	lease time.Duration,
+) ([]entity.WebhookDispatch, error) {
+	// Placeholders of the subquery are numbered with the outer query
+	due := squirrel.
+		Select("id").
+		From(tableWebhookDeliveries).
+		Where("status = ? AND next_attempt_at <= CURRENT_TIMESTAMP", entity.DeliveryPending).
+		OrderBy("next_attempt_at").
+		Limit(uint64(limit)).
+		Suffix("FOR UPDATE SKIP LOCKED")
+
+	dueSQL, dueArgs, _ := due.ToSql()
+
+	sql, args, _ := r.db.Builder.
+		Update(tableWebhookDeliveries+" d").
+		Set("next_attempt_at", squirrel.Expr("CURRENT_TIMESTAMP + make_interval(secs => ?)", lease.Seconds())).
+		From(tableWebhooks+" w, "+tableOutbox+" o").
+		Where("d.id IN ("+dueSQL+")", dueArgs...).
+		Where("w.id = d.webhook_id AND o.event_id = d.event_id").
+		Suffix("RETURNING " + deliveryColumns + ", w.url, w.secret, o.wallet_id, o.data, o.occurred_at").
+		ToSql()
+
+	rows, err := r.db.Pool.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, fmt.Errorf("WebhookRepo.ClaimDeliveries - r.Pool.Query: %w", err)
+	}
+	defer rows.Close()
+
+	var dispatches []entity.WebhookDispatch
+
+	for rows.Next() {
+		var (
+			dispatch entity.WebhookDispatch
+			data     []byte
+		)
+
+		d := &dispatch.Delivery
+		err = rows.Scan(
+			&d.ID,
+			&d.WebhookID,
+			&d.EventID,
+			&d.EventType,
+			&d.Status,
+			&d.Attempts,
+			&d.ResponseStatus,
+			&d.LastError,
+			&d.NextAttemptAt,
+			&d.CreatedAt,
+			&d.DeliveredAt,
+			&dispatch.URL,
+			&dispatch.Secret,
+			&dispatch.Event.WalletID,
+			&data,
+			&dispatch.Event.OccurredAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("WebhookRepo.ClaimDeliveries - rows.Scan: %w", err)
+		}
+
+		dispatch.Event.ID = d.EventID
+		dispatch.Event.Type = d.EventType
+		dispatch.Event.Data = data
+
+		dispatches = append(dispatches, dispatch)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("WebhookRepo.ClaimDeliveries - rows.Err: %w", err)
+	}
+
+	return dispatches, nil
+}
+
+// SaveAttempt - recording the result of the delivery attempt.
+// The delivery is retried at nextAttemptAt while it's pending.
+func (r *WebhookRepo) SaveAttempt(ctx context.Context, delivery *entity.WebhookDelivery) error {
+	var responseStatus *int
+	if delivery.ResponseStatus != 0 {
+		responseStatus = &delivery.ResponseStatus
+	}
+
+	query := r.db.Builder.
+		Update(tableWebhookDeliveries).
+		Set("status", delivery.Status).
+		Set("attempts", delivery.Attempts).
+		Set("response_status", responseStatus).
+		Set("last_error", delivery.LastError).
+		Set("delivered_at", delivery.DeliveredAt).
+		Where("id = ? AND status = ?", delivery.ID, entity.DeliveryPending)
+
+	if delivery.NextAttemptAt != nil {
+		query = query.Set("next_attempt_at", *delivery.NextAttemptAt)
+	}
+
+	sql, args, _ := query.ToSql()
+
+	_, err := r.db.Pool.Exec(ctx, sql, args...)
+	if err != nil {
+		return fmt.Errorf("WebhookRepo.SaveAttempt - r.Pool.Exec: %w", err)
+	}
+
+	return nil
+}
@@ -8,13 +8,22 @@ import (
 	"fmt"
 	"github.com/Masterminds/squirrel"
 	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
 )
 
 const (
 	tableWallets      = "wallets"
 	tableTransactions = "transactions"
+
+	// indexIdempotencyKey - the unique (from_wallet_id, idempotency_key) index of the transactions.
+	indexIdempotencyKey = "transactions_idempotency_key_idx"
 )
 
+// rowQuerier - the pool or the transaction.
+type rowQuerier interface {
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+}
+
 type WalletRepo struct {
 	db *postgres.Postgres
 }
@@ -24,40 +33,264 @@ func New(pg *postgres.Postgres) *WalletRepo {
 }
 
 // CreateNewWallet - creating new wallet entry in the db.
-func (r *WalletRepo) CreateNewWallet(ctx context.Context, wallet *entity.Wallet) (*entity.Wallet, error) {
+// The creation is written to the audit log in the same transaction.
+func (r *WalletRepo) CreateNewWallet(ctx context.Context, wallet *entity.Wallet, caller entity.Caller) (*entity.Wallet, error) {
+	tx, err := r.db.Pool.Begin(ctx)
+	if err != nil {
+		return wallet, fmt.Errorf("WalletRepo.CreateNewWallet - r.Pool.Begin: %w", err)
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	var ownerID *string
+	if wallet.OwnerID != "" {
+		ownerID = &wallet.OwnerID
+	}
+
 	sql, args, _ := r.db.Builder.
 		Insert(tableWallets).
-		Columns("balance").
-		Values(wallet.Balance).
+		Columns("balance", "owner_id").
+		Values(wallet.Balance, ownerID).
 		Suffix("RETURNING id").
 		ToSql()
 
-	err := r.db.Pool.QueryRow(ctx, sql, args...).Scan(&wallet.ID)
+	err = tx.QueryRow(ctx, sql, args...).Scan(&wallet.ID)
 	if err != nil {
-		return wallet, fmt.Errorf("CreateNewWallet - r.Pool.QueryRow: %v", err)
+		return wallet, fmt.Errorf("CreateNewWallet - r.Pool.QueryRow: %w", err)
+	}
+
+	err = insertAudit(ctx, tx, r.db.Builder, &entity.AuditEntry{
+		Actor:     caller.Actor,
+		Action:    entity.ActionWalletCreated,
+		WalletID:  wallet.ID,
+		RequestID: caller.RequestID,
+		IP:        caller.IP,
+		PayloadHash: entity.PayloadHash(entity.CreateNewWalletWithBalanceRequest{
+			Balance: wallet.Balance,
+			OwnerID: wallet.OwnerID,
+		}),
+		Balances: []entity.BalanceChange{
+			{WalletID: wallet.ID, Before: 0, After: wallet.Balance},
+		},
+	})
+	if err != nil {
+		return wallet, fmt.Errorf("WalletRepo.CreateNewWallet - insertAudit: %w", err)
+	}
+
+	err = insertEvent(ctx, tx, r.db.Builder, entity.EventWalletCreated, wallet.ID, entity.WalletCreatedEvent{
+		WalletID: wallet.ID,
+		OwnerID:  wallet.OwnerID,
+		Balance:  wallet.Balance,
+	})
+	if err != nil {
+		return wallet, fmt.Errorf("WalletRepo.CreateNewWallet - insertEvent: %w", err)
+	}
+
+	err = tx.Commit(ctx)
+	if err != nil {
+		return wallet, fmt.Errorf("WalletRepo.CreateNewWallet - tx.Commit: %w", err)
 	}
 
 	return wallet, nil
 }
 
 // SendFunds - decreasing the balance of the sender and an increasing the receiver.
-// Adding an entry to a transaction table.
-func (r *WalletRepo) SendFunds(ctx context.Context, transaction *entity.Transaction) error {
+// Adding an entry to a transaction table, to the audit log and events to the outbox.
+// The transfer from the wallet with the same idempotency key is returned instead of making a new one. Transfers
+// of the same key lock the same sender wallet, so the repeated one waits for the first and finds it after the commit.
+func (r *WalletRepo) SendFunds(
+	ctx context.Context,
+	transaction *entity.Transaction,
+	idempotencyKey string,
+	caller entity.Caller,
+) error {
 	tx, err := r.db.Pool.Begin(ctx)
 	if err != nil {
-		return fmt.Errorf("WalletRepo.SendFunds - r.Pool.Begin: %v", err)
+		return fmt.Errorf("WalletRepo.SendFunds - r.Pool.Begin: %w", err)
 	}
 	defer func() { _ = tx.Rollback(ctx) }()
 
+	wallets, err := r.lockWallets(ctx, tx, transaction.From, transaction.To)
+	if err != nil {
+		return fmt.Errorf("WalletRepo.SendFunds - r.lockWallets: %w", err)
+	}
+
+	if idempotencyKey != "" {
+		done, err := r.findTransfer(ctx, tx, transaction, idempotencyKey)
+		if err != nil {
+			return fmt.Errorf("WalletRepo.SendFunds - r.findTransfer: %w", err)
+		}
+
+		if done {
+			return nil
+		}
+	}
+
+	if wallets[transaction.From].Frozen || wallets[transaction.To].Frozen {
+		return entity.ErrWalletFrozen
+	}
+
+	if wallets[transaction.From].Balance < transaction.Amount {
+		return entity.ErrNotEnoughFunds
+	}
+
+	err = r.moveFunds(ctx, tx, transaction, idempotencyKey)
+	if isIdempotencyKeyTaken(err) {
+		// The key was stored by a transfer committed after the lookup, it's replayed or conflicts the same way
+		_ = tx.Rollback(ctx)
+
+		return r.replayTransfer(ctx, transaction, idempotencyKey)
+	}
+	if err != nil {
+		return fmt.Errorf("WalletRepo.SendFunds - r.moveFunds: %w", err)
+	}
+
+	from, to := wallets[transaction.From], wallets[transaction.To]
+
+	err = insertAudit(ctx, tx, r.db.Builder, &entity.AuditEntry{
+		Actor:         caller.Actor,
+		Action:        entity.ActionFundsSent,
+		WalletID:      transaction.From,
+		TransactionID: transaction.ID,
+		RequestID:     caller.RequestID,
+		IP:            caller.IP,
+		PayloadHash: entity.PayloadHash(entity.SendFundsRequest{
+			From:   transaction.From,
+			To:     transaction.To,
+			Amount: transaction.Amount,
+		}),
+		Balances: []entity.BalanceChange{
+			{WalletID: from.ID, Before: from.Balance, After: from.Balance - transaction.Amount},
+			{WalletID: to.ID, Before: to.Balance, After: to.Balance + transaction.Amount},
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("WalletRepo.SendFunds - insertAudit: %w", err)
+	}
+
+	err = insertFundsEvents(ctx, tx, r.db.Builder, transaction, from, to)
+	if err != nil {
+		return fmt.Errorf("WalletRepo.SendFunds - insertFundsEvents: %w", err)
+	}
+
+	err = tx.Commit(ctx)
+	if err != nil {
+		return fmt.Errorf("WalletRepo.SendFunds - tx.Commit: %w", err)
+	}
+
+	return nil
+}
+
+// lockWallets - selecting wallets for update in the id order, so concurrent transfers don't deadlock.
+func (r *WalletRepo) lockWallets(ctx context.Context, tx pgx.Tx, ids ...string) (map[string]*entity.Wallet, error) {
+	sql, args, _ := r.db.Builder.
+		Select("id, balance, COALESCE(owner_id, ''), frozen").
+		From(tableWallets).
+		Where(squirrel.Eq{"id": ids}).
+		OrderBy("id").
+		Suffix("FOR UPDATE").
+		ToSql()
+
+	rows, err := tx.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, fmt.Errorf("tx.Query: %w", err)
+	}
+	defer rows.Close()
+
+	wallets := make(map[string]*entity.Wallet, len(ids))
+
+	for rows.Next() {
+		wallet := new(entity.Wallet)
+		err = rows.Scan(&wallet.ID, &wallet.Balance, &wallet.OwnerID, &wallet.Frozen)
+		if err != nil {
+			return nil, fmt.Errorf("rows.Scan: %w", err)
+		}
+		wallets[wallet.ID] = wallet
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows.Err: %w", err)
+	}
+
+	for _, id := range ids {
+		if _, ok := wallets[id]; !ok {
+			return nil, entity.ErrWalletNotFound
+		}
+	}
+
+	return wallets, nil
+}
+
+// findTransfer - the transfer made from the sender with the idempotency key, it must have the same receiver
+// and amount.
+func (r *WalletRepo) findTransfer(
+	ctx context.Context,
+	db rowQuerier,
+	transaction *entity.Transaction,
+	idempotencyKey string,
+) (bool, error) {
+	sql, args, _ := r.db.Builder.
+		Select("id, time, from_wallet_id, to_wallet_id, amount").
+		From(tableTransactions).
+		Where("from_wallet_id = ? AND idempotency_key = ?", transaction.From, idempotencyKey).
+		ToSql()
+
+	var done entity.Transaction
+
+	err := db.QueryRow(ctx, sql, args...).Scan(&done.ID, &done.Time, &done.From, &done.To, &done.Amount)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("db.QueryRow: %w", err)
+	}
+
+	if done.From != transaction.From || done.To != transaction.To || done.Amount != transaction.Amount {
+		return false, entity.ErrIdempotencyKeyConflict
+	}
+
+	transaction.ID, transaction.Time = done.ID, done.Time
+
+	return true, nil
+}
+
+// replayTransfer - the result of the transfer which stored the idempotency key first.
+func (r *WalletRepo) replayTransfer(ctx context.Context, transaction *entity.Transaction, idempotencyKey string) error {
+	done, err := r.findTransfer(ctx, r.db.Pool, transaction, idempotencyKey)
+	if err != nil {
+		return fmt.Errorf("WalletRepo.replayTransfer - r.findTransfer: %w", err)
+	}
+
+	if !done {
+		return fmt.Errorf("WalletRepo.replayTransfer - r.findTransfer: transfer of the key %q is missing", idempotencyKey)
+	}
+
+	return nil
+}
+
+// isIdempotencyKeyTaken - the insert violated the unique index of the idempotency keys.
+func isIdempotencyKeyTaken(err error) bool {
+	var pgErr *pgconn.PgError
+
+	return errors.As(err, &pgErr) && pgErr.Code == "23505" && pgErr.ConstraintName == indexIdempotencyKey
+}
+
+// moveFunds - changing balances of the locked wallets and adding the transaction entry.
+// The idempotency key is stored with the transaction, reversals have none.
+func (r *WalletRepo) moveFunds(
+	ctx context.Context,
+	tx pgx.Tx,
+	transaction *entity.Transaction,
+	idempotencyKey string,
+) error {
 	sql, args, _ := r.db.Builder.
 		Update(tableWallets).
 		Set("balance", squirrel.Expr("balance - ?", transaction.Amount)).
 		Where("id = ?", transaction.From).
 		ToSql()
 
-	_, err = tx.Exec(ctx, sql, args...)
+	_, err := tx.Exec(ctx, sql, args...)
 	if err != nil {
-		return fmt.Errorf("WalletRepo.SendFunds - tx.Exec: %v", err)
+		return fmt.Errorf("tx.Exec: %w", err)
 	}
 
 	sql, args, _ = r.db.Builder.
@@ -67,25 +300,25 @@ func (r *WalletRepo) SendFunds(ctx context.Context, transaction *entity.Transact
 		ToSql()
 
 	_, err = tx.Exec(ctx, sql, args...)
-
 	if err != nil {
-		return fmt.Errorf("WalletRepo.SendFunds - tx.Exec: %v", err)
+		return fmt.Errorf("tx.Exec: %w", err)
+	}
+
+	var key *string
+	if idempotencyKey != "" {
+		key = &idempotencyKey
 	}
 
 	sql, args, _ = r.db.Builder.
 		Insert(tableTransactions).
-		Columns("from_wallet_id", "to_wallet_id", "amount").
-		Values(transaction.From, transaction.To, transaction.Amount).
+		Columns("from_wallet_id", "to_wallet_id", "amount", "reversal_of", "idempotency_key").
+		Values(transaction.From, transaction.To, transaction.Amount, transaction.ReversalOf, key).
+		Suffix("RETURNING id, time").
 		ToSql()
 
-	_, err = tx.Exec(ctx, sql, args...)
-	if err != nil {
-		return fmt.Errorf("WalletRepo.SendFunds - tx.Exec: %v", err)
-	}
-
-	err = tx.Commit(ctx)
+	err = tx.QueryRow(ctx, sql, args...).Scan(&transaction.ID, &transaction.Time)
 	if err != nil {
-		return fmt.Errorf("WalletRepo.SendFunds - tx.Commit: %v", err)
+		return fmt.Errorf("tx.QueryRow: %w", err)
 	}
 
 	return nil
@@ -96,22 +329,31 @@ func (r *WalletRepo) GetWalletHistoryByID(ctx context.Context, walletID string)
 	var transactions []entity.Transaction
 
 	sqlQuery, args, _ := r.db.Builder.
-		Select("time, from_wallet_id, to_wallet_id, amount").
+		Select("id, time, from_wallet_id, to_wallet_id, amount, reversal_of, reversed_at").
 		From(tableTransactions).
 		Where("from_wallet_id = ? OR to_wallet_id = ?", walletID, walletID).
+		OrderBy("id").
 		ToSql()
 
 	rows, err := r.db.Pool.Query(ctx, sqlQuery, args...)
 	if err != nil {
-		return nil, fmt.Errorf("WalletRepo.GetWalletHistoryByID - r.Pool.Query: %v", err)
+		return nil, fmt.Errorf("WalletRepo.GetWalletHistoryByID - r.Pool.Query: %w", err)
 	}
 	defer rows.Close()
 
 	for rows.Next() {
 		var transaction entity.Transaction
-		err = rows.Scan(&transaction.Time, &transaction.From, &transaction.To, &transaction.Amount)
+		err = rows.Scan(
+			&transaction.ID,
+			&transaction.Time,
+			&transaction.From,
+			&transaction.To,
+			&transaction.Amount,
+			&transaction.ReversalOf,
+			&transaction.ReversedAt,
+		)
 		if err != nil {
-			return nil, fmt.Errorf("OperationRepo.paginationOperationsByDate - rows.Scan: %v", err)
+			return nil, fmt.Errorf("OperationRepo.paginationOperationsByDate - rows.Scan: %w", err)
 		}
 		transactions = append(transactions, transaction)
 	}
@@ -122,7 +364,7 @@ func (r *WalletRepo) GetWalletHistoryByID(ctx context.Context, walletID string)
 // GetWalletByID - getting wallet info by walletID.
 func (r *WalletRepo) GetWalletByID(ctx context.Context, walletID string) (*entity.Wallet, error) {
 	sql, args, _ := r.db.Builder.
-		Select("id, balance").
+		Select("id, balance, COALESCE(owner_id, ''), frozen").
 		From(tableWallets).
 		Where("id = ?", walletID).
 		ToSql()
@@ -131,12 +373,14 @@ func (r *WalletRepo) GetWalletByID(ctx context.Context, walletID string) (*entit
 	err := r.db.Pool.QueryRow(ctx, sql, args...).Scan(
 		&wallet.ID,
 		&wallet.Balance,
+		&wallet.OwnerID,
+		&wallet.Frozen,
 	)
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
-			return nil, errors.New("Not found")
+			return nil, entity.ErrWalletNotFound
 		}
-		return wallet, fmt.Errorf("WalletRepo.GetWalletByID - r.Pool.QueryRow: %v", err)
+		return wallet, fmt.Errorf("WalletRepo.GetWalletByID - r.Pool.QueryRow: %w", err)
 	}
 
 	return wallet, nil
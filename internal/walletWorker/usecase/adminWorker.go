@@ -0,0 +1,71 @@
+package usecase
+
+import (
+	"WalletRieltaTestTask/internal/entity"
+	"context"
+	"fmt"
+)
+
+type AdminWorkerUseCase struct {
+	repo AdminWorkerRepo
+}
+
+func NewAdminWorker(r AdminWorkerRepo) *AdminWorkerUseCase {
+	return &AdminWorkerUseCase{
+		repo: r,
+	}
+}
+
+// Searching wallets in repository.
+func (uc *AdminWorkerUseCase) SearchWallets(ctx context.Context, filter entity.WalletFilter) ([]entity.Wallet, error) {
+	wallets, err := uc.repo.SearchWallets(ctx, filter)
+	if err != nil {
+		return nil, fmt.Errorf("AdminWorkerUseCase - SearchWallets - uc.repo.SearchWallets: %w", err)
+	}
+
+	return wallets, nil
+}
+
+// Manual adjustment of the wallet balance in repository.
+func (uc *AdminWorkerUseCase) AdjustBalance(
+	ctx context.Context,
+	walletID string,
+	amount int64,
+	action entity.AdminAction,
+) (*entity.Wallet, error) {
+	wallet, err := uc.repo.AdjustBalance(ctx, walletID, amount, action)
+	if err != nil {
+		return nil, fmt.Errorf("AdminWorkerUseCase - AdjustBalance - uc.repo.AdjustBalance: %w", err)
+	}
+
+	return wallet, nil
+}
+
+// Freezing or unfreezing the wallet in repository.
+func (uc *AdminWorkerUseCase) SetWalletFrozen(
+	ctx context.Context,
+	walletID string,
+	frozen bool,
+	action entity.AdminAction,
+) (*entity.Wallet, error) {
+	wallet, err := uc.repo.SetWalletFrozen(ctx, walletID, frozen, action)
+	if err != nil {
+		return nil, fmt.Errorf("AdminWorkerUseCase - SetWalletFrozen - uc.repo.SetWalletFrozen: %w", err)
+	}
+
+	return wallet, nil
+}
+
+// Reversing the transaction in repository.
+func (uc *AdminWorkerUseCase) ReverseTransaction(
+	ctx context.Context,
+	transactionID int64,
+	action entity.AdminAction,
+) (*entity.Transaction, error) {
+	transaction, err := uc.repo.ReverseTransaction(ctx, transactionID, action)
+	if err != nil {
+		return nil, fmt.Errorf("AdminWorkerUseCase - ReverseTransaction - uc.repo.ReverseTransaction: %w", err)
+	}
+
+	return transaction, nil
+}
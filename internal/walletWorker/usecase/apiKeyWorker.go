@@ -0,0 +1,117 @@
+package usecase
+
+import (
+	"WalletRieltaTestTask/internal/entity"
+	"WalletRieltaTestTask/pkg/sealer"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"time"
+)
+
+const (
+	_apiKeyPrefix      = "wr_"
+	_apiKeyIDBytes     = 8
+	_apiKeySecretBytes = 32
+)
+
+type APIKeyWorkerUseCase struct {
+	repo   APIKeyWorkerRepo
+	sealer *sealer.Sealer
+}
+
+func NewAPIKeyWorker(r APIKeyWorkerRepo, s *sealer.Sealer) *APIKeyWorkerUseCase {
+	return &APIKeyWorkerUseCase{
+		repo:   r,
+		sealer: s,
+	}
+}
+
+// Generating a new api key and saving its hash and encrypted signing secret in repository.
+// The raw key and the signing secret are returned only once, the raw key is never stored.
+func (uc *APIKeyWorkerUseCase) CreateAPIKey(
+	ctx context.Context,
+	name string,
+	scopes []string,
+	roles []string,
+	requireSignature bool,
+) (string, *entity.APIKey, error) {
+	id, err := randomHex(_apiKeyIDBytes)
+	if err != nil {
+		return "", nil, fmt.Errorf("APIKeyWorkerUseCase - CreateAPIKey - randomHex: %w", err)
+	}
+
+	secret, err := randomHex(_apiKeySecretBytes)
+	if err != nil {
+		return "", nil, fmt.Errorf("APIKeyWorkerUseCase - CreateAPIKey - randomHex: %w", err)
+	}
+
+	rawKey := _apiKeyPrefix + id + "." + secret
+
+	// The signing secret isn't derived from the key, so the stored hash can't be used to sign requests
+	signingSecret, err := randomHex(_apiKeySecretBytes)
+	if err != nil {
+		return "", nil, fmt.Errorf("APIKeyWorkerUseCase - CreateAPIKey - randomHex: %w", err)
+	}
+
+	sealed, err := uc.sealer.Seal([]byte(signingSecret))
+	if err != nil {
+		return "", nil, fmt.Errorf("APIKeyWorkerUseCase - CreateAPIKey - uc.sealer.Seal: %w", err)
+	}
+
+	key, err := uc.repo.CreateAPIKey(ctx, &entity.APIKey{
+		ID:                  _apiKeyPrefix + id,
+		Name:                name,
+		Hash:                entity.HashAPIKey(rawKey),
+		SealedSigningSecret: sealed,
+		Scopes:              scopes,
+		Roles:               roles,
+		RequireSignature:    requireSignature,
+	})
+	if err != nil {
+		return "", nil, fmt.Errorf("APIKeyWorkerUseCase - CreateAPIKey - uc.repo.CreateAPIKey: %w", err)
+	}
+
+	key.SigningSecret = signingSecret
+
+	return rawKey, key, nil
+}
+
+// Getting api key by id from repository with the decrypted signing secret.
+func (uc *APIKeyWorkerUseCase) GetAPIKeyByID(ctx context.Context, id string) (*entity.APIKey, error) {
+	key, err := uc.repo.GetAPIKeyByID(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("APIKeyWorkerUseCase - GetAPIKeyByID - uc.repo.GetAPIKeyByID: %w", err)
+	}
+
+	if key.SealedSigningSecret != nil {
+		secret, err := uc.sealer.Open(key.SealedSigningSecret)
+		if err != nil {
+			return nil, fmt.Errorf("APIKeyWorkerUseCase - GetAPIKeyByID - uc.sealer.Open: %w", err)
+		}
+
+		key.SigningSecret = string(secret)
+	}
+
+	return key, nil
+}
+
+// Registering nonce of a signed request in repository.
+func (uc *APIKeyWorkerUseCase) RegisterNonce(ctx context.Context, keyID, nonce string, expiresAt time.Time) (bool, error) {
+	registered, err := uc.repo.RegisterNonce(ctx, keyID, nonce, expiresAt)
+	if err != nil {
+		return false, fmt.Errorf("APIKeyWorkerUseCase - RegisterNonce - uc.repo.RegisterNonce: %w", err)
+	}
+
+	return registered, nil
+}
+
+func randomHex(n int) (string, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(b), nil
+}
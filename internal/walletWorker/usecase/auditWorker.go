@@ -0,0 +1,37 @@
+package usecase
+
+import (
+	"WalletRieltaTestTask/internal/entity"
+	"context"
+	"fmt"
+)
+
+type AuditWorkerUseCase struct {
+	repo AuditWorkerRepo
+}
+
+func NewAuditWorker(r AuditWorkerRepo) *AuditWorkerUseCase {
+	return &AuditWorkerUseCase{
+		repo: r,
+	}
+}
+
+// Searching audit log entries in repository.
+func (uc *AuditWorkerUseCase) SearchAudit(ctx context.Context, filter entity.AuditFilter) ([]entity.AuditEntry, error) {
+	entries, err := uc.repo.SearchAudit(ctx, filter)
+	if err != nil {
+		return nil, fmt.Errorf("AuditWorkerUseCase - SearchAudit - uc.repo.SearchAudit: %w", err)
+	}
+
+	return entries, nil
+}
+
+// Verifying the hash chain of the audit log in repository.
+func (uc *AuditWorkerUseCase) VerifyAuditChain(ctx context.Context, savedHead string) (entity.AuditChainReport, error) {
+	report, err := uc.repo.VerifyAuditChain(ctx, savedHead)
+	if err != nil {
+		return report, fmt.Errorf("AuditWorkerUseCase - VerifyAuditChain - uc.repo.VerifyAuditChain: %w", err)
+	}
+
+	return report, nil
+}
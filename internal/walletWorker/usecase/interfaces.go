@@ -3,20 +3,127 @@ package usecase
 import (
 	"WalletRieltaTestTask/internal/entity"
 	"context"
+	"time"
 )
 
 type (
 	WalletWorker interface {
-		CreateNewWalletWithBalance(ctx context.Context, balance uint) (*entity.Wallet, error)
-		SendFunds(ctx context.Context, from string, to string, amount uint) error
+		CreateNewWalletWithBalance(
+			ctx context.Context,
+			balance uint,
+			ownerID string,
+			caller entity.Caller,
+		) (*entity.Wallet, error)
+		SendFunds(ctx context.Context, from string, to string, amount uint, idempotencyKey string, caller entity.Caller) error
 		GetWalletHistoryByID(ctx context.Context, walletID string) ([]entity.Transaction, error)
 		GetWalletByID(ctx context.Context, walletID string) (*entity.Wallet, error)
+		ListWalletEvents(ctx context.Context, walletID, after string, limit uint) ([]entity.Event, error)
 	}
 
 	WalletWorkerRepo interface {
-		CreateNewWallet(ctx context.Context, wallet *entity.Wallet) (*entity.Wallet, error)
-		SendFunds(ctx context.Context, transaction *entity.Transaction) error
+		CreateNewWallet(ctx context.Context, wallet *entity.Wallet, caller entity.Caller) (*entity.Wallet, error)
+		SendFunds(ctx context.Context, transaction *entity.Transaction, idempotencyKey string, caller entity.Caller) error
 		GetWalletHistoryByID(ctx context.Context, walletID string) ([]entity.Transaction, error)
 		GetWalletByID(ctx context.Context, walletID string) (*entity.Wallet, error)
+		ListWalletEvents(ctx context.Context, walletID, after string, limit uint) ([]entity.Event, error)
+	}
+
+	APIKeyWorker interface {
+		CreateAPIKey(
+			ctx context.Context,
+			name string,
+			scopes []string,
+			roles []string,
+			requireSignature bool,
+		) (string, *entity.APIKey, error)
+		GetAPIKeyByID(ctx context.Context, id string) (*entity.APIKey, error)
+		RegisterNonce(ctx context.Context, keyID, nonce string, expiresAt time.Time) (bool, error)
+	}
+
+	APIKeyWorkerRepo interface {
+		CreateAPIKey(ctx context.Context, key *entity.APIKey) (*entity.APIKey, error)
+		GetAPIKeyByID(ctx context.Context, id string) (*entity.APIKey, error)
+		RegisterNonce(ctx context.Context, keyID, nonce string, expiresAt time.Time) (bool, error)
+	}
+
+	AdminWorker interface {
+		SearchWallets(ctx context.Context, filter entity.WalletFilter) ([]entity.Wallet, error)
+		AdjustBalance(ctx context.Context, walletID string, amount int64, action entity.AdminAction) (*entity.Wallet, error)
+		SetWalletFrozen(ctx context.Context, walletID string, frozen bool, action entity.AdminAction) (*entity.Wallet, error)
+		ReverseTransaction(ctx context.Context, transactionID int64, action entity.AdminAction) (*entity.Transaction, error)
+	}
+
+	AdminWorkerRepo interface {
+		SearchWallets(ctx context.Context, filter entity.WalletFilter) ([]entity.Wallet, error)
+		AdjustBalance(ctx context.Context, walletID string, amount int64, action entity.AdminAction) (*entity.Wallet, error)
+		SetWalletFrozen(ctx context.Context, walletID string, frozen bool, action entity.AdminAction) (*entity.Wallet, error)
+		ReverseTransaction(ctx context.Context, transactionID int64, action entity.AdminAction) (*entity.Transaction, error)
+	}
+
+	OutboxRelay interface {
+		RelayPending(ctx context.Context) (int, error)
+	}
+
+	OutboxRepo interface {
+		PublishPending(
+			ctx context.Context,
+			limit uint,
+			publish func(ctx context.Context, event *entity.Event) error,
+		) (int, error)
+	}
+
+	EventPublisher interface {
+		Publish(ctx context.Context, event *entity.Event) error
+	}
+
+	WebhookWorker interface {
+		CreateWebhook(ctx context.Context, webhook *entity.Webhook) (*entity.Webhook, error)
+		ListWebhooks(ctx context.Context, createdBy string) ([]entity.Webhook, error)
+		GetWebhook(ctx context.Context, id, createdBy string) (*entity.Webhook, error)
+		DeleteWebhook(ctx context.Context, id, createdBy string) error
+		ListDeliveries(
+			ctx context.Context,
+			webhookID string,
+			createdBy string,
+			limit uint,
+			offset uint,
+		) ([]entity.WebhookDelivery, error)
+	}
+
+	WebhookWorkerRepo interface {
+		CreateWebhook(ctx context.Context, webhook *entity.Webhook) (*entity.Webhook, error)
+		ListWebhooks(ctx context.Context, createdBy string) ([]entity.Webhook, error)
+		GetWebhook(ctx context.Context, id, createdBy string) (*entity.Webhook, error)
+		DeleteWebhook(ctx context.Context, id, createdBy string) error
+		ListDeliveries(
+			ctx context.Context,
+			webhookID string,
+			createdBy string,
+			limit uint,
+			offset uint,
+		) ([]entity.WebhookDelivery, error)
+	}
+
+	WebhookDispatcher interface {
+		DispatchPending(ctx context.Context) (int, error)
+	}
+
+	WebhookDispatchRepo interface {
+		ClaimDeliveries(ctx context.Context, limit uint, lease time.Duration) ([]entity.WebhookDispatch, error)
+		SaveAttempt(ctx context.Context, delivery *entity.WebhookDelivery) error
+	}
+
+	WebhookSender interface {
+		Send(ctx context.Context, dispatch *entity.WebhookDispatch) (int, error)
+	}
+
+	AuditWorker interface {
+		SearchAudit(ctx context.Context, filter entity.AuditFilter) ([]entity.AuditEntry, error)
+		VerifyAuditChain(ctx context.Context, savedHead string) (entity.AuditChainReport, error)
+	}
+
+	AuditWorkerRepo interface {
+		SearchAudit(ctx context.Context, filter entity.AuditFilter) ([]entity.AuditEntry, error)
+		VerifyAuditChain(ctx context.Context, savedHead string) (entity.AuditChainReport, error)
 	}
 )
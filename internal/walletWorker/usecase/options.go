@@ -0,0 +1,56 @@
+package usecase
+
+import "time"
+
+type RelayOption func(*OutboxRelayUseCase)
+
+// BatchSize - maximum number of events published at once.
+func BatchSize(size uint) RelayOption {
+	return func(uc *OutboxRelayUseCase) {
+		if size > 0 {
+			uc.batchSize = size
+		}
+	}
+}
+
+type DispatcherOption func(*WebhookDispatcherUseCase)
+
+// DispatchBatchSize - maximum number of deliveries sent at once.
+func DispatchBatchSize(size uint) DispatcherOption {
+	return func(uc *WebhookDispatcherUseCase) {
+		if size > 0 {
+			uc.batchSize = size
+		}
+	}
+}
+
+// MaxAttempts - failed attempts after which the delivery is dead.
+func MaxAttempts(attempts int) DispatcherOption {
+	return func(uc *WebhookDispatcherUseCase) {
+		if attempts > 0 {
+			uc.maxAttempts = attempts
+		}
+	}
+}
+
+// Backoff - delay after the first failed attempt, it doubles up to the max delay.
+func Backoff(base, maxDelay time.Duration) DispatcherOption {
+	return func(uc *WebhookDispatcherUseCase) {
+		if base > 0 {
+			uc.backoffBase = base
+		}
+		if maxDelay > 0 {
+			uc.backoffMax = maxDelay
+		}
+	}
+}
+
+// DispatchLease - time the claimed deliveries are hidden from other dispatchers,
+// must be longer than the request timeout.
+func DispatchLease(lease time.Duration) DispatcherOption {
+	return func(uc *WebhookDispatcherUseCase) {
+		if lease > 0 {
+			uc.lease = lease
+		}
+	}
+}
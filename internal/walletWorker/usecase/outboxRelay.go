@@ -0,0 +1,38 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+)
+
+const _defaultBatchSize uint = 100
+
+type OutboxRelayUseCase struct {
+	repo      OutboxRepo
+	publisher EventPublisher
+	batchSize uint
+}
+
+func NewOutboxRelay(r OutboxRepo, p EventPublisher, opts ...RelayOption) *OutboxRelayUseCase {
+	uc := &OutboxRelayUseCase{
+		repo:      r,
+		publisher: p,
+		batchSize: _defaultBatchSize,
+	}
+
+	for _, opt := range opts {
+		opt(uc)
+	}
+
+	return uc
+}
+
+// Publishing a batch of events from the outbox, returns the number of published events.
+func (uc *OutboxRelayUseCase) RelayPending(ctx context.Context) (int, error) {
+	published, err := uc.repo.PublishPending(ctx, uc.batchSize, uc.publisher.Publish)
+	if err != nil {
+		return published, fmt.Errorf("OutboxRelayUseCase - RelayPending - uc.repo.PublishPending: %w", err)
+	}
+
+	return published, nil
+}
@@ -17,13 +17,19 @@ func NewWalletWorker(r WalletWorkerRepo) *WalletWorkerUseCase {
 }
 
 // Creating a new wallet with balance in repository.
-func (uc *WalletWorkerUseCase) CreateNewWalletWithBalance(ctx context.Context, balance uint) (*entity.Wallet, error) {
+func (uc *WalletWorkerUseCase) CreateNewWalletWithBalance(
+	ctx context.Context,
+	balance uint,
+	ownerID string,
+	caller entity.Caller,
+) (*entity.Wallet, error) {
 	// Create a new instance of the wallet with default balance
 	defaultWallet := &entity.Wallet{
 		Balance: balance,
+		OwnerID: ownerID,
 	}
 
-	wallet, err := uc.repo.CreateNewWallet(ctx, defaultWallet)
+	wallet, err := uc.repo.CreateNewWallet(ctx, defaultWallet, caller)
 	if err != nil {
 		return nil, fmt.Errorf("WalletWorkerUseCase - CreateNewWalletWithBalance - w.repo.CreateNewWallet: %w", err)
 	}
@@ -32,14 +38,26 @@ func (uc *WalletWorkerUseCase) CreateNewWalletWithBalance(ctx context.Context, b
 }
 
 // Sending funds through wallets in repository.
-func (uc *WalletWorkerUseCase) SendFunds(ctx context.Context, from string, to string, amount uint) error {
+// Idempotency keys are chosen by the clients, so they are unique only for the same caller.
+func (uc *WalletWorkerUseCase) SendFunds(
+	ctx context.Context,
+	from string,
+	to string,
+	amount uint,
+	idempotencyKey string,
+	caller entity.Caller,
+) error {
 	transaction := &entity.Transaction{
 		From:   from,
 		To:     to,
 		Amount: amount,
 	}
 
-	err := uc.repo.SendFunds(ctx, transaction)
+	if idempotencyKey != "" {
+		idempotencyKey = caller.Actor + ":" + idempotencyKey
+	}
+
+	err := uc.repo.SendFunds(ctx, transaction, idempotencyKey, caller)
 	if err != nil {
 		return fmt.Errorf("WalletWorkerUseCase - SendFunds - w.repo.SendFunds: %w", err)
 	}
@@ -66,3 +84,18 @@ func (uc *WalletWorkerUseCase) GetWalletByID(ctx context.Context, walletID strin
 
 	return wallet, nil
 }
+
+// Getting events of the wallet added after the event from repository.
+func (uc *WalletWorkerUseCase) ListWalletEvents(
+	ctx context.Context,
+	walletID string,
+	after string,
+	limit uint,
+) ([]entity.Event, error) {
+	events, err := uc.repo.ListWalletEvents(ctx, walletID, after, limit)
+	if err != nil {
+		return nil, fmt.Errorf("WalletWorkerUseCase - ListWalletEvents - w.repo.ListWalletEvents: %w", err)
+	}
+
+	return events, nil
+}
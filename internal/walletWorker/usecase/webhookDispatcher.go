@@ -0,0 +1,129 @@
+package usecase
+
+import (
+	"WalletRieltaTestTask/internal/entity"
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+const (
+	_defaultMaxAttempts = 8
+	_defaultBackoffBase = 10 * time.Second
+	_defaultBackoffMax  = time.Hour
+	// Claimed deliveries are hidden from other dispatchers for this time.
+	_defaultDispatchLease = time.Minute
+)
+
+type WebhookDispatcherUseCase struct {
+	repo   WebhookDispatchRepo
+	sender WebhookSender
+
+	batchSize   uint
+	maxAttempts int
+	backoffBase time.Duration
+	backoffMax  time.Duration
+	lease       time.Duration
+
+	now func() time.Time
+}
+
+func NewWebhookDispatcher(r WebhookDispatchRepo, s WebhookSender, opts ...DispatcherOption) *WebhookDispatcherUseCase {
+	uc := &WebhookDispatcherUseCase{
+		repo:        r,
+		sender:      s,
+		batchSize:   _defaultBatchSize,
+		maxAttempts: _defaultMaxAttempts,
+		backoffBase: _defaultBackoffBase,
+		backoffMax:  _defaultBackoffMax,
+		lease:       _defaultDispatchLease,
+		now:         time.Now,
+	}
+
+	for _, opt := range opts {
+		opt(uc)
+	}
+
+	return uc
+}
+
+// Sending a batch of due deliveries concurrently, returns the number of attempts made.
+func (uc *WebhookDispatcherUseCase) DispatchPending(ctx context.Context) (int, error) {
+	dispatches, err := uc.repo.ClaimDeliveries(ctx, uc.batchSize, uc.lease)
+	if err != nil {
+		return 0, fmt.Errorf("WebhookDispatcherUseCase - DispatchPending - uc.repo.ClaimDeliveries: %w", err)
+	}
+
+	var (
+		wg   sync.WaitGroup
+		mu   sync.Mutex
+		errs []error
+	)
+
+	for i := range dispatches {
+		wg.Add(1)
+
+		go func(dispatch *entity.WebhookDispatch) {
+			defer wg.Done()
+
+			if err := uc.dispatch(ctx, dispatch); err != nil {
+				mu.Lock()
+				errs = append(errs, err)
+				mu.Unlock()
+			}
+		}(&dispatches[i])
+	}
+
+	wg.Wait()
+
+	if len(errs) > 0 {
+		return len(dispatches), fmt.Errorf("WebhookDispatcherUseCase - DispatchPending - uc.dispatch: %w", errors.Join(errs...))
+	}
+
+	return len(dispatches), nil
+}
+
+// Sending the delivery and recording the attempt.
+// Failed delivery is retried with exponential backoff until it runs out of attempts.
+func (uc *WebhookDispatcherUseCase) dispatch(ctx context.Context, dispatch *entity.WebhookDispatch) error {
+	delivery := &dispatch.Delivery
+
+	status, err := uc.sender.Send(ctx, dispatch)
+
+	now := uc.now()
+	delivery.Attempts++
+	delivery.ResponseStatus = status
+
+	switch {
+	case err == nil:
+		delivery.Status = entity.DeliveryDelivered
+		delivery.LastError = ""
+		delivery.DeliveredAt = &now
+	case delivery.Attempts >= uc.maxAttempts:
+		delivery.Status = entity.DeliveryDead
+		delivery.LastError = err.Error()
+	default:
+		next := now.Add(uc.backoff(delivery.Attempts))
+		delivery.LastError = err.Error()
+		delivery.NextAttemptAt = &next
+	}
+
+	err = uc.repo.SaveAttempt(ctx, delivery)
+	if err != nil {
+		return fmt.Errorf("delivery %d - uc.repo.SaveAttempt: %w", delivery.ID, err)
+	}
+
+	return nil
+}
+
+// Delay before the next attempt doubles after every failed one.
+func (uc *WebhookDispatcherUseCase) backoff(attempts int) time.Duration {
+	delay := uc.backoffBase
+	for i := 1; i < attempts && delay < uc.backoffMax; i++ {
+		delay *= 2
+	}
+
+	return min(delay, uc.backoffMax)
+}
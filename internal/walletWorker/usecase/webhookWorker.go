@@ -0,0 +1,79 @@
+package usecase
+
+import (
+	"WalletRieltaTestTask/internal/entity"
+	"context"
+	"fmt"
+)
+
+type WebhookWorkerUseCase struct {
+	repo WebhookWorkerRepo
+}
+
+func NewWebhookWorker(r WebhookWorkerRepo) *WebhookWorkerUseCase {
+	return &WebhookWorkerUseCase{
+		repo: r,
+	}
+}
+
+// Adding the webhook subscription in repository.
+func (uc *WebhookWorkerUseCase) CreateWebhook(ctx context.Context, webhook *entity.Webhook) (*entity.Webhook, error) {
+	webhook, err := uc.repo.CreateWebhook(ctx, webhook)
+	if err != nil {
+		return nil, fmt.Errorf("WebhookWorkerUseCase - CreateWebhook - uc.repo.CreateWebhook: %w", err)
+	}
+
+	return webhook, nil
+}
+
+// Getting webhooks of the actor from repository.
+func (uc *WebhookWorkerUseCase) ListWebhooks(ctx context.Context, createdBy string) ([]entity.Webhook, error) {
+	webhooks, err := uc.repo.ListWebhooks(ctx, createdBy)
+	if err != nil {
+		return nil, fmt.Errorf("WebhookWorkerUseCase - ListWebhooks - uc.repo.ListWebhooks: %w", err)
+	}
+
+	return webhooks, nil
+}
+
+// Getting the webhook of the actor from repository.
+func (uc *WebhookWorkerUseCase) GetWebhook(ctx context.Context, id, createdBy string) (*entity.Webhook, error) {
+	webhook, err := uc.repo.GetWebhook(ctx, id, createdBy)
+	if err != nil {
+		return nil, fmt.Errorf("WebhookWorkerUseCase - GetWebhook - uc.repo.GetWebhook: %w", err)
+	}
+
+	return webhook, nil
+}
+
+// Removing the webhook of the actor from repository.
+func (uc *WebhookWorkerUseCase) DeleteWebhook(ctx context.Context, id, createdBy string) error {
+	err := uc.repo.DeleteWebhook(ctx, id, createdBy)
+	if err != nil {
+		return fmt.Errorf("WebhookWorkerUseCase - DeleteWebhook - uc.repo.DeleteWebhook: %w", err)
+	}
+
+	return nil
+}
+
+// Getting the delivery log of the webhook from repository.
+func (uc *WebhookWorkerUseCase) ListDeliveries(
+	ctx context.Context,
+	webhookID string,
+	createdBy string,
+	limit uint,
+	offset uint,
+) ([]entity.WebhookDelivery, error) {
+	// Only the owner of the webhook can see its log
+	_, err := uc.repo.GetWebhook(ctx, webhookID, createdBy)
+	if err != nil {
+		return nil, fmt.Errorf("WebhookWorkerUseCase - ListDeliveries - uc.repo.GetWebhook: %w", err)
+	}
+
+	deliveries, err := uc.repo.ListDeliveries(ctx, webhookID, createdBy, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("WebhookWorkerUseCase - ListDeliveries - uc.repo.ListDeliveries: %w", err)
+	}
+
+	return deliveries, nil
+}
@@ -0,0 +1,85 @@
+package grpcserver
+
+import (
+	"fmt"
+	"google.golang.org/grpc"
+	"log/slog"
+	"net"
+	"time"
+)
+
+const (
+	_defaultAddr            = ":9090"
+	_defaultShutdownTimeout = 5 * time.Second
+)
+
+type Server struct {
+	log             *slog.Logger
+	grpcServer      *grpc.Server
+	addr            string
+	shutdownTimeout time.Duration
+}
+
+// New - the services must be registered on the grpc server before it's run.
+func New(log *slog.Logger, grpcServer *grpc.Server, opts ...Option) *Server {
+	s := &Server{
+		log:             log,
+		grpcServer:      grpcServer,
+		addr:            _defaultAddr,
+		shutdownTimeout: _defaultShutdownTimeout,
+	}
+
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	return s
+}
+
+func (s *Server) MustRun() {
+	if err := s.Run(); err != nil {
+		panic("cannot run grpc server: " + err.Error())
+	}
+}
+
+func (s *Server) Run() error {
+	const op = "grpcserver.Run"
+
+	l, err := net.Listen("tcp", s.addr)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	s.log.Info("grpc server started", slog.String("addr", l.Addr().String()))
+
+	// Serve returns nil after GracefulStop or Stop
+	if err := s.grpcServer.Serve(l); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+// Shutdown - waiting for the running calls, the rest are cancelled after the shutdown timeout.
+func (s *Server) Shutdown() error {
+	const op = "grpcserver.Shutdown"
+
+	s.log.With(slog.String("op", op)).
+		Info("stopping grpc server", slog.String("port", s.addr))
+
+	stopped := make(chan struct{})
+
+	go func() {
+		s.grpcServer.GracefulStop()
+		close(stopped)
+	}()
+
+	select {
+	case <-stopped:
+		return nil
+	case <-time.After(s.shutdownTimeout):
+		s.grpcServer.Stop()
+
+		return fmt.Errorf("%s: calls were cancelled after %s", op, s.shutdownTimeout)
+	}
+}
@@ -0,0 +1,150 @@
+package jwks
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/golang-jwt/jwt/v5"
+	"math/big"
+	"os"
+)
+
+var (
+	ErrKeyNotFound       = errors.New("jwks: key not found")
+	ErrUnsupportedKey    = errors.New("jwks: unsupported key type")
+	ErrUnsupportedCurve  = errors.New("jwks: unsupported curve")
+	ErrKeyAlgMismatch    = errors.New("jwks: key does not match token algorithm")
+	ErrMissingKeyIDInJWT = errors.New("jwks: token has no kid header")
+)
+
+// Key - public key from the JSON Web Key Set (RFC 7517), only RSA and EC P-256 keys are supported.
+type Key struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Alg string `json:"alg"`
+	Use string `json:"use"`
+
+	// RSA
+	N string `json:"n"`
+	E string `json:"e"`
+
+	// EC
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+type KeySet struct {
+	keys map[string]interface{}
+}
+
+// Load - reading key set from the local file.
+func Load(path string) (*KeySet, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("jwks - Load - os.ReadFile: %w", err)
+	}
+
+	return Parse(data)
+}
+
+func Parse(data []byte) (*KeySet, error) {
+	var set struct {
+		Keys []Key `json:"keys"`
+	}
+
+	if err := json.Unmarshal(data, &set); err != nil {
+		return nil, fmt.Errorf("jwks - Parse - json.Unmarshal: %w", err)
+	}
+
+	ks := &KeySet{keys: make(map[string]interface{}, len(set.Keys))}
+
+	for _, k := range set.Keys {
+		if k.Use != "" && k.Use != "sig" {
+			continue
+		}
+
+		key, err := k.publicKey()
+		if err != nil {
+			return nil, fmt.Errorf("jwks - Parse - key %q: %w", k.Kid, err)
+		}
+
+		ks.keys[k.Kid] = key
+	}
+
+	return ks, nil
+}
+
+// Keyfunc - to be used with jwt.Parse, selects the key by the "kid" token header.
+func (ks *KeySet) Keyfunc(token *jwt.Token) (interface{}, error) {
+	kid, ok := token.Header["kid"].(string)
+	if !ok {
+		return nil, ErrMissingKeyIDInJWT
+	}
+
+	key, ok := ks.keys[kid]
+	if !ok {
+		return nil, ErrKeyNotFound
+	}
+
+	switch token.Method.(type) {
+	case *jwt.SigningMethodRSA:
+		if _, ok := key.(*rsa.PublicKey); !ok {
+			return nil, ErrKeyAlgMismatch
+		}
+	case *jwt.SigningMethodECDSA:
+		if _, ok := key.(*ecdsa.PublicKey); !ok {
+			return nil, ErrKeyAlgMismatch
+		}
+	}
+
+	return key, nil
+}
+
+func (k *Key) publicKey() (interface{}, error) {
+	switch k.Kty {
+	case "RSA":
+		n, err := decodeBigInt(k.N)
+		if err != nil {
+			return nil, fmt.Errorf("n: %w", err)
+		}
+
+		e, err := decodeBigInt(k.E)
+		if err != nil {
+			return nil, fmt.Errorf("e: %w", err)
+		}
+
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+	case "EC":
+		if k.Crv != "P-256" {
+			return nil, ErrUnsupportedCurve
+		}
+
+		x, err := decodeBigInt(k.X)
+		if err != nil {
+			return nil, fmt.Errorf("x: %w", err)
+		}
+
+		y, err := decodeBigInt(k.Y)
+		if err != nil {
+			return nil, fmt.Errorf("y: %w", err)
+		}
+
+		return &ecdsa.PublicKey{Curve: elliptic.P256(), X: x, Y: y}, nil
+	default:
+		return nil, ErrUnsupportedKey
+	}
+}
+
+func decodeBigInt(s string) (*big.Int, error) {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, err
+	}
+
+	return new(big.Int).SetBytes(b), nil
+}
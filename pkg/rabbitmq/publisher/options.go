@@ -0,0 +1,24 @@
+package publisher
+
+import "time"
+
+type Option func(*Publisher)
+
+// ConfirmTimeout - how long to wait for the broker to confirm the message.
+func ConfirmTimeout(timeout time.Duration) Option {
+	return func(p *Publisher) {
+		p.confirmTimeout = timeout
+	}
+}
+
+func ConnWaitTime(timeout time.Duration) Option {
+	return func(p *Publisher) {
+		p.waitTime = timeout
+	}
+}
+
+func ConnAttempts(attempts int) Option {
+	return func(p *Publisher) {
+		p.attempts = attempts
+	}
+}
@@ -0,0 +1,199 @@
+package publisher
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	amqp "github.com/rabbitmq/amqp091-go"
+	"log"
+	"sync"
+	"time"
+)
+
+const (
+	_defaultWaitTime       = 2 * time.Second
+	_defaultAttempts       = 10
+	_defaultConfirmTimeout = 5 * time.Second
+)
+
+var (
+	ErrNotConfirmed = errors.New("message is not confirmed by the broker")
+
+	ErrClosed = errors.New("publisher is closed")
+)
+
+// Message - persistent message of the topic exchange.
+type Message struct {
+	ID          string
+	RoutingKey  string
+	Type        string
+	Timestamp   time.Time
+	ContentType string
+	Body        []byte
+}
+
+// Publisher - publishes messages to a durable topic exchange with publisher confirms.
+// Publish returns only after the broker has taken responsibility for the message.
+type Publisher struct {
+	url      string
+	exchange string
+
+	mu       sync.Mutex
+	conn     *amqp.Connection
+	channel  *amqp.Channel
+	confirms chan amqp.Confirmation
+	closed   bool
+
+	waitTime       time.Duration
+	attempts       int
+	confirmTimeout time.Duration
+}
+
+func New(url, exchange string, opts ...Option) (*Publisher, error) {
+	p := &Publisher{
+		url:            url,
+		exchange:       exchange,
+		waitTime:       _defaultWaitTime,
+		attempts:       _defaultAttempts,
+		confirmTimeout: _defaultConfirmTimeout,
+	}
+
+	for _, opt := range opts {
+		opt(p)
+	}
+
+	err := p.attemptConnect()
+	if err != nil {
+		return nil, fmt.Errorf("rabbitmq publisher - New - p.attemptConnect: %w", err)
+	}
+
+	return p, nil
+}
+
+func (p *Publisher) attemptConnect() error {
+	var err error
+	for i := p.attempts; i > 0; i-- {
+		if err = p.connect(); err == nil {
+			break
+		}
+
+		log.Printf("RabbitMQ publisher is trying to connect, attempts left: %d", i)
+		time.Sleep(p.waitTime)
+	}
+
+	return err
+}
+
+func (p *Publisher) connect() error {
+	var err error
+
+	p.conn, err = amqp.Dial(p.url)
+	if err != nil {
+		return fmt.Errorf("amqp.Dial: %w", err)
+	}
+
+	p.channel, err = p.conn.Channel()
+	if err != nil {
+		return fmt.Errorf("p.conn.Channel: %w", err)
+	}
+
+	// Durable exchange survives the broker restart, consumers bind their queues by the routing key patterns
+	err = p.channel.ExchangeDeclare(
+		p.exchange,
+		"topic",
+		true,
+		false,
+		false,
+		false,
+		nil,
+	)
+	if err != nil {
+		return fmt.Errorf("p.channel.ExchangeDeclare: %w", err)
+	}
+
+	err = p.channel.Confirm(false)
+	if err != nil {
+		return fmt.Errorf("p.channel.Confirm: %w", err)
+	}
+
+	p.confirms = p.channel.NotifyPublish(make(chan amqp.Confirmation, 1))
+
+	return nil
+}
+
+// Publish - publishing the message and waiting for the confirmation.
+// The connection is restored on the next call if it was lost.
+func (p *Publisher) Publish(ctx context.Context, m Message) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.closed {
+		return ErrClosed
+	}
+
+	if p.conn == nil || p.conn.IsClosed() {
+		if err := p.connect(); err != nil {
+			return fmt.Errorf("rabbitmq publisher - Publish - p.connect: %w", err)
+		}
+	}
+
+	err := p.channel.PublishWithContext(ctx, p.exchange, m.RoutingKey, false, false,
+		amqp.Publishing{
+			ContentType:  m.ContentType,
+			DeliveryMode: amqp.Persistent,
+			MessageId:    m.ID,
+			Type:         m.Type,
+			Timestamp:    m.Timestamp,
+			Body:         m.Body,
+		})
+	if err != nil {
+		p.reset()
+		return fmt.Errorf("rabbitmq publisher - Publish - p.channel.PublishWithContext: %w", err)
+	}
+
+	timer := time.NewTimer(p.confirmTimeout)
+	defer timer.Stop()
+
+	select {
+	case confirm, ok := <-p.confirms:
+		if !ok || !confirm.Ack {
+			p.reset()
+			return ErrNotConfirmed
+		}
+	case <-timer.C:
+		// Late confirmation would be taken as the confirmation of the next message
+		p.reset()
+		return ErrNotConfirmed
+	case <-ctx.Done():
+		p.reset()
+		return ctx.Err() //nolint:wrapcheck // we need just a send ctx error
+	}
+
+	return nil
+}
+
+// Closing the connection, so the next publish starts with a new channel.
+func (p *Publisher) reset() {
+	if p.conn != nil {
+		_ = p.conn.Close()
+	}
+	p.conn = nil
+}
+
+func (p *Publisher) Close() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.closed = true
+
+	if p.conn == nil || p.conn.IsClosed() {
+		return nil
+	}
+
+	err := p.conn.Close()
+	if err != nil {
+		return fmt.Errorf("rabbitmq publisher - Close - p.conn.Close: %w", err)
+	}
+
+	return nil
+}
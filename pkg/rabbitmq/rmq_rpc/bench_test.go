@@ -0,0 +1,138 @@
+package rmq_rpc_test
+
+import (
+	"WalletRieltaTestTask/pkg/rabbitmq/rmq_rpc"
+	"WalletRieltaTestTask/pkg/rabbitmq/rmq_rpc/client"
+	"WalletRieltaTestTask/pkg/rabbitmq/rmq_rpc/server"
+	"context"
+	"io"
+	"log/slog"
+	"os"
+	"strconv"
+	"testing"
+	"time"
+)
+
+// Очередь и exchange бенчмарка с RabbitMQ, как у cmd/rpcbench
+const _benchExchange = "rpc_bench"
+
+var _benchRequest = make([]byte, 256)
+
+func echoRouter() map[string]server.CallHandler {
+	return map[string]server.CallHandler{
+		"echo": server.Handle(func(_ context.Context, request []byte) ([]byte, error) {
+			return request, nil
+		}),
+	}
+}
+
+func discardLogger() *slog.Logger {
+	return slog.New(slog.NewTextHandler(io.Discard, nil))
+}
+
+// Shutdown ждет завершения вызовов, поэтому не задерживает следующий запуск бенчмарка
+func shutdown(b *testing.B, c *client.Client, s *server.Server) {
+	b.Cleanup(func() {
+		go func() {
+			_ = c.Shutdown()
+			_ = s.Shutdown()
+		}()
+	})
+}
+
+func remoteCalls(b *testing.B, c *client.Client) {
+	b.SetBytes(int64(len(_benchRequest)))
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			var response []byte
+			if err := c.RemoteCall(context.Background(), "echo", _benchRequest, &response); err != nil {
+				b.Error(err)
+
+				return
+			}
+		}
+	})
+}
+
+// Публикация вызовов параллельными клиентами без ответов сервера
+func BenchmarkMemoryPublishParallel(b *testing.B) {
+	broker := rmq_rpc.NewMemoryBroker()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	broker.Server(_benchExchange).Consume(ctx, 8, func(d *rmq_rpc.Delivery) {
+		_ = d.Ack()
+	})
+
+	b.SetBytes(int64(len(_benchRequest)))
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	b.RunParallel(func(pb *testing.PB) {
+		transport := broker.Client(_benchExchange)
+
+		for pb.Next() {
+			err := transport.Publish(ctx, rmq_rpc.Message{
+				Type:        "echo",
+				ContentType: "application/octet-stream",
+				Body:        _benchRequest,
+			})
+			if err != nil {
+				b.Error(err)
+
+				return
+			}
+		}
+	})
+}
+
+// Полный вызов через клиент и сервер, накладные расходы кодеков без брокера
+func BenchmarkMemoryRemoteCallParallel(b *testing.B) {
+	for _, codec := range []rmq_rpc.Codec{rmq_rpc.JSON(), rmq_rpc.MessagePack()} {
+		b.Run(codec.ContentType(), func(b *testing.B) {
+			broker := rmq_rpc.NewMemoryBroker()
+
+			s := server.NewWithTransport(broker.Server(_benchExchange), echoRouter(), discardLogger(),
+				server.DefaultGoroutinesCount(16))
+			s.MustRun()
+
+			c := client.New(broker.Client(_benchExchange), client.Codecs(codec), client.Timeout(10*time.Second))
+
+			shutdown(b, c, s)
+			remoteCalls(b, c)
+		})
+	}
+}
+
+// Пропускная способность пула каналов публикации с RabbitMQ из RMQ_BENCH_URL, без него пропускается.
+// go test -run '^$' -bench AMQP ./pkg/rabbitmq/rmq_rpc
+func BenchmarkAMQPRemoteCallParallel(b *testing.B) {
+	url := os.Getenv("RMQ_BENCH_URL")
+	if url == "" {
+		b.Skip("RMQ_BENCH_URL isn't set")
+	}
+
+	for _, channels := range []int{1, 4, 16} {
+		b.Run("channels="+strconv.Itoa(channels), func(b *testing.B) {
+			s, err := server.New(url, _benchExchange, echoRouter(), discardLogger(), server.DefaultGoroutinesCount(24))
+			if err != nil {
+				b.Fatal(err)
+			}
+
+			s.MustRun()
+
+			c, err := client.NewRabbitMQClient(url, _benchExchange,
+				client.Channels(channels), client.Timeout(10*time.Second))
+			if err != nil {
+				b.Fatal(err)
+			}
+
+			shutdown(b, c, s)
+			remoteCalls(b, c)
+		})
+	}
+}
@@ -4,21 +4,19 @@ import (
 	"WalletRieltaTestTask/pkg/rabbitmq/rmq_rpc"
 	"context"
 	"encoding/json"
-	"errors"
 	"fmt"
 	"github.com/google/uuid"
-	"github.com/streadway/amqp"
 	"sync"
 	"time"
 )
 
-var ErrConnectionClosed = errors.New("rmq_rpc client - Client - RemoteCall - Connection closed")
-
 const (
 	_defaultWaitTime = 2 * time.Second
 	_defaultAttempts = 10
 	_defaultTimeout  = 2 * time.Second
 
+	_defaultChannels = 16
+
 	Success = "success"
 )
 
@@ -37,190 +35,270 @@ type pendingCall struct {
 	done   chan struct{}
 	status string
 	body   []byte
+	err    error
+
+	contentType     string
+	contentEncoding string
 }
 
 // RPC-клиента
 type Client struct {
-	conn           *rmq_rpc.Connection
-	serverExchange string
-	error          chan error
-	stop           chan struct{}
+	transport rmq_rpc.ClientTransport
+	stop      <-chan struct{}
+	cancel    context.CancelFunc
 
 	rw    sync.RWMutex
 	calls map[string]*pendingCall
 
+	codes   *rmq_rpc.Registry
 	timeout time.Duration
+
+	// Запрос кодируется первым подходящим кодеком, сервер отвечает в одном из принятых клиентом форматов
+	codecs      rmq_rpc.Codecs
+	compression rmq_rpc.Compression
+
+	// Настройки транспорта RabbitMQ, вызов публикуется в свой канал и ждет его подтверждения
+	conn          *rmq_rpc.Connection
+	channelsCount int
 }
 
-// Создание клиента
-func NewRabbitMQClient(url, serverExchange, clientExchange string, opts ...Option) (*Client, error) {
+// Создание клиента, ответы сервера приходят в эксклюзивную очередь клиента
+func NewRabbitMQClient(url, serverExchange string, opts ...Option) (*Client, error) {
 	cfg := rmq_rpc.Config{
 		URL:      url,
 		WaitTime: _defaultWaitTime,
 		Attempts: _defaultAttempts,
+		Name:     "client:" + serverExchange,
 	}
 
-	c := &Client{
-		conn:           rmq_rpc.NewConnectionRabbitMQ(clientExchange, cfg),
-		serverExchange: serverExchange,
-		error:          make(chan error),
-		stop:           make(chan struct{}),
-		calls:          make(map[string]*pendingCall),
-		timeout:        _defaultTimeout,
-	}
-
-	for _, opt := range opts {
-		opt(c)
-	}
+	c := newClient(rmq_rpc.NewConnectionRabbitMQ("", cfg), opts)
 
-	err := c.conn.AttemptConnect()
+	transport, err := rmq_rpc.NewAMQPClient(c.conn, serverExchange, c.channelsCount)
 	if err != nil {
-		return nil, fmt.Errorf("rmq_rpc client - NewClient - c.conn.AttemptConnect: %w", err)
+		return nil, fmt.Errorf("rmq_rpc client - NewClient - rmq_rpc.NewAMQPClient: %w", err)
 	}
 
-	// Прослушивание сообщений
-	go c.consumer()
+	c.start(transport)
 
 	return c, nil
 }
 
-// Публикует сообщение в RabbitMQ
-func (c *Client) publish(corrID, handler string, request interface{}) error {
-	var (
-		requestBody []byte
-		err         error
-	)
+// New - клиент поверх транспорта, например rmq_rpc.MemoryBroker в тестах.
+// Опции соединения RabbitMQ игнорируются
+func New(transport rmq_rpc.ClientTransport, opts ...Option) *Client {
+	c := newClient(rmq_rpc.NewConnectionRabbitMQ("", rmq_rpc.Config{}), opts)
 
-	if request != nil {
-		requestBody, err = json.Marshal(request)
-		if err != nil {
-			return fmt.Errorf("publish - json.Marshal: %w", err)
-		}
+	c.start(transport)
+
+	return c
+}
+
+func newClient(conn *rmq_rpc.Connection, opts []Option) *Client {
+	c := &Client{
+		calls:         make(map[string]*pendingCall),
+		codes:         rmq_rpc.NewRegistry(),
+		timeout:       _defaultTimeout,
+		codecs:        rmq_rpc.Codecs{rmq_rpc.JSON()},
+		conn:          conn,
+		channelsCount: _defaultChannels,
 	}
 
-	err = c.conn.Channel.Publish(c.serverExchange, "", false, false,
-		amqp.Publishing{
-			ContentType:   "application/json",
-			CorrelationId: corrID,
-			ReplyTo:       c.conn.ConsumerExchange,
-			Type:          handler,
-			Body:          requestBody,
-		})
-	if err != nil {
-		return fmt.Errorf("c.Channel.Publish: %w", err)
+	for _, opt := range opts {
+		opt(c)
 	}
 
-	return nil
+	return c
+}
+
+// Прослушивание ответов. Вызовы, ожидающие ответа, завершаются с ошибкой,
+// если ответ на них может не прийти
+func (c *Client) start(transport rmq_rpc.ClientTransport) {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	c.transport = transport
+	c.stop = ctx.Done()
+	c.cancel = cancel
+
+	c.transport.Consume(ctx, c.getCall, c.failCalls)
 }
 
 func (c *Client) RemoteCall(ctx context.Context, handler string, request, response interface{}) error {
 	// Если клиент не завершился
 	select {
 	case <-c.stop:
-		time.Sleep(c.timeout)
-		select {
-		case <-c.stop:
-			return ErrConnectionClosed
-		default:
-		}
+		return rmq_rpc.ErrConnectionClosed
 	default:
 	}
 
-	corrID := uuid.New().String()
+	if err := ctx.Err(); err != nil {
+		return err //nolint:wrapcheck // just sending raw error
+	}
 
-	err := c.publish(corrID, handler, request)
+	msg, err := c.request(handler, request)
 	if err != nil {
-		return fmt.Errorf("rmq_rpc client - Client - RemoteCall - c.publish: %w", err)
+		return err
 	}
 
+	corrID := uuid.New().String()
+	msg.CorrelationID = corrID
+
 	call := &pendingCall{done: make(chan struct{})}
 
-	// Добавляет вызов в c.calls, чтобы отслеживать его статус.
+	// Добавляет вызов в c.calls до публикации, чтобы не пропустить быстрый ответ
 	c.addCall(corrID, call)
 	defer c.deleteCall(corrID)
 
+	// Клиент ждет ответ не дольше таймаута, сервер не выполняет вызов после дедлайна
+	callCtx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	msg.Deadline, _ = callCtx.Deadline()
+
+	err = c.transport.Publish(callCtx, msg)
+	if err != nil {
+		if callCtx.Err() != nil {
+			return expired(ctx)
+		}
+
+		return fmt.Errorf("rmq_rpc client - Client - RemoteCall - c.transport.Publish: %w", err)
+	}
+
 	// Если не выполняется за определенное время то ошибка
 	select {
-	case <-time.After(c.timeout):
-		return rmq_rpc.ErrTimeout
-	case <-ctx.Done():
-		return ctx.Err() //nolint:wrapcheck // just sending raw error
+	case <-callCtx.Done():
+		return expired(ctx)
 	case <-call.done:
 	}
 
 	return c.handleCallStatus(call, response)
 }
 
-func (c *Client) handleCallStatus(call *pendingCall, response interface{}) error {
-	if call.status == Success {
-		err := json.Unmarshal(call.body, &response)
-		if err != nil {
-			return fmt.Errorf("rmq_rpc client - Client - handleCallStatus - json.Unmarshal: %w", err)
-		}
-
-		return nil
+// Сообщение вызова с телом в формате кодека и форматами ответа, которые принимает клиент
+func (c *Client) request(handler string, request interface{}) (rmq_rpc.Message, error) {
+	msg := rmq_rpc.Message{
+		Type:        handler,
+		ContentType: rmq_rpc.JSON().ContentType(),
+		Headers:     map[string]interface{}{rmq_rpc.AcceptHeader: c.codecs.Accept()},
 	}
 
-	if call.status == rmq_rpc.ErrBadHandler.Error() {
-		return rmq_rpc.ErrBadHandler
+	if len(c.compression.Compressors) > 0 {
+		msg.Headers[rmq_rpc.AcceptEncodingHeader] = c.compression.AcceptEncoding()
 	}
 
-	if call.status == rmq_rpc.ErrNotFound.Error() {
-		return rmq_rpc.ErrNotFound
+	if request == nil {
+		return msg, nil
 	}
 
-	return fmt.Errorf("%w: %v", rmq_rpc.ErrCallStatus, call.status)
-}
+	codec, body, err := c.codecs.Marshal(request)
+	if err != nil {
+		return msg, fmt.Errorf("rmq_rpc client - Client - request - c.codecs.Marshal: %w", err)
+	}
 
-func (c *Client) consumer() {
-	for {
-		select {
-		case <-c.stop:
-			return
-		case d, opened := <-c.conn.Delivery:
-			if !opened {
-				c.reconnect()
+	// Сервер распаковывает встроенные алгоритмы сжатия без настройки
+	encoding, body, err := c.compression.Compress(c.compression.AcceptEncoding(), body)
+	if err != nil {
+		return msg, fmt.Errorf("rmq_rpc client - Client - request - c.compression.Compress: %w", err)
+	}
 
-				return
-			}
+	msg.ContentType = codec.ContentType()
+	msg.ContentEncoding = encoding
+	msg.Body = body
 
-			_ = d.Ack(false)
+	return msg, nil
+}
 
-			c.getCall(&d)
-		}
+// Ошибка вызова, не дождавшегося ответа: контекст вызывающего или таймаут клиента
+func expired(ctx context.Context) error {
+	if err := ctx.Err(); err != nil {
+		return err //nolint:wrapcheck // just sending raw error
 	}
+
+	return rmq_rpc.ErrTimeout
 }
 
-func (c *Client) reconnect() {
-	close(c.stop)
+func (c *Client) handleCallStatus(call *pendingCall, response interface{}) error {
+	if call.err != nil {
+		return call.err
+	}
 
-	err := c.conn.AttemptConnect()
+	body, err := c.compression.Decompress(call.contentEncoding, call.body)
 	if err != nil {
-		c.error <- err
-		close(c.error)
+		return fmt.Errorf("rmq_rpc client - Client - handleCallStatus - c.compression.Decompress: %w", err)
+	}
 
-		return
+	if call.status == Success {
+		// Ответ без результата не декодируется
+		if response == nil {
+			return nil
+		}
+
+		codec, err := c.codecs.Get(call.contentType)
+		if err != nil {
+			return fmt.Errorf("rmq_rpc client - Client - handleCallStatus - c.codecs.Get: %w", err)
+		}
+
+		err = codec.Unmarshal(body, response)
+		if err != nil {
+			return fmt.Errorf("rmq_rpc client - Client - handleCallStatus - codec.Unmarshal: %w", err)
+		}
+
+		return nil
 	}
 
-	c.stop = make(chan struct{})
+	// Ошибка сервера восстанавливается по коду, чтобы работал errors.Is
+	if call.status == rmq_rpc.ErrorStatus {
+		var rpcErr rmq_rpc.Error
 
-	go c.consumer()
-}
+		err := json.Unmarshal(body, &rpcErr)
+		if err != nil {
+			return fmt.Errorf("rmq_rpc client - Client - handleCallStatus - json.Unmarshal: %w", err)
+		}
 
-func (c *Client) getCall(d *amqp.Delivery) {
-	c.rw.RLock()
-	call, ok := c.calls[d.CorrelationId]
-	c.rw.RUnlock()
+		return c.codes.Decode(&rpcErr)
+	}
 
+	return fmt.Errorf("%w: %v", rmq_rpc.ErrCallStatus, call.status)
+}
+
+func (c *Client) getCall(reply rmq_rpc.Message) {
+	call, ok := c.takeCall(reply.CorrelationID)
 	if !ok {
 		return
 	}
 
-	call.status = d.Type
-	call.body = d.Body
+	call.status = reply.Type
+	call.body = reply.Body
+	call.contentType = reply.ContentType
+	call.contentEncoding = reply.ContentEncoding
 	close(call.done)
 }
 
+// Завершает с ошибкой все вызовы, ожидающие ответа
+func (c *Client) failCalls(err error) {
+	c.rw.Lock()
+	calls := c.calls
+	c.calls = make(map[string]*pendingCall)
+	c.rw.Unlock()
+
+	for _, call := range calls {
+		call.err = err
+		close(call.done)
+	}
+
+	rmq_rpc.LostCalls.WithLabelValues(c.conn.Name).Add(float64(len(calls)))
+}
+
+// Забирает вызов из c.calls, так что повторный ответ сервера на него не приходит
+func (c *Client) takeCall(corrID string) (*pendingCall, bool) {
+	c.rw.Lock()
+	defer c.rw.Unlock()
+
+	call, ok := c.calls[corrID]
+	delete(c.calls, corrID)
+
+	return call, ok
+}
+
 func (c *Client) addCall(corrID string, call *pendingCall) {
 	c.rw.Lock()
 	c.calls[corrID] = call
@@ -234,22 +312,22 @@ func (c *Client) deleteCall(corrID string) {
 }
 
 func (c *Client) Notify() <-chan error {
-	return c.error
+	return c.transport.Notify()
 }
 
 func (c *Client) Shutdown() error {
 	select {
-	case <-c.error:
+	case <-c.transport.Notify():
 		return nil
 	default:
 	}
 
-	close(c.stop)
+	c.cancel()
 	time.Sleep(c.timeout)
 
-	err := c.conn.Connection.Close()
+	err := c.transport.Close()
 	if err != nil {
-		return fmt.Errorf("rmq_rpc client - Client - Shutdown - c.Connection.Close: %w", err)
+		return fmt.Errorf("rmq_rpc client - Client - Shutdown - c.transport.Close: %w", err)
 	}
 
 	return nil
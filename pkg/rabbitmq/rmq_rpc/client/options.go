@@ -1,6 +1,9 @@
 package client
 
-import "time"
+import (
+	"WalletRieltaTestTask/pkg/rabbitmq/rmq_rpc"
+	"time"
+)
 
 type Option func(*Client)
 
@@ -21,3 +24,40 @@ func ConnAttempts(attempts int) Option {
 		c.conn.Attempts = attempts
 	}
 }
+
+// ConnMaxWaitTime - предел экспоненциальной задержки между попытками подключения
+func ConnMaxWaitTime(timeout time.Duration) Option {
+	return func(c *Client) {
+		c.conn.MaxWaitTime = timeout
+	}
+}
+
+// Errors - коды ошибок, восстанавливаемых из ответов, сервер должен зарегистрировать те же коды
+func Errors(registry *rmq_rpc.Registry) Option {
+	return func(c *Client) {
+		c.codes = registry
+	}
+}
+
+// Channels - каналы для публикации вызовов, вызов занимает канал до подтверждения брокером
+func Channels(count int) Option {
+	return func(c *Client) {
+		c.channelsCount = count
+	}
+}
+
+// Codecs - кодеки в порядке предпочтения. Запрос кодируется первым кодеком, поддерживающим его тип,
+// сервер отвечает первым из них, который у него есть. По умолчанию JSON
+func Codecs(codecs ...rmq_rpc.Codec) Option {
+	return func(c *Client) {
+		c.codecs = codecs
+	}
+}
+
+// Compression - запросы от threshold байт сжимаются первым алгоритмом,
+// сервер сжимает большие ответы первым из них, который у него есть. По умолчанию выключено
+func Compression(threshold int, compressors ...rmq_rpc.Compressor) Option {
+	return func(c *Client) {
+		c.compression = rmq_rpc.Compression{Threshold: threshold, Compressors: compressors}
+	}
+}
@@ -0,0 +1,170 @@
+package rmq_rpc
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/vmihailenco/msgpack/v5"
+	"google.golang.org/protobuf/proto"
+	"strings"
+)
+
+const (
+	// AcceptHeader - content types ответа, которые принимает клиент, в порядке предпочтения
+	AcceptHeader = "x-accept"
+	// AcceptEncodingHeader - алгоритмы сжатия ответа, которые принимает клиент, в порядке предпочтения
+	AcceptEncodingHeader = "x-accept-encoding"
+)
+
+var (
+	// ErrUnsupportedType - кодек не кодирует значение, например protobuf обычной структуры
+	ErrUnsupportedType = errors.New("type isn't supported by the codec")
+	// ErrContentType - тело закодировано неизвестным кодеком
+	ErrContentType = errors.New("unsupported content type")
+)
+
+// Codec - кодирование тел вызовов и ответов, выбирается по content type сообщения
+type Codec interface {
+	ContentType() string
+	Marshal(v interface{}) ([]byte, error)
+	Unmarshal(data []byte, v interface{}) error
+}
+
+// Codecs - кодеки в порядке предпочтения, если ни один не подходит, используется JSON
+type Codecs []Codec
+
+// Get - кодек content type, сообщения без content type в JSON
+func (cs Codecs) Get(contentType string) (Codec, error) {
+	if contentType == "" {
+		return JSON(), nil
+	}
+
+	// Встроенные кодеки декодируются и без настройки
+	for _, c := range append(cs[:len(cs):len(cs)], JSON(), Protobuf(), MessagePack()) {
+		if c.ContentType() == contentType {
+			return c, nil
+		}
+	}
+
+	return nil, fmt.Errorf("%w: %s", ErrContentType, contentType)
+}
+
+// Accepted - кодеки из accept заголовка в его порядке, которые есть в списке
+func (cs Codecs) Accepted(accept string) Codecs {
+	var accepted Codecs
+
+	for _, contentType := range strings.Split(accept, ",") {
+		for _, c := range cs {
+			if c.ContentType() == strings.TrimSpace(contentType) {
+				accepted = append(accepted, c)
+			}
+		}
+	}
+
+	return accepted
+}
+
+// Accept - значение accept заголовка
+func (cs Codecs) Accept() string {
+	contentTypes := make([]string, 0, len(cs))
+	for _, c := range cs {
+		contentTypes = append(contentTypes, c.ContentType())
+	}
+
+	return strings.Join(contentTypes, ",")
+}
+
+// Marshal - кодирует значение первым кодеком, поддерживающим его тип
+func (cs Codecs) Marshal(v interface{}) (Codec, []byte, error) {
+	for _, c := range append(cs[:len(cs):len(cs)], JSON()) {
+		data, err := c.Marshal(v)
+		if errors.Is(err, ErrUnsupportedType) {
+			continue
+		}
+
+		return c, data, err
+	}
+
+	return nil, nil, ErrUnsupportedType
+}
+
+type jsonCodec struct{}
+
+func JSON() Codec {
+	return jsonCodec{}
+}
+
+func (jsonCodec) ContentType() string {
+	return "application/json"
+}
+
+func (jsonCodec) Marshal(v interface{}) ([]byte, error) {
+	return json.Marshal(v) //nolint:wrapcheck // codec errors are wrapped by the caller
+}
+
+func (jsonCodec) Unmarshal(data []byte, v interface{}) error {
+	return json.Unmarshal(data, v) //nolint:wrapcheck // codec errors are wrapped by the caller
+}
+
+type protobufCodec struct{}
+
+// Protobuf - кодек сгенерированных protobuf сообщений, другие типы кодируются следующим кодеком
+func Protobuf() Codec {
+	return protobufCodec{}
+}
+
+func (protobufCodec) ContentType() string {
+	return "application/x-protobuf"
+}
+
+func (protobufCodec) Marshal(v interface{}) ([]byte, error) {
+	m, ok := v.(proto.Message)
+	if !ok {
+		return nil, fmt.Errorf("%w: %T", ErrUnsupportedType, v)
+	}
+
+	return proto.Marshal(m) //nolint:wrapcheck // codec errors are wrapped by the caller
+}
+
+func (protobufCodec) Unmarshal(data []byte, v interface{}) error {
+	m, ok := v.(proto.Message)
+	if !ok {
+		return fmt.Errorf("%w: %T", ErrUnsupportedType, v)
+	}
+
+	return proto.Unmarshal(data, m) //nolint:wrapcheck // codec errors are wrapped by the caller
+}
+
+type msgpackCodec struct{}
+
+// MessagePack - бинарный кодек тех же структур, что и JSON, поля называются по json тегам.
+// Время декодируется в локальной временной зоне
+func MessagePack() Codec {
+	return msgpackCodec{}
+}
+
+func (msgpackCodec) ContentType() string {
+	return "application/msgpack"
+}
+
+func (msgpackCodec) Marshal(v interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+
+	enc := msgpack.NewEncoder(&buf)
+	enc.SetCustomStructTag("json")
+	enc.UseCompactInts(true)
+
+	if err := enc.Encode(v); err != nil {
+		return nil, err //nolint:wrapcheck // codec errors are wrapped by the caller
+	}
+
+	return buf.Bytes(), nil
+}
+
+func (msgpackCodec) Unmarshal(data []byte, v interface{}) error {
+	dec := msgpack.NewDecoder(bytes.NewReader(data))
+	dec.SetCustomStructTag("json")
+
+	return dec.Decode(v) //nolint:wrapcheck // codec errors are wrapped by the caller
+}
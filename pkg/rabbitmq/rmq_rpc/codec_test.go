@@ -0,0 +1,82 @@
+package rmq_rpc
+
+import (
+	"errors"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+	"reflect"
+	"testing"
+	"time"
+)
+
+type codecTransfer struct {
+	From   string    `json:"from"`
+	Amount uint      `json:"amount"`
+	Time   time.Time `json:"time"`
+}
+
+func TestCodecRoundTrip(t *testing.T) {
+	transfer := codecTransfer{From: "alice", Amount: 30, Time: time.Date(2024, 5, 1, 12, 0, 0, 0, time.Local)}
+
+	for _, codec := range []Codec{JSON(), MessagePack()} {
+		t.Run(codec.ContentType(), func(t *testing.T) {
+			data, err := codec.Marshal(transfer)
+			if err != nil {
+				t.Fatalf("Marshal: %v", err)
+			}
+
+			var decoded codecTransfer
+			if err = codec.Unmarshal(data, &decoded); err != nil {
+				t.Fatalf("Unmarshal: %v", err)
+			}
+
+			if !decoded.Time.Equal(transfer.Time) {
+				t.Fatalf("time = %s, want %s", decoded.Time, transfer.Time)
+			}
+
+			decoded.Time = transfer.Time
+			if !reflect.DeepEqual(decoded, transfer) {
+				t.Fatalf("decoded = %+v, want %+v", decoded, transfer)
+			}
+		})
+	}
+
+	t.Run(Protobuf().ContentType(), func(t *testing.T) {
+		data, err := Protobuf().Marshal(wrapperspb.String("alice"))
+		if err != nil {
+			t.Fatalf("Marshal: %v", err)
+		}
+
+		decoded := &wrapperspb.StringValue{}
+		if err = Protobuf().Unmarshal(data, decoded); err != nil {
+			t.Fatalf("Unmarshal: %v", err)
+		}
+
+		if !proto.Equal(decoded, wrapperspb.String("alice")) {
+			t.Fatalf("decoded = %v, want alice", decoded)
+		}
+	})
+}
+
+// Обычные структуры protobuf не кодирует, они уходят следующему кодеку
+func TestProtobufUnsupportedType(t *testing.T) {
+	if _, err := Protobuf().Marshal(codecTransfer{}); !errors.Is(err, ErrUnsupportedType) {
+		t.Fatalf("Marshal = %v, want %v", err, ErrUnsupportedType)
+	}
+
+	if err := Protobuf().Unmarshal(nil, &codecTransfer{}); !errors.Is(err, ErrUnsupportedType) {
+		t.Fatalf("Unmarshal = %v, want %v", err, ErrUnsupportedType)
+	}
+
+	codecs := Codecs{Protobuf(), JSON()}
+
+	codec, _, err := codecs.Marshal(codecTransfer{})
+	if err != nil || codec.ContentType() != JSON().ContentType() {
+		t.Fatalf("Marshal = %v, %v, want the JSON codec", codec, err)
+	}
+
+	codec, _, err = codecs.Marshal(wrapperspb.String("alice"))
+	if err != nil || codec.ContentType() != Protobuf().ContentType() {
+		t.Fatalf("Marshal = %v, %v, want the Protobuf codec", codec, err)
+	}
+}
@@ -0,0 +1,184 @@
+package rmq_rpc
+
+import (
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"github.com/klauspost/compress/zstd"
+	"io"
+	"strings"
+	"sync"
+)
+
+// Сжатое тело небольшого размера может распаковаться в гигабайты, поэтому размер распакованного тела ограничен
+const _maxDecompressedSize = 64 << 20
+
+var (
+	// ErrContentEncoding - тело сжато неизвестным алгоритмом
+	ErrContentEncoding = errors.New("unsupported content encoding")
+	// ErrBodyTooLarge - распакованное тело больше лимита
+	ErrBodyTooLarge = errors.New("decompressed body is too large")
+)
+
+// Compressor - сжатие тел, выбирается по content encoding сообщения
+type Compressor interface {
+	Encoding() string
+	Compress(data []byte) ([]byte, error)
+	Decompress(data []byte) ([]byte, error)
+}
+
+// Compression - тела от Threshold байт сжимаются первым алгоритмом, который принимает получатель.
+// Без алгоритмов сжатие выключено
+type Compression struct {
+	Threshold   int
+	Compressors []Compressor
+}
+
+// AcceptEncoding - значение accept encoding заголовка
+func (c *Compression) AcceptEncoding() string {
+	encodings := make([]string, 0, len(c.Compressors))
+	for _, compressor := range c.Compressors {
+		encodings = append(encodings, compressor.Encoding())
+	}
+
+	return strings.Join(encodings, ",")
+}
+
+// Compress - возвращает тело как есть, если оно маленькое или ни один алгоритм не принимается
+func (c *Compression) Compress(acceptEncoding string, data []byte) (string, []byte, error) {
+	if len(c.Compressors) == 0 || len(data) < c.Threshold {
+		return "", data, nil
+	}
+
+	for _, encoding := range strings.Split(acceptEncoding, ",") {
+		for _, compressor := range c.Compressors {
+			if compressor.Encoding() != strings.TrimSpace(encoding) {
+				continue
+			}
+
+			compressed, err := compressor.Compress(data)
+			if err != nil {
+				return "", nil, fmt.Errorf("rmq_rpc - Compression - Compress - compressor.Compress: %w", err)
+			}
+
+			return compressor.Encoding(), compressed, nil
+		}
+	}
+
+	return "", data, nil
+}
+
+// Decompress - встроенные алгоритмы распаковываются, даже если их нет в списке
+func (c *Compression) Decompress(encoding string, data []byte) ([]byte, error) {
+	if encoding == "" {
+		return data, nil
+	}
+
+	for _, compressor := range append(c.Compressors[:len(c.Compressors):len(c.Compressors)], Gzip(), Zstd()) {
+		if compressor.Encoding() != encoding {
+			continue
+		}
+
+		data, err := compressor.Decompress(data)
+		if err != nil {
+			return nil, fmt.Errorf("rmq_rpc - Compression - Decompress - compressor.Decompress: %w", err)
+		}
+
+		return data, nil
+	}
+
+	return nil, fmt.Errorf("%w: %s", ErrContentEncoding, encoding)
+}
+
+type gzipCompressor struct{}
+
+func Gzip() Compressor {
+	return gzipCompressor{}
+}
+
+func (gzipCompressor) Encoding() string {
+	return "gzip"
+}
+
+func (gzipCompressor) Compress(data []byte) ([]byte, error) {
+	var buf bytes.Buffer
+
+	w := gzip.NewWriter(&buf)
+
+	if _, err := w.Write(data); err != nil {
+		return nil, fmt.Errorf("w.Write: %w", err)
+	}
+
+	if err := w.Close(); err != nil {
+		return nil, fmt.Errorf("w.Close: %w", err)
+	}
+
+	return buf.Bytes(), nil
+}
+
+func (gzipCompressor) Decompress(data []byte) ([]byte, error) {
+	r, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("gzip.NewReader: %w", err)
+	}
+	defer r.Close()
+
+	data, err = io.ReadAll(io.LimitReader(r, _maxDecompressedSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("io.ReadAll: %w", err)
+	}
+
+	if len(data) > _maxDecompressedSize {
+		return nil, ErrBodyTooLarge
+	}
+
+	return data, nil
+}
+
+// Кодировщик и декодировщик zstd создаются один раз, EncodeAll и DecodeAll безопасны для горутин
+var (
+	zstdOnce    sync.Once
+	zstdEncoder *zstd.Encoder
+	zstdDecoder *zstd.Decoder
+)
+
+type zstdCompressor struct{}
+
+func Zstd() Compressor {
+	return zstdCompressor{}
+}
+
+func (zstdCompressor) Encoding() string {
+	return "zstd"
+}
+
+func (zstdCompressor) Compress(data []byte) ([]byte, error) {
+	zstdOnce.Do(initZstd)
+
+	return zstdEncoder.EncodeAll(data, nil), nil
+}
+
+func (zstdCompressor) Decompress(data []byte) ([]byte, error) {
+	zstdOnce.Do(initZstd)
+
+	data, err := zstdDecoder.DecodeAll(data, nil)
+	if errors.Is(err, zstd.ErrDecoderSizeExceeded) {
+		return nil, ErrBodyTooLarge
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("zstdDecoder.DecodeAll: %w", err)
+	}
+
+	return data, nil
+}
+
+func initZstd() {
+	// Без writer и reader ошибок нет, опции заданы корректно
+	zstdEncoder, _ = zstd.NewWriter(nil)
+	zstdDecoder, _ = zstd.NewReader(nil,
+		zstd.WithDecoderConcurrency(0),
+		zstd.WithDecoderMaxMemory(_maxDecompressedSize),
+	)
+}
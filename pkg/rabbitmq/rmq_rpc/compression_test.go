@@ -0,0 +1,64 @@
+package rmq_rpc
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+)
+
+func TestCompressionRoundTrip(t *testing.T) {
+	data := bytes.Repeat([]byte("wallet "), 1000)
+
+	for _, compressor := range []Compressor{Gzip(), Zstd()} {
+		t.Run(compressor.Encoding(), func(t *testing.T) {
+			compression := Compression{Threshold: 1, Compressors: []Compressor{compressor}}
+
+			encoding, compressed, err := compression.Compress(compressor.Encoding(), data)
+			if err != nil || encoding != compressor.Encoding() || len(compressed) >= len(data) {
+				t.Fatalf("Compress = %s, %d bytes, %v", encoding, len(compressed), err)
+			}
+
+			// Получатель распаковывает встроенные алгоритмы и без настройки
+			decompressed, err := (&Compression{}).Decompress(encoding, compressed)
+			if err != nil || !bytes.Equal(decompressed, data) {
+				t.Fatalf("Decompress = %d bytes, %v", len(decompressed), err)
+			}
+		})
+	}
+}
+
+// Тело, распаковывающееся больше лимита, отклоняется, а не читается в память целиком
+func TestDecompressTooLarge(t *testing.T) {
+	bomb := make([]byte, _maxDecompressedSize+1)
+
+	for _, compressor := range []Compressor{Gzip(), Zstd()} {
+		t.Run(compressor.Encoding(), func(t *testing.T) {
+			compressed, err := compressor.Compress(bomb)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			_, err = (&Compression{}).Decompress(compressor.Encoding(), compressed)
+			if !errors.Is(err, ErrBodyTooLarge) {
+				t.Fatalf("err = %v, want %v", err, ErrBodyTooLarge)
+			}
+
+			// Тело размером с лимит распаковывается
+			compressed, err = compressor.Compress(bomb[:_maxDecompressedSize])
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if _, err = (&Compression{}).Decompress(compressor.Encoding(), compressed); err != nil {
+				t.Fatalf("Decompress of the limit: %v", err)
+			}
+		})
+	}
+}
+
+func TestDecompressUnknownEncoding(t *testing.T) {
+	_, err := (&Compression{}).Decompress("br", []byte{1})
+	if !errors.Is(err, ErrContentEncoding) {
+		t.Fatalf("err = %v, want %v", err, ErrContentEncoding)
+	}
+}
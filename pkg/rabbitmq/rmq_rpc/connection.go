@@ -1,71 +1,232 @@
 package rmq_rpc
 
 import (
+	"errors"
 	"fmt"
-	"github.com/streadway/amqp"
+	amqp "github.com/rabbitmq/amqp091-go"
 	"log"
+	"math/rand"
+	"sync"
+	"sync/atomic"
 	"time"
 )
 
+const _defaultMaxWaitTime = 30 * time.Second
+
 type Config struct {
 	URL      string
 	WaitTime time.Duration
 	Attempts int
+	// MaxWaitTime - предел экспоненциальной задержки между попытками, начиная с WaitTime
+	MaxWaitTime time.Duration
+	// Name - метка соединения в метриках
+	Name string
+}
+
+// State - состояние соединения
+type State int32
+
+const (
+	StateConnecting State = iota
+	StateConnected
+	StateReconnecting
+	StateClosed
+)
+
+// CallsExchange - direct exchange вызовов сервера. Прежние версии публиковали вызовы в неустойчивый fanout
+// exchange с именем сервера, а тип и устойчивость exchange нельзя изменить повторным объявлением,
+// поэтому у direct exchange свое имя
+func CallsExchange(serverExchange string) string {
+	return serverExchange + ".direct"
 }
 
 type Connection struct {
+	// ConsumerExchange - имя сервера. Общая устойчивая quorum очередь "<ConsumerExchange>.calls" привязана
+	// к direct exchange CallsExchange(ConsumerExchange) с ключом маршрутизации ConsumerExchange. Конкурирующие
+	// потребители очереди получают каждое сообщение один раз. Если он пустой, соединение читает свою
+	// эксклюзивную очередь
 	ConsumerExchange string
+	// DeadLetterExchange - fanout exchange сообщений, которые потребители не смогли обработать,
+	// они хранятся в очереди "<ConsumerExchange>.dead"
+	DeadLetterExchange string
+	// Prefetch - неподтвержденные доставки канала, брокер ждет подтверждений, прежде чем отправить еще.
+	// Без ограничения, если ноль
+	Prefetch int
+	// OnChannel - вызывается для каждого открытого канала до начала чтения, например для включения подтверждений
+	OnChannel func(ch *amqp.Channel) error
 	Config
-	Connection *amqp.Connection
-	Channel    *amqp.Channel
-	Delivery   <-chan amqp.Delivery // Канал для получения доставленных сообщений
+
+	mu         sync.RWMutex
+	connection *amqp.Connection
+	channel    *amqp.Channel
+	delivery   <-chan amqp.Delivery // Канал для получения доставленных сообщений
+	queue      string
+	connClosed chan *amqp.Error
+	chanClosed chan *amqp.Error
+
+	state     atomic.Int32
+	done      chan struct{}
+	closeOnce sync.Once
 }
 
 func NewConnectionRabbitMQ(consumerExchange string, cfg Config) *Connection {
+	if cfg.MaxWaitTime == 0 {
+		cfg.MaxWaitTime = _defaultMaxWaitTime
+	}
+
 	conn := &Connection{
 		ConsumerExchange: consumerExchange,
 		Config:           cfg,
+		done:             make(chan struct{}),
 	}
 
 	return conn
 }
 
+// Channel - текущий канал соединения, он заменяется при восстановлении соединения
+func (c *Connection) Channel() *amqp.Channel {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	return c.channel
+}
+
+// Delivery - сообщения текущего канала, закрывается при потере канала
+func (c *Connection) Delivery() <-chan amqp.Delivery {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	return c.delivery
+}
+
+// Queue - имя читаемой очереди, в эксклюзивную очередь сообщения приходят через default exchange
+func (c *Connection) Queue() string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	return c.queue
+}
+
+func (c *Connection) State() State {
+	return State(c.state.Load())
+}
+
+func (c *Connection) setState(state State) {
+	c.state.Store(int32(state))
+
+	up := 0.0
+	if state == StateConnected {
+		up = 1
+	}
+
+	connectionUp.WithLabelValues(c.Name).Set(up)
+}
+
 func (c *Connection) AttemptConnect() error {
 	var err error
-	for i := c.Attempts; i > 0; i-- {
+	for i := 0; i < c.Attempts; i++ {
 		if err = c.connect(); err == nil {
+			c.setState(StateConnected)
+
+			return nil
+		}
+
+		connectFailures.WithLabelValues(c.Name).Inc()
+
+		if i == c.Attempts-1 {
 			break
 		}
 
-		log.Printf("RabbitMQ is trying to connect, attempts left: %d", i)
-		time.Sleep(c.WaitTime)
+		wait := c.backoff(i)
+		log.Printf("RabbitMQ is trying to connect, attempts left: %d, next attempt in %s", c.Attempts-i-1, wait)
+
+		select {
+		case <-c.done:
+			return ErrConnectionClosed
+		case <-time.After(wait):
+		}
 	}
 
-	if err != nil {
-		return fmt.Errorf("rmq_rpc - AttemptConnect - c.connect: %w", err)
+	return fmt.Errorf("rmq_rpc - AttemptConnect - c.connect: %w", err)
+}
+
+// Экспоненциальная задержка, половина ожидания случайна, чтобы клиенты не переподключались одновременно
+func (c *Connection) backoff(attempt int) time.Duration {
+	wait := c.MaxWaitTime
+	if attempt < 32 && c.WaitTime<<attempt > 0 && c.WaitTime<<attempt < c.MaxWaitTime {
+		wait = c.WaitTime << attempt
 	}
 
-	return nil
+	return wait/2 + time.Duration(rand.Int63n(int64(wait/2)+1)) //nolint:gosec // jitter doesn't need crypto rand
 }
 
 func (c *Connection) connect() error {
-	var err error
-
 	// Установка соединения с сервером RabbitMQ
-	c.Connection, err = amqp.Dial(c.URL)
+	connection, err := amqp.Dial(c.URL)
 	if err != nil {
 		return fmt.Errorf("amqp.Dial: %w", err)
 	}
 
+	connClosed := connection.NotifyClose(make(chan *amqp.Error, 1))
+
+	c.mu.Lock()
+	c.connection = connection
+	c.connClosed = connClosed
+	// Эксклюзивная очередь удаляется вместе с прежним соединением
+	c.queue = ""
+	c.mu.Unlock()
+
+	err = c.openChannel()
+	if err != nil {
+		_ = connection.Close()
+
+		return err
+	}
+
+	return nil
+}
+
+// Открывает канал на текущем соединении, объявляет очередь и начинает чтение
+func (c *Connection) openChannel() error {
+	c.mu.RLock()
+	connection, queueName := c.connection, c.queue
+	c.mu.RUnlock()
+
 	// Открытие канала для связи
-	c.Channel, err = c.Connection.Channel()
+	channel, err := connection.Channel()
 	if err != nil {
-		return fmt.Errorf("c.Connection.Channel: %w", err)
+		return fmt.Errorf("connection.Channel: %w", err)
 	}
 
-	err = c.Channel.ExchangeDeclare(
-		c.ConsumerExchange,
-		"fanout", // доставляет сообщения всем связанным очередям
+	chanClosed := channel.NotifyClose(make(chan *amqp.Error, 1))
+
+	queue, err := c.declareQueue(channel, queueName)
+	if err != nil {
+		_ = channel.Close()
+
+		return err
+	}
+
+	if c.OnChannel != nil {
+		if err = c.OnChannel(channel); err != nil {
+			_ = channel.Close()
+
+			return fmt.Errorf("c.OnChannel: %w", err)
+		}
+	}
+
+	if c.Prefetch > 0 {
+		if err = channel.Qos(c.Prefetch, 0, false); err != nil {
+			_ = channel.Close()
+
+			return fmt.Errorf("channel.Qos: %w", err)
+		}
+	}
+
+	// Запускает потребление сообщений из очереди, полученные сообщения доступны через c.Delivery()
+	delivery, err := channel.Consume(
+		queue.Name,
+		"",
 		false,
 		false,
 		false,
@@ -73,47 +234,232 @@ func (c *Connection) connect() error {
 		nil,
 	)
 	if err != nil {
-		return fmt.Errorf("c.Connection.Channel: %w", err)
+		_ = channel.Close()
+
+		return fmt.Errorf("channel.Consume: %w", err)
 	}
 
-	// Объявляет временную очередь, которая удалится после закрытия соединения
-	queue, err := c.Channel.QueueDeclare(
-		"",
+	c.mu.Lock()
+	c.channel = channel
+	c.chanClosed = chanClosed
+	c.delivery = delivery
+	c.queue = queue.Name
+	c.mu.Unlock()
+
+	return nil
+}
+
+// Supervise - восстанавливает потерянное соединение или канал до вызова Close.
+// onLost вызывается при потере канала, onRestored после повторного открытия канала,
+// потребители потерянного канала останавливаются, когда закрывается его Delivery.
+// Возвращает ошибку, если соединение не восстановлено за все попытки
+func (c *Connection) Supervise(onLost func(err error), onRestored func()) error {
+	for {
+		c.mu.RLock()
+		connClosed, chanClosed := c.connClosed, c.chanClosed
+		c.mu.RUnlock()
+
+		var (
+			amqpErr        *amqp.Error
+			connectionLost bool
+		)
+
+		select {
+		case <-c.done:
+			return nil
+		case amqpErr = <-connClosed:
+			connectionLost = true
+		case amqpErr = <-chanClosed:
+		}
+
+		// Соединение закрыто через Close
+		select {
+		case <-c.done:
+			return nil
+		default:
+		}
+
+		c.setState(StateReconnecting)
+
+		lostErr := ErrConnectionLost
+		if amqpErr != nil {
+			lostErr = fmt.Errorf("%w: %v", ErrConnectionLost, amqpErr)
+		}
+
+		log.Printf("RabbitMQ %s is lost: %v", c.Name, lostErr)
+		onLost(lostErr)
+
+		err := c.restore(connectionLost)
+		if err != nil {
+			c.setState(StateClosed)
+
+			if errors.Is(err, ErrConnectionClosed) {
+				return nil
+			}
+
+			return fmt.Errorf("rmq_rpc - Supervise - c.restore: %w", err)
+		}
+
+		log.Printf("RabbitMQ %s is restored", c.Name)
+		onRestored()
+	}
+}
+
+// Открывает канал заново, если соединение живо, иначе подключается заново
+func (c *Connection) restore(connectionLost bool) error {
+	if !connectionLost {
+		if err := c.openChannel(); err == nil {
+			reconnects.WithLabelValues(c.Name, "channel").Inc()
+			c.setState(StateConnected)
+
+			return nil
+		}
+	}
+
+	c.mu.RLock()
+	connection := c.connection
+	c.mu.RUnlock()
+
+	_ = connection.Close()
+
+	err := c.AttemptConnect()
+	if err != nil {
+		return err
+	}
+
+	reconnects.WithLabelValues(c.Name, "connection").Inc()
+
+	return nil
+}
+
+// Close - закрывает соединение, Supervise завершается без его восстановления
+func (c *Connection) Close() error {
+	c.closeOnce.Do(func() { close(c.done) })
+	c.setState(StateClosed)
+
+	c.mu.RLock()
+	connection := c.connection
+	c.mu.RUnlock()
+
+	if connection == nil || connection.IsClosed() {
+		return nil
+	}
+
+	err := connection.Close()
+	if err != nil {
+		return fmt.Errorf("rmq_rpc - Close - connection.Close: %w", err)
+	}
+
+	return nil
+}
+
+func (c *Connection) declareQueue(channel *amqp.Channel, name string) (amqp.Queue, error) {
+	// Объявляет временную очередь с именем от сервера, которая удалится после закрытия соединения.
+	// После восстановления канала очередь объявляется по прежнему имени, чтобы не потерять ответы в ней
+	if c.ConsumerExchange == "" {
+		queue, err := channel.QueueDeclare(
+			name,
+			false,
+			false,
+			true,
+			false,
+			nil,
+		)
+		if err != nil {
+			return amqp.Queue{}, fmt.Errorf("channel.QueueDeclare: %w", err)
+		}
+
+		return queue, nil
+	}
+
+	err := channel.ExchangeDeclare(
+		CallsExchange(c.ConsumerExchange),
+		"direct", // доставляет сообщения в очереди с совпадающим routing key
+		true,
 		false,
 		false,
-		true,
 		false,
 		nil,
 	)
 	if err != nil {
-		return fmt.Errorf("c.Channel.QueueDeclare: %w", err)
+		return amqp.Queue{}, fmt.Errorf("channel.ExchangeDeclare: %w", err)
+	}
+
+	// Потребители сами публикуют сообщения в dead letter exchange, чтобы туда не попадали
+	// сообщения с истекшим Expiration
+	if c.DeadLetterExchange != "" {
+		if err = c.declareDeadLetter(channel); err != nil {
+			return amqp.Queue{}, err
+		}
 	}
 
-	// Связывает очередь с fanout exchange, используя пустой routing key
-	err = c.Channel.QueueBind(
+	// Quorum очередь считает повторные доставки в заголовке x-delivery-count
+	args := amqp.Table{"x-queue-type": "quorum"}
+
+	// Объявляет общую очередь, которая переживает перезапуск потребителей
+	queue, err := channel.QueueDeclare(
+		c.ConsumerExchange+".calls",
+		true,
+		false,
+		false,
+		false,
+		args,
+	)
+	if err != nil {
+		return amqp.Queue{}, fmt.Errorf("channel.QueueDeclare: %w", err)
+	}
+
+	// Связывает очередь с direct exchange, клиенты публикуют вызовы с именем сервера как routing key
+	err = channel.QueueBind(
 		queue.Name,
-		"",
 		c.ConsumerExchange,
+		CallsExchange(c.ConsumerExchange),
 		false,
 		nil,
 	)
 	if err != nil {
-		return fmt.Errorf("c.Channel.QueueBind: %w", err)
+		return amqp.Queue{}, fmt.Errorf("channel.QueueBind: %w", err)
 	}
 
-	// Запускает потребление сообщений из очереди, предоставляя канал c.Delivery,
-	// через который будут доступны полученные сообщения.
-	c.Delivery, err = c.Channel.Consume(
-		queue.Name,
-		"",
+	return queue, nil
+}
+
+func (c *Connection) declareDeadLetter(channel *amqp.Channel) error {
+	err := channel.ExchangeDeclare(
+		c.DeadLetterExchange,
+		"fanout",
+		true,
+		false,
+		false,
+		false,
+		nil,
+	)
+	if err != nil {
+		return fmt.Errorf("channel.ExchangeDeclare: %w", err)
+	}
+
+	// Отклоненные сообщения хранятся, пока их не разберут вручную
+	queue, err := channel.QueueDeclare(
+		c.ConsumerExchange+".dead",
+		true,
 		false,
 		false,
 		false,
+		nil,
+	)
+	if err != nil {
+		return fmt.Errorf("channel.QueueDeclare: %w", err)
+	}
+
+	err = channel.QueueBind(
+		queue.Name,
+		"",
+		c.DeadLetterExchange,
 		false,
 		nil,
 	)
 	if err != nil {
-		return fmt.Errorf("c.Channel.Consume: %w", err)
+		return fmt.Errorf("channel.QueueBind: %w", err)
 	}
 
 	return nil
@@ -0,0 +1,18 @@
+package rmq_rpc
+
+import (
+	"context"
+)
+
+// DeadlineHeader - unix время в миллисекундах, после которого клиент не ждет ответа
+const DeadlineHeader = "x-deadline"
+
+// WithDeadline - контекст вызова с дедлайном клиента.
+// Если у вызова нет дедлайна, контекст не ограничен
+func WithDeadline(ctx context.Context, call *Message) (context.Context, context.CancelFunc) {
+	if call.Deadline.IsZero() {
+		return context.WithCancel(ctx)
+	}
+
+	return context.WithDeadline(ctx, call.Deadline)
+}
@@ -0,0 +1,112 @@
+package rmq_rpc
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+// ErrorStatus - статус ответа с Error в теле
+const ErrorStatus = "error"
+
+// CodeUnknown - код ошибок, которых нет в реестре
+const CodeUnknown = "unknown"
+
+// Error - ошибка вызова, передаваемая в теле ответа.
+// Она разворачивается в sentinel ошибку, зарегистрированную с кодом, поэтому errors.Is работает на клиенте
+type Error struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+	// Retryable - вызов завершился временной ошибкой и может выполниться при повторе
+	Retryable bool              `json:"retryable,omitempty"`
+	Details   map[string]string `json:"details,omitempty"`
+
+	sentinel error
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+func (e *Error) Unwrap() error {
+	return e.sentinel
+}
+
+// Registry - коды sentinel ошибок, передаваемых через очередь.
+// Клиент и сервер должны зарегистрировать одни и те же коды
+type Registry struct {
+	mu     sync.RWMutex
+	codes  []registeredError
+	byCode map[string]error
+}
+
+type registeredError struct {
+	code string
+	err  error
+}
+
+// NewRegistry - реестр с ошибками пакета rmq_rpc и ошибками контекста
+func NewRegistry() *Registry {
+	r := &Registry{
+		byCode: make(map[string]error),
+	}
+
+	return r.
+		Register("timeout", ErrTimeout).
+		Register("unregistered_handler", ErrBadHandler).
+		Register("not_found", ErrNotFound).
+		Register("too_many_attempts", ErrTooManyAttempts).
+		Register("internal", ErrInternal).
+		Register("deadline_exceeded", context.DeadlineExceeded).
+		Register("canceled", context.Canceled)
+}
+
+// Register - ошибки сопоставляются через errors.Is в порядке регистрации
+func (r *Registry) Register(code string, err error) *Registry {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.byCode[code]; ok || code == CodeUnknown {
+		panic("rmq_rpc - Registry - Register - code is already registered: " + code)
+	}
+
+	r.codes = append(r.codes, registeredError{code, err})
+	r.byCode[code] = err
+
+	return r
+}
+
+// Encode - ошибка ответа, зарегистрированные ошибки отправляются с сообщением sentinel ошибки
+func (r *Registry) Encode(err error) *Error {
+	var rpcErr *Error
+	if errors.As(err, &rpcErr) {
+		return rpcErr
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, registered := range r.codes {
+		if errors.Is(err, registered.err) {
+			return &Error{
+				Code:     registered.code,
+				Message:  registered.err.Error(),
+				sentinel: registered.err,
+			}
+		}
+	}
+
+	return &Error{
+		Code:    CodeUnknown,
+		Message: err.Error(),
+	}
+}
+
+// Decode - восстанавливает sentinel ошибку по коду, ошибки с неизвестным кодом не разворачиваются
+func (r *Registry) Decode(e *Error) error {
+	r.mu.RLock()
+	e.sentinel = r.byCode[e.Code]
+	r.mu.RUnlock()
+
+	return e
+}
@@ -1,6 +1,9 @@
 package rmq_rpc
 
-import "errors"
+import (
+	"errors"
+	"fmt"
+)
 
 var (
 	ErrTimeout = errors.New("timeout")
@@ -12,4 +15,20 @@ var (
 	ErrNotFound = errors.New("not found")
 
 	ErrCallStatus = errors.New("call status")
+
+	ErrTooManyAttempts = errors.New("too many attempts")
+
+	// ErrInternal - сервер не смог ответить на вызов, например закодировать ответ
+	ErrInternal = errors.New("internal error")
+
+	ErrNotConfirmed = errors.New("call is not confirmed by the broker")
+
+	ErrConnectionClosed = errors.New("connection closed")
+
+	// ErrUnavailable - вызов не может быть выполнен сейчас и может выполниться позже
+	ErrUnavailable = errors.New("unavailable")
+
+	ErrNoConsumers = fmt.Errorf("%w: no consumers", ErrUnavailable)
+
+	ErrConnectionLost = fmt.Errorf("%w: connection lost", ErrUnavailable)
 )
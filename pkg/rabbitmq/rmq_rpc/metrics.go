@@ -0,0 +1,33 @@
+package rmq_rpc
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var (
+	connectionUp = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: "rmq_rpc",
+		Name:      "connection_up",
+		Help:      "Whether the connection and its channel are open.",
+	}, []string{"connection"})
+
+	reconnects = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "rmq_rpc",
+		Name:      "reconnects_total",
+		Help:      "Restored connections and channels, by the level of the recovery.",
+	}, []string{"connection", "level"})
+
+	connectFailures = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "rmq_rpc",
+		Name:      "connect_failures_total",
+		Help:      "Failed attempts to connect.",
+	}, []string{"connection"})
+
+	// LostCalls - ожидающие ответа вызовы, завершенные с ошибкой из-за потери соединения
+	LostCalls = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "rmq_rpc",
+		Name:      "lost_calls_total",
+		Help:      "Pending calls failed because the connection was lost.",
+	}, []string{"connection"})
+)
@@ -0,0 +1,121 @@
+package rmq_rpc
+
+import (
+	"context"
+	"fmt"
+	amqp "github.com/rabbitmq/amqp091-go"
+)
+
+// ChannelPool - каналы соединения для публикации, каналом одновременно пользуется одна горутина.
+// Каналы открываются по требованию до размера пула, каналы потерянного соединения отбрасываются
+type ChannelPool struct {
+	conn    *Connection
+	confirm bool
+	idle    chan *PooledChannel
+	slots   chan struct{}
+}
+
+// PooledChannel - Confirms и Returns заданы в режиме подтверждений,
+// брокер возвращает не маршрутизированное mandatory сообщение до его подтверждения
+type PooledChannel struct {
+	*amqp.Channel
+	Confirms <-chan amqp.Confirmation
+	Returns  <-chan amqp.Return
+}
+
+func NewChannelPool(conn *Connection, size int, confirm bool) *ChannelPool {
+	return &ChannelPool{
+		conn:    conn,
+		confirm: confirm,
+		idle:    make(chan *PooledChannel, size),
+		slots:   make(chan struct{}, size),
+	}
+}
+
+// Get - свободный канал пула или новый, ждет, если все каналы заняты
+func (p *ChannelPool) Get(ctx context.Context) (*PooledChannel, error) {
+	for {
+		// Свободный канал используется вместо открытия нового
+		select {
+		case ch := <-p.idle:
+			if !ch.IsClosed() {
+				return ch, nil
+			}
+
+			p.Discard(ch)
+
+			continue
+		default:
+		}
+
+		select {
+		case ch := <-p.idle:
+			if !ch.IsClosed() {
+				return ch, nil
+			}
+
+			p.Discard(ch)
+		case p.slots <- struct{}{}:
+			ch, err := p.openChannel()
+			if err != nil {
+				<-p.slots
+
+				return nil, err
+			}
+
+			return ch, nil
+		case <-ctx.Done():
+			return nil, ctx.Err() //nolint:wrapcheck // just sending raw error
+		}
+	}
+}
+
+// Put - возвращает канал в пул, закрытый канал отбрасывается
+func (p *ChannelPool) Put(ch *PooledChannel) {
+	if ch.IsClosed() {
+		p.Discard(ch)
+
+		return
+	}
+
+	p.idle <- ch
+}
+
+// Discard - закрывает канал, например если подтверждение не дождались и его получил бы следующий вызов
+func (p *ChannelPool) Discard(ch *PooledChannel) {
+	_ = ch.Close()
+	<-p.slots
+}
+
+func (p *ChannelPool) openChannel() (*PooledChannel, error) {
+	p.conn.mu.RLock()
+	connection := p.conn.connection
+	p.conn.mu.RUnlock()
+
+	if connection == nil || connection.IsClosed() {
+		return nil, ErrConnectionLost
+	}
+
+	channel, err := connection.Channel()
+	if err != nil {
+		return nil, fmt.Errorf("rmq_rpc - ChannelPool - openChannel - connection.Channel: %w", err)
+	}
+
+	ch := &PooledChannel{Channel: channel}
+
+	if !p.confirm {
+		return ch, nil
+	}
+
+	err = channel.Confirm(false)
+	if err != nil {
+		_ = channel.Close()
+
+		return nil, fmt.Errorf("rmq_rpc - ChannelPool - openChannel - channel.Confirm: %w", err)
+	}
+
+	ch.Confirms = channel.NotifyPublish(make(chan amqp.Confirmation, 1))
+	ch.Returns = channel.NotifyReturn(make(chan amqp.Return, 1))
+
+	return ch, nil
+}
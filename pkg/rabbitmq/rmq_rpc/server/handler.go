@@ -0,0 +1,37 @@
+package server
+
+import (
+	"WalletRieltaTestTask/pkg/rabbitmq/rmq_rpc"
+	"context"
+	"fmt"
+	"reflect"
+)
+
+// Handle - обработчик вызова с запросом, декодированным кодеком вызова
+func Handle[Req, Resp any](handler func(ctx context.Context, request Req) (Resp, error)) CallHandler {
+	return func(ctx context.Context, body []byte, codec rmq_rpc.Codec) (interface{}, error) {
+		var request Req
+
+		target := interface{}(&request)
+
+		// Запросы типов-указателей, например protobuf сообщений, декодируются в новое значение
+		if v := reflect.ValueOf(&request).Elem(); v.Kind() == reflect.Pointer {
+			v.Set(reflect.New(v.Type().Elem()))
+			target = request
+		}
+
+		// У вызовов без запроса нет тела
+		if len(body) > 0 {
+			if err := codec.Unmarshal(body, target); err != nil {
+				return nil, fmt.Errorf("rmq_rpc server - Handle - codec.Unmarshal: %w", err)
+			}
+		}
+
+		response, err := handler(ctx, request)
+		if err != nil {
+			return nil, err
+		}
+
+		return response, nil
+	}
+}
@@ -1,6 +1,9 @@
 package server
 
-import "time"
+import (
+	"WalletRieltaTestTask/pkg/rabbitmq/rmq_rpc"
+	"time"
+)
 
 type Option func(*Server)
 
@@ -27,3 +30,69 @@ func ConnAttempts(attempts int) Option {
 		s.conn.Attempts = attempts
 	}
 }
+
+// ConnMaxWaitTime - предел экспоненциальной задержки между попытками подключения
+func ConnMaxWaitTime(timeout time.Duration) Option {
+	return func(s *Server) {
+		s.conn.MaxWaitTime = timeout
+	}
+}
+
+// Prefetch - вызовы, доставляемые серверу до подтверждения предыдущих, по умолчанию число горутин
+func Prefetch(count int) Option {
+	return func(s *Server) {
+		s.conn.Prefetch = count
+	}
+}
+
+// AckAfterCall - вызовы подтверждаются после публикации ответа, временные ошибки повторяются.
+// Иначе вызовы подтверждаются при получении и теряются при падении воркера
+func AckAfterCall(enabled bool) Option {
+	return func(s *Server) {
+		s.ackAfterCall = enabled
+	}
+}
+
+// MaxAttempts - после стольких попыток вызов уходит в dead letters, считая попытки, уронившие воркер
+func MaxAttempts(attempts int) Option {
+	return func(s *Server) {
+		s.maxAttempts = attempts
+	}
+}
+
+// Transient - ошибки, с которыми вызов возвращается в очередь вместо ответа. Паники обработчика повторяются всегда
+func Transient(isTransient func(err error) bool) Option {
+	return func(s *Server) {
+		s.isTransient = isTransient
+	}
+}
+
+// DeadLetterExchange - exchange вызовов, исчерпавших попытки, по умолчанию "<exchange сервера>.dlx"
+func DeadLetterExchange(exchange string) Option {
+	return func(s *Server) {
+		s.conn.DeadLetterExchange = exchange
+	}
+}
+
+// Errors - коды ошибок, отправляемых клиентам, ошибки без кода отправляются как unknown
+func Errors(registry *rmq_rpc.Registry) Option {
+	return func(s *Server) {
+		s.codes = registry
+	}
+}
+
+// Codecs - кодеки ответов, используется первый, который принимает клиент. По умолчанию JSON, MessagePack и Protobuf.
+// Вызовы декодируются встроенными кодеками, даже если их нет в списке
+func Codecs(codecs ...rmq_rpc.Codec) Option {
+	return func(s *Server) {
+		s.codecs = codecs
+	}
+}
+
+// Compression - ответы от threshold байт сжимаются первым алгоритмом, который принимает клиент,
+// по умолчанию zstd и gzip от 4 KiB. Без алгоритмов сжатие выключено
+func Compression(threshold int, compressors ...rmq_rpc.Compressor) Option {
+	return func(s *Server) {
+		s.compression = rmq_rpc.Compression{Threshold: threshold, Compressors: compressors}
+	}
+}
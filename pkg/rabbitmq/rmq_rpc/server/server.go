@@ -3,10 +3,13 @@ package server
 import (
 	"WalletRieltaTestTask/pkg/logger"
 	"WalletRieltaTestTask/pkg/rabbitmq/rmq_rpc"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
-	"github.com/streadway/amqp"
 	"log/slog"
+	"runtime/debug"
+	"strconv"
 	"time"
 )
 
@@ -15,21 +18,45 @@ const (
 	_defaultAttempts        = 10
 	_defaultTimeout         = 2 * time.Second
 	_defaultGoroutinesCount = 24
+	_defaultMaxAttempts     = 5
+
+	_defaultCompressionThreshold = 4 << 10
 
 	Success = "success"
+
+	// ErrorHeader - ошибка последней попытки вызова, ушедшего в dead letters
+	ErrorHeader = "x-error"
 )
 
-type CallHandler func(*amqp.Delivery) (interface{}, error)
+// errPanic - обработчик запаниковал. Вызов повторяется, паника может быть вызвана состоянием воркера
+var errPanic = errors.New("handler panicked")
+
+// CallHandler - обработчик тела запроса, закодированного кодеком, возвращенный ответ кодируется
+// первым кодеком, который принимает клиент. Контекст завершается, когда клиент перестает ждать ответа
+type CallHandler func(ctx context.Context, body []byte, codec rmq_rpc.Codec) (interface{}, error)
 
 type Server struct {
-	conn   *rmq_rpc.Connection
-	error  chan error
-	stop   chan struct{}
-	router map[string]CallHandler
+	transport rmq_rpc.ServerTransport
+	cancel    context.CancelFunc
+	router    map[string]CallHandler
 
 	timeout         time.Duration
 	goroutinesCount int
 
+	// Вызовы подтверждаются после публикации ответа, поэтому вызовы упавшего воркера доставляются повторно
+	ackAfterCall bool
+	maxAttempts  int
+	isTransient  func(err error) bool
+
+	codes *rmq_rpc.Registry
+
+	// Ответы кодируются и сжимаются так, как принимает клиент
+	codecs      rmq_rpc.Codecs
+	compression rmq_rpc.Compression
+
+	// Настройки транспорта RabbitMQ
+	conn *rmq_rpc.Connection
+
 	logger *slog.Logger
 }
 
@@ -38,15 +65,59 @@ func New(url, serverExchange string, router map[string]CallHandler, l *slog.Logg
 		URL:      url,
 		WaitTime: _defaultWaitTime,
 		Attempts: _defaultAttempts,
+		Name:     "server:" + serverExchange,
+	}
+
+	conn := rmq_rpc.NewConnectionRabbitMQ(serverExchange, cfg)
+	conn.DeadLetterExchange = serverExchange + ".dlx"
+
+	s := newServer(conn, router, l, opts)
+
+	// Горутина получает следующий вызов, пока отвечает на текущий
+	if s.conn.Prefetch == 0 {
+		s.conn.Prefetch = s.goroutinesCount
+	}
+
+	transport, err := rmq_rpc.NewAMQPServer(s.conn, s.goroutinesCount)
+	if err != nil {
+		return nil, fmt.Errorf("rmq_rpc server - NewServer - rmq_rpc.NewAMQPServer: %w", err)
+	}
+
+	s.transport = transport
+
+	return s, nil
+}
+
+// NewWithTransport - сервер поверх транспорта, например rmq_rpc.MemoryBroker в тестах.
+// Опции соединения RabbitMQ игнорируются
+func NewWithTransport(
+	transport rmq_rpc.ServerTransport,
+	router map[string]CallHandler,
+	l *slog.Logger,
+	opts ...Option,
+) *Server {
+	s := newServer(rmq_rpc.NewConnectionRabbitMQ("", rmq_rpc.Config{}), router, l, opts)
+	s.transport = transport
+
+	return s
+}
+
+func newServer(conn *rmq_rpc.Connection, router map[string]CallHandler, l *slog.Logger, opts []Option) *Server {
+	compression := rmq_rpc.Compression{
+		Threshold:   _defaultCompressionThreshold,
+		Compressors: []rmq_rpc.Compressor{rmq_rpc.Zstd(), rmq_rpc.Gzip()},
 	}
 
 	s := &Server{
-		conn:            rmq_rpc.NewConnectionRabbitMQ(serverExchange, cfg),
-		error:           make(chan error),
-		stop:            make(chan struct{}),
 		router:          router,
 		timeout:         _defaultTimeout,
 		goroutinesCount: _defaultGoroutinesCount,
+		ackAfterCall:    true,
+		maxAttempts:     _defaultMaxAttempts,
+		codes:           rmq_rpc.NewRegistry(),
+		codecs:          rmq_rpc.Codecs{rmq_rpc.JSON(), rmq_rpc.MessagePack(), rmq_rpc.Protobuf()},
+		compression:     compression,
+		conn:            conn,
 		logger:          l,
 	}
 
@@ -54,108 +125,240 @@ func New(url, serverExchange string, router map[string]CallHandler, l *slog.Logg
 		opt(s)
 	}
 
-	err := s.conn.AttemptConnect()
+	return s
+}
+
+func (s *Server) MustRun() {
+	ctx, cancel := context.WithCancel(context.Background())
+	s.cancel = cancel
+
+	s.transport.Consume(ctx, s.goroutinesCount, s.serveCall)
+}
+
+func (s *Server) serveCall(d *rmq_rpc.Delivery) {
+	if !s.ackAfterCall {
+		_ = d.Ack()
+	}
+
+	callHandler, ok := s.router[d.Type]
+	if !ok {
+		s.publishError(d, s.codes.Encode(rmq_rpc.ErrBadHandler))
+		s.ack(d)
+
+		return
+	}
+
+	ctx, cancel := rmq_rpc.WithDeadline(context.Background(), &d.Message)
+	defer cancel()
+
+	// Клиент уже не ждет ответа, поэтому вызов не выполняется
+	if ctx.Err() != nil {
+		s.logger.Debug("rmq_rpc server - Server - serveCall - call expired", slog.String("handler", d.Type))
+		s.ack(d)
+
+		return
+	}
+
+	// Предыдущие попытки уронили воркеры до подтверждения вызова
+	attempt := d.Attempt
+	if s.ackAfterCall && attempt > s.maxAttempts {
+		s.deadLetter(d, rmq_rpc.ErrTooManyAttempts, attempt)
+
+		return
+	}
+
+	body, err := s.compression.Decompress(d.ContentEncoding, d.Body)
 	if err != nil {
-		return nil, fmt.Errorf("rmq_rpc server - NewServer - s.conn.AttemptConnect: %w", err)
+		s.publishError(d, s.codes.Encode(err))
+		s.ack(d)
+
+		return
 	}
 
-	return s, nil
-}
+	codec, err := s.codecs.Get(d.ContentType)
+	if err != nil {
+		s.publishError(d, s.codes.Encode(err))
+		s.ack(d)
 
-func (s *Server) MustRun() {
-	for i := 0; i < s.goroutinesCount; i++ {
-		go s.consumer()
+		return
 	}
-}
 
-func (s *Server) consumer() {
-	for {
-		select {
-		case <-s.stop:
+	response, err := s.call(ctx, callHandler, d.Type, body, codec)
+	if err != nil {
+		if s.ackAfterCall && (errors.Is(err, errPanic) || s.isTransient != nil && s.isTransient(err)) {
+			s.retry(d, err, attempt)
+
 			return
-		case d, opened := <-s.conn.Delivery:
-			if !opened {
-				s.reconnect()
+		}
+
+		s.publishError(d, s.codes.Encode(err))
+		s.ack(d)
 
-				return
-			}
+		return
+	}
 
-			_ = d.Ack(false)
+	// Клиент восстанавливает ответ по content type ответа
+	codec, body, err = s.codecs.Accepted(header(d, rmq_rpc.AcceptHeader)).Marshal(response)
+	if err != nil {
+		s.logger.Error("rmq_rpc server - Server - serveCall - Marshal", logger.Err(err), slog.String("handler", d.Type))
 
-			s.serveCall(&d)
-		}
+		// Пустой ответ с успехом клиент принял бы за результат вызова
+		s.publishError(d, s.codes.Encode(rmq_rpc.ErrInternal))
+		s.ack(d)
+
+		return
 	}
+
+	s.publish(d, codec.ContentType(), body, Success)
+	s.ack(d)
 }
 
-func (s *Server) serveCall(d *amqp.Delivery) {
-	callHandler, ok := s.router[d.Type]
-	if !ok {
-		s.publish(d, nil, rmq_rpc.ErrBadHandler.Error())
+func (s *Server) call(
+	ctx context.Context,
+	callHandler CallHandler,
+	handler string,
+	body []byte,
+	codec rmq_rpc.Codec,
+) (response interface{}, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("%w: %v", errPanic, r)
+
+			s.logger.Error("rmq_rpc server - Server - call - panic",
+				logger.Err(err),
+				slog.String("handler", handler),
+				slog.String("stack", string(debug.Stack())),
+			)
+		}
+	}()
+
+	return callHandler(ctx, body, codec)
+}
+
+// Возвращает в очередь вызов, завершившийся временной ошибкой, последняя попытка уходит в dead letters
+func (s *Server) retry(d *rmq_rpc.Delivery, err error, attempt int) {
+	if attempt >= s.maxAttempts {
+		s.deadLetter(d, err, attempt)
 
 		return
 	}
 
-	response, err := callHandler(d)
+	s.logger.Warn("rmq_rpc server - Server - retry - call requeued",
+		logger.Err(err),
+		slog.String("handler", d.Type),
+		slog.Int("attempt", attempt),
+	)
+
+	if err = d.Nack(true); err != nil {
+		s.logger.Error("rmq_rpc server - Server - retry - d.Nack", logger.Err(err))
+	}
+}
+
+// Отвечает клиенту ошибкой и переносит вызов в dead-letter exchange
+func (s *Server) deadLetter(d *rmq_rpc.Delivery, err error, attempt int) {
+	s.logger.Error("rmq_rpc server - Server - deadLetter - call dead-lettered",
+		logger.Err(err),
+		slog.String("handler", d.Type),
+		slog.Int("attempt", attempt),
+	)
+
+	// Вызовы, завершившиеся временной ошибкой, могут выполниться позже
+	rpcErr := *s.codes.Encode(err)
+	rpcErr.Retryable = !errors.Is(err, rmq_rpc.ErrTooManyAttempts)
+	rpcErr.Details = map[string]string{"attempts": strconv.Itoa(attempt)}
+
+	s.publishError(d, &rpcErr)
+
+	call := d.Message
+	call.Headers = map[string]interface{}{ErrorHeader: err.Error()}
+
+	for k, v := range d.Headers {
+		call.Headers[k] = v
+	}
+
+	err = s.transport.DeadLetter(context.Background(), call)
 	if err != nil {
-		s.publish(d, nil, err.Error())
+		s.logger.Error("rmq_rpc server - Server - deadLetter - s.transport.DeadLetter", logger.Err(err))
+
+		// Вызов остается в очереди и уходит в dead letters на следующей попытке
+		if err = d.Nack(true); err != nil {
+			s.logger.Error("rmq_rpc server - Server - deadLetter - d.Nack", logger.Err(err))
+		}
 
 		return
 	}
 
-	body, err := json.Marshal(response)
-	if err != nil {
-		s.logger.Error("rmq_rpc server - Server - serveCall - json.Marshal", logger.Err(err))
+	s.ack(d)
+}
+
+func (s *Server) ack(d *rmq_rpc.Delivery) {
+	if !s.ackAfterCall {
+		return
 	}
 
-	s.publish(d, body, Success)
+	if err := d.Ack(); err != nil {
+		s.logger.Error("rmq_rpc server - Server - ack - d.Ack", logger.Err(err))
+	}
 }
 
-func (s *Server) publish(d *amqp.Delivery, body []byte, status string) {
-	err := s.conn.Channel.Publish(d.ReplyTo, "", false, false,
-		amqp.Publishing{
-			ContentType:   "application/json",
-			CorrelationId: d.CorrelationId,
-			Type:          status,
-			Body:          body,
-		})
+func (s *Server) publishError(d *rmq_rpc.Delivery, rpcErr *rmq_rpc.Error) {
+	body, err := json.Marshal(rpcErr)
 	if err != nil {
-		s.logger.Error("rmq_rpc server - Server - publish - s.conn.Channel.Publish", logger.Err(err))
+		s.logger.Error("rmq_rpc server - Server - publishError - json.Marshal", logger.Err(err))
 	}
-}
 
-func (s *Server) reconnect() {
-	close(s.stop)
+	// Ошибка всегда в json, поэтому ее декодирует любой клиент
+	s.publish(d, rmq_rpc.JSON().ContentType(), body, rmq_rpc.ErrorStatus)
+}
 
-	err := s.conn.AttemptConnect()
+// Ответ публикуется и после дедлайна вызова, клиент сам отбрасывает опоздавшие ответы
+func (s *Server) publish(d *rmq_rpc.Delivery, contentType string, body []byte, status string) {
+	encoding, compressed, err := s.compression.Compress(header(d, rmq_rpc.AcceptEncodingHeader), body)
 	if err != nil {
-		s.error <- err
-		close(s.error)
+		s.logger.Error("rmq_rpc server - Server - publish - s.compression.Compress", logger.Err(err))
+	} else {
+		body = compressed
+	}
 
-		return
+	err = s.transport.Reply(context.Background(), d.ReplyTo,
+		rmq_rpc.Message{
+			CorrelationID:   d.CorrelationID,
+			Type:            status,
+			ContentType:     contentType,
+			ContentEncoding: encoding,
+			Body:            body,
+		})
+	if err != nil {
+		s.logger.Error("rmq_rpc server - Server - publish - s.transport.Reply", logger.Err(err))
 	}
+}
 
-	s.stop = make(chan struct{})
+func header(d *rmq_rpc.Delivery, name string) string {
+	value, _ := d.Headers[name].(string)
 
-	go s.consumer()
+	return value
 }
 
 func (s *Server) Notify() <-chan error {
-	return s.error
+	return s.transport.Notify()
 }
 
 func (s *Server) Shutdown() error {
 	select {
-	case <-s.error:
+	case <-s.transport.Notify():
 		return nil
 	default:
 	}
 
-	close(s.stop)
+	if s.cancel != nil {
+		s.cancel()
+	}
+
 	time.Sleep(s.timeout)
 
-	err := s.conn.Connection.Close()
+	err := s.transport.Close()
 	if err != nil {
-		return fmt.Errorf("rmq_rpc server - Server - Shutdown - s.Connection.Close: %w", err)
+		return fmt.Errorf("rmq_rpc server - Server - Shutdown - s.transport.Close: %w", err)
 	}
 
 	return nil
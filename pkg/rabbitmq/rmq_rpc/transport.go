@@ -0,0 +1,57 @@
+package rmq_rpc
+
+import (
+	"context"
+	"time"
+)
+
+// Message - вызов клиента или ответ сервера
+type Message struct {
+	CorrelationID string
+	// ReplyTo - адрес клиента для ответа, задается транспортом клиента
+	ReplyTo string
+	// Type - обработчик вызова или статус ответа
+	Type        string
+	ContentType string
+	// ContentEncoding - сжатие тела, пустое, если тело не сжато
+	ContentEncoding string
+	Headers         map[string]interface{}
+	// Deadline - после него клиент не ждет ответа, нулевой, если дедлайна нет
+	Deadline time.Time
+	Body     []byte
+}
+
+// Delivery - вызов, полученный сервером, он подтверждается или отклоняется один раз
+type Delivery struct {
+	Message
+	// Attempt - номер доставки, вызов доставляется повторно, если его вернули в очередь или сервер упал
+	Attempt int
+	Ack     func() error
+	Nack    func(requeue bool) error
+}
+
+// ClientTransport - отправляет вызовы серверу и передает ответы обратно
+type ClientTransport interface {
+	// Publish - завершается, когда очередь сервера приняла вызов.
+	// ErrNoConsumers, если очереди сервера нет, ErrConnectionLost, пока соединение восстанавливается
+	Publish(ctx context.Context, call Message) error
+	// Consume - передает ответы в onReply до завершения ctx,
+	// onLost вызывается, когда ответы на ожидающие вызовы могут быть потеряны
+	Consume(ctx context.Context, onReply func(reply Message), onLost func(err error))
+	// Notify - ошибка, после которой транспорт больше не работает
+	Notify() <-chan error
+	Close() error
+}
+
+// ServerTransport - передает вызовы клиентов серверу и отправляет ответы
+type ServerTransport interface {
+	// Consume - передает вызовы обработчику из горутин до завершения ctx
+	Consume(ctx context.Context, goroutines int, handler func(d *Delivery))
+	// Reply - отправляет ответ в ReplyTo вызова, ответы ушедшим клиентам отбрасываются
+	Reply(ctx context.Context, replyTo string, reply Message) error
+	// DeadLetter - сохраняет вызов, исчерпавший все попытки
+	DeadLetter(ctx context.Context, call Message) error
+	// Notify - ошибка, после которой транспорт больше не работает
+	Notify() <-chan error
+	Close() error
+}
@@ -0,0 +1,358 @@
+package rmq_rpc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	amqp "github.com/rabbitmq/amqp091-go"
+	"strconv"
+	"time"
+)
+
+// AMQPClient - клиентский транспорт поверх RabbitMQ. Вызовы публикуются в direct exchange сервера
+// с подтверждениями и флагом mandatory, ответы приходят в эксклюзивную очередь соединения
+type AMQPClient struct {
+	conn           *Connection
+	serverExchange string
+	channels       *ChannelPool
+	error          chan error
+}
+
+func NewAMQPClient(conn *Connection, serverExchange string, channels int) (*AMQPClient, error) {
+	t := &AMQPClient{
+		conn:           conn,
+		serverExchange: serverExchange,
+		channels:       NewChannelPool(conn, channels, true),
+		error:          make(chan error),
+	}
+
+	conn.OnChannel = t.setupChannel
+
+	err := conn.AttemptConnect()
+	if err != nil {
+		return nil, fmt.Errorf("rmq_rpc - NewAMQPClient - conn.AttemptConnect: %w", err)
+	}
+
+	return t, nil
+}
+
+// Настраивает каждый открытый канал соединения до начала потребления
+func (t *AMQPClient) setupChannel(channel *amqp.Channel) error {
+	// Exchange сервера объявляется и клиентом, иначе публикация до запуска сервера закрывает канал
+	err := channel.ExchangeDeclare(
+		CallsExchange(t.serverExchange),
+		"direct",
+		true,
+		false,
+		false,
+		false,
+		nil,
+	)
+	if err != nil {
+		return fmt.Errorf("channel.ExchangeDeclare: %w", err)
+	}
+
+	return nil
+}
+
+func (t *AMQPClient) Publish(ctx context.Context, call Message) error {
+	// Пока соединение восстанавливается, вызовы завершаются сразу
+	if t.conn.State() != StateConnected {
+		return ErrConnectionLost
+	}
+
+	call.ReplyTo = t.conn.Queue()
+
+	ch, err := t.channels.Get(ctx)
+	if err != nil {
+		return fmt.Errorf("rmq_rpc - AMQPClient - Publish - t.channels.Get: %w", err)
+	}
+
+	// Очередь сервера связана с exchange по его имени, сообщение возвращается,
+	// если очередь не объявлена
+	exchange := CallsExchange(t.serverExchange)
+
+	err = ch.PublishWithContext(ctx, exchange, t.serverExchange, true, false, publishing(&call, true))
+	if err != nil {
+		t.channels.Discard(ch)
+
+		// Канал потерян, соединение восстанавливается
+		if errors.Is(err, amqp.ErrClosed) {
+			return ErrConnectionLost
+		}
+
+		return fmt.Errorf("rmq_rpc - AMQPClient - Publish - ch.PublishWithContext: %w", err)
+	}
+
+	select {
+	case <-ctx.Done():
+		// Опоздавшее подтверждение досталось бы следующему вызову
+		t.channels.Discard(ch)
+
+		return ctx.Err() //nolint:wrapcheck // just sending raw error
+	case confirm, ok := <-ch.Confirms:
+		if !ok {
+			t.channels.Discard(ch)
+
+			return ErrConnectionLost
+		}
+
+		// Брокер возвращает сообщение до его подтверждения
+		select {
+		case <-ch.Returns:
+			t.channels.Put(ch)
+
+			return ErrNoConsumers
+		default:
+		}
+
+		t.channels.Put(ch)
+
+		if !confirm.Ack {
+			return ErrNotConfirmed
+		}
+	}
+
+	return nil
+}
+
+// Consume - соединение восстанавливается до Close, потребитель запускается заново на восстановленном канале
+func (t *AMQPClient) Consume(ctx context.Context, onReply func(reply Message), onLost func(err error)) {
+	go t.consumer(ctx, t.conn.Delivery(), onReply)
+
+	go func() {
+		err := t.conn.Supervise(
+			onLost,
+			func() {
+				go t.consumer(ctx, t.conn.Delivery(), onReply)
+			},
+		)
+		if err != nil {
+			t.error <- err
+			close(t.error)
+		}
+	}()
+}
+
+func (t *AMQPClient) consumer(ctx context.Context, delivery <-chan amqp.Delivery, onReply func(reply Message)) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case d, opened := <-delivery:
+			if !opened {
+				return
+			}
+
+			_ = d.Ack(false)
+
+			onReply(message(&d))
+		}
+	}
+}
+
+func (t *AMQPClient) Notify() <-chan error {
+	return t.error
+}
+
+func (t *AMQPClient) Close() error {
+	err := t.conn.Close()
+	if err != nil {
+		return fmt.Errorf("rmq_rpc - AMQPClient - Close - t.conn.Close: %w", err)
+	}
+
+	return nil
+}
+
+// AMQPServer - серверный транспорт поверх RabbitMQ. Вызовы читаются из устойчивой очереди соединения,
+// ответы публикуются в очереди клиентов через default exchange
+type AMQPServer struct {
+	conn *Connection
+	// Каждая горутина публикует ответы в свой канал
+	channels *ChannelPool
+	error    chan error
+}
+
+func NewAMQPServer(conn *Connection, channels int) (*AMQPServer, error) {
+	t := &AMQPServer{
+		conn:     conn,
+		channels: NewChannelPool(conn, channels, false),
+		error:    make(chan error),
+	}
+
+	err := conn.AttemptConnect()
+	if err != nil {
+		return nil, fmt.Errorf("rmq_rpc - NewAMQPServer - conn.AttemptConnect: %w", err)
+	}
+
+	return t, nil
+}
+
+// Consume - потребители канала останавливаются при его потере и запускаются заново на восстановленном канале
+func (t *AMQPServer) Consume(ctx context.Context, goroutines int, handler func(d *Delivery)) {
+	t.startConsumers(ctx, goroutines, handler)
+
+	go func() {
+		err := t.conn.Supervise(
+			func(error) {},
+			func() {
+				t.startConsumers(ctx, goroutines, handler)
+			},
+		)
+		if err != nil {
+			t.error <- err
+			close(t.error)
+		}
+	}()
+}
+
+func (t *AMQPServer) startConsumers(ctx context.Context, goroutines int, handler func(d *Delivery)) {
+	delivery := t.conn.Delivery()
+
+	for i := 0; i < goroutines; i++ {
+		go t.consumer(ctx, delivery, handler)
+	}
+}
+
+func (t *AMQPServer) consumer(ctx context.Context, delivery <-chan amqp.Delivery, handler func(d *Delivery)) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case d, opened := <-delivery:
+			if !opened {
+				return
+			}
+
+			handler(&Delivery{
+				Message: message(&d),
+				Attempt: deliveryCount(&d) + 1,
+				Ack: func() error {
+					return d.Ack(false)
+				},
+				Nack: func(requeue bool) error {
+					return d.Nack(false, requeue)
+				},
+			})
+		}
+	}
+}
+
+// Ответ уходит в эксклюзивную очередь клиента через exchange по умолчанию
+func (t *AMQPServer) Reply(ctx context.Context, replyTo string, reply Message) error {
+	err := t.publishTo(ctx, "", replyTo, publishing(&reply, false))
+	if err != nil {
+		return fmt.Errorf("rmq_rpc - AMQPServer - Reply - t.publishTo: %w", err)
+	}
+
+	return nil
+}
+
+// DeadLetter - вызов хранится в очереди, привязанной к dead-letter exchange соединения
+func (t *AMQPServer) DeadLetter(ctx context.Context, call Message) error {
+	err := t.publishTo(ctx, t.conn.DeadLetterExchange, "", publishing(&call, false))
+	if err != nil {
+		return fmt.Errorf("rmq_rpc - AMQPServer - DeadLetter - t.publishTo: %w", err)
+	}
+
+	return nil
+}
+
+func (t *AMQPServer) publishTo(ctx context.Context, exchange, key string, msg amqp.Publishing) error {
+	ch, err := t.channels.Get(ctx)
+	if err != nil {
+		return fmt.Errorf("t.channels.Get: %w", err)
+	}
+
+	err = ch.PublishWithContext(ctx, exchange, key, false, false, msg)
+	if err != nil {
+		t.channels.Discard(ch)
+
+		return fmt.Errorf("ch.PublishWithContext: %w", err)
+	}
+
+	t.channels.Put(ch)
+
+	return nil
+}
+
+func (t *AMQPServer) Notify() <-chan error {
+	return t.error
+}
+
+func (t *AMQPServer) Close() error {
+	err := t.conn.Close()
+	if err != nil {
+		return fmt.Errorf("rmq_rpc - AMQPServer - Close - t.conn.Close: %w", err)
+	}
+
+	return nil
+}
+
+// Сообщение для публикации, дедлайн вызова передается заголовком и ограничивает время жизни в очереди
+func publishing(msg *Message, expires bool) amqp.Publishing {
+	headers := amqp.Table{}
+	for k, v := range msg.Headers {
+		headers[k] = v
+	}
+
+	p := amqp.Publishing{
+		ContentType:     msg.ContentType,
+		ContentEncoding: msg.ContentEncoding,
+		CorrelationId:   msg.CorrelationID,
+		ReplyTo:         msg.ReplyTo,
+		Type:            msg.Type,
+		Body:            msg.Body,
+	}
+
+	if !msg.Deadline.IsZero() {
+		headers[DeadlineHeader] = msg.Deadline.UnixMilli()
+
+		if expires {
+			expiration := time.Until(msg.Deadline).Milliseconds()
+			if expiration < 1 {
+				expiration = 1
+			}
+
+			p.Expiration = strconv.FormatInt(expiration, 10)
+		}
+	}
+
+	if len(headers) > 0 {
+		p.Headers = headers
+	}
+
+	return p
+}
+
+func message(d *amqp.Delivery) Message {
+	msg := Message{
+		CorrelationID:   d.CorrelationId,
+		ReplyTo:         d.ReplyTo,
+		Type:            d.Type,
+		ContentType:     d.ContentType,
+		ContentEncoding: d.ContentEncoding,
+		Headers:         d.Headers,
+		Body:            d.Body,
+	}
+
+	if ms, ok := d.Headers[DeadlineHeader].(int64); ok {
+		msg.Deadline = time.UnixMilli(ms)
+	}
+
+	return msg
+}
+
+// Число предыдущих доставок вызова, которое считает quorum очередь
+func deliveryCount(d *amqp.Delivery) int {
+	switch count := d.Headers["x-delivery-count"].(type) {
+	case int64:
+		return int(count)
+	case int32:
+		return int(count)
+	case int:
+		return count
+	default:
+		return 0
+	}
+}
@@ -0,0 +1,143 @@
+package rmq_rpc
+
+import (
+	amqp "github.com/rabbitmq/amqp091-go"
+	"reflect"
+	"strconv"
+	"testing"
+	"time"
+)
+
+// Доставка, которую брокер собирает из опубликованного сообщения
+func delivered(p amqp.Publishing) amqp.Delivery {
+	return amqp.Delivery{
+		Headers:         p.Headers,
+		ContentType:     p.ContentType,
+		ContentEncoding: p.ContentEncoding,
+		CorrelationId:   p.CorrelationId,
+		ReplyTo:         p.ReplyTo,
+		Expiration:      p.Expiration,
+		Type:            p.Type,
+		Body:            p.Body,
+	}
+}
+
+func TestPublishingMessageRoundTrip(t *testing.T) {
+	deadline := time.UnixMilli(time.Now().Add(time.Minute).UnixMilli())
+
+	call := Message{
+		CorrelationID:   "b7c9e4de-6f0e-4a8e-9a3f-5d1c2a0b9e11",
+		ReplyTo:         "amq.gen-reply",
+		Type:            "sendFunds",
+		ContentType:     "application/msgpack",
+		ContentEncoding: "zstd",
+		Headers:         map[string]interface{}{AcceptHeader: "application/msgpack,application/json"},
+		Deadline:        deadline,
+		Body:            []byte{0x81, 0xa4, 0x66, 0x72, 0x6f, 0x6d},
+	}
+
+	p := publishing(&call, true)
+
+	if p.Headers[DeadlineHeader] != deadline.UnixMilli() {
+		t.Fatalf("deadline header = %v, want %d", p.Headers[DeadlineHeader], deadline.UnixMilli())
+	}
+
+	if _, ok := call.Headers[DeadlineHeader]; ok {
+		t.Fatal("headers of the message were changed")
+	}
+
+	if err := p.Headers.Validate(); err != nil {
+		t.Fatalf("headers aren't valid amqp table: %v", err)
+	}
+
+	got := message(ptr(delivered(p)))
+
+	if !got.Deadline.Equal(call.Deadline) {
+		t.Fatalf("deadline = %s, want %s", got.Deadline, call.Deadline)
+	}
+
+	got.Deadline = call.Deadline
+	want := call
+	want.Headers = map[string]interface{}{
+		AcceptHeader:   call.Headers[AcceptHeader],
+		DeadlineHeader: deadline.UnixMilli(),
+	}
+
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("message = %+v, want %+v", got, want)
+	}
+}
+
+func TestPublishingExpiration(t *testing.T) {
+	tests := []struct {
+		name     string
+		deadline time.Time
+		expires  bool
+		min, max int64
+	}{
+		{"call", time.Now().Add(5 * time.Second), true, 4000, 5000},
+		// Истекший вызов не должен жить в очереди бесконечно
+		{"expired call", time.Now().Add(-time.Second), true, 1, 1},
+		// Ответы и dead letters не истекают
+		{"reply", time.Now().Add(5 * time.Second), false, 0, 0},
+		{"no deadline", time.Time{}, true, 0, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := publishing(&Message{Deadline: tt.deadline}, tt.expires)
+
+			if tt.max == 0 {
+				if p.Expiration != "" {
+					t.Fatalf("expiration = %s, want none", p.Expiration)
+				}
+
+				return
+			}
+
+			expiration, err := strconv.ParseInt(p.Expiration, 10, 64)
+			if err != nil || expiration < tt.min || expiration > tt.max {
+				t.Fatalf("expiration = %q, want %d..%d ms", p.Expiration, tt.min, tt.max)
+			}
+		})
+	}
+}
+
+func TestPublishingWithoutHeaders(t *testing.T) {
+	p := publishing(&Message{Body: []byte("{}")}, true)
+
+	if p.Headers != nil {
+		t.Fatalf("headers = %v, want nil", p.Headers)
+	}
+
+	msg := message(ptr(delivered(p)))
+	if !msg.Deadline.IsZero() {
+		t.Fatalf("deadline = %s, want zero", msg.Deadline)
+	}
+}
+
+func TestDeliveryCount(t *testing.T) {
+	tests := []struct {
+		name    string
+		headers amqp.Table
+		want    int
+	}{
+		{"first delivery", nil, 0},
+		{"int64", amqp.Table{"x-delivery-count": int64(3)}, 3},
+		{"int32", amqp.Table{"x-delivery-count": int32(2)}, 2},
+		{"int", amqp.Table{"x-delivery-count": 1}, 1},
+		{"unexpected type", amqp.Table{"x-delivery-count": "4"}, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := deliveryCount(&amqp.Delivery{Headers: tt.headers}); got != tt.want {
+				t.Fatalf("deliveryCount = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func ptr[T any](v T) *T {
+	return &v
+}
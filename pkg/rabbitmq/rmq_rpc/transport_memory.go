@@ -0,0 +1,229 @@
+package rmq_rpc
+
+import (
+	"context"
+	"maps"
+	"strconv"
+	"sync"
+)
+
+const _memoryQueueSize = 1024
+
+// MemoryBroker - транспорт вызовов внутри процесса, например для тестов без RabbitMQ.
+// Как и очередь RabbitMQ, очередь сервера существует с создания его транспорта и хранит вызовы,
+// пока их не заберет потребитель. Возвращенные в очередь вызовы доставляются со следующей попыткой,
+// ответы маршрутизируются по ReplyTo, ответы ушедшим клиентам отбрасываются
+type MemoryBroker struct {
+	mu      sync.RWMutex
+	queues  map[string]chan *Delivery
+	inboxes map[string]chan Message
+	dead    map[string][]Message
+	clients int
+}
+
+func NewMemoryBroker() *MemoryBroker {
+	return &MemoryBroker{
+		queues:  make(map[string]chan *Delivery),
+		inboxes: make(map[string]chan Message),
+		dead:    make(map[string][]Message),
+	}
+}
+
+// Client - транспорт клиента, вызывающего сервер, у него свой адрес для ответов
+func (b *MemoryBroker) Client(server string) *MemoryClient {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.clients++
+
+	replyTo := "memory.reply." + strconv.Itoa(b.clients)
+	inbox := make(chan Message, _memoryQueueSize)
+	b.inboxes[replyTo] = inbox
+
+	return &MemoryClient{
+		broker:  b,
+		server:  server,
+		replyTo: replyTo,
+		inbox:   inbox,
+	}
+}
+
+// Server - транспорт сервера, его очередь объявляется сразу
+func (b *MemoryBroker) Server(server string) *MemoryServer {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	queue, ok := b.queues[server]
+	if !ok {
+		queue = make(chan *Delivery, _memoryQueueSize)
+		b.queues[server] = queue
+	}
+
+	return &MemoryServer{
+		broker: b,
+		server: server,
+		queue:  queue,
+	}
+}
+
+// DeadLetters - вызовы сервера, исчерпавшие все попытки
+func (b *MemoryBroker) DeadLetters(server string) []Message {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	return append([]Message(nil), b.dead[server]...)
+}
+
+func (b *MemoryBroker) queue(server string) (chan *Delivery, bool) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	queue, ok := b.queues[server]
+
+	return queue, ok
+}
+
+func (b *MemoryBroker) inbox(replyTo string) (chan Message, bool) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	inbox, ok := b.inboxes[replyTo]
+
+	return inbox, ok
+}
+
+// Сообщение копируется, чтобы клиент и сервер не делили тело и заголовки
+func (b *MemoryBroker) delivery(queue chan *Delivery, call Message, attempt int) *Delivery {
+	call.Headers = maps.Clone(call.Headers)
+	call.Body = append([]byte(nil), call.Body...)
+
+	d := &Delivery{Message: call, Attempt: attempt}
+
+	var once sync.Once
+
+	d.Ack = func() error {
+		once.Do(func() {})
+
+		return nil
+	}
+	d.Nack = func(requeue bool) error {
+		once.Do(func() {
+			if requeue {
+				// Очередь может быть заполнена, а потребитель ее и разбирает
+				go func() {
+					queue <- b.delivery(queue, call, attempt+1)
+				}()
+			}
+		})
+
+		return nil
+	}
+
+	return d
+}
+
+// MemoryClient - клиентский транспорт MemoryBroker
+type MemoryClient struct {
+	broker  *MemoryBroker
+	server  string
+	replyTo string
+	inbox   chan Message
+}
+
+func (t *MemoryClient) Publish(ctx context.Context, call Message) error {
+	queue, ok := t.broker.queue(t.server)
+	if !ok {
+		return ErrNoConsumers
+	}
+
+	call.ReplyTo = t.replyTo
+
+	select {
+	case queue <- t.broker.delivery(queue, call, 1):
+		return nil
+	case <-ctx.Done():
+		return ctx.Err() //nolint:wrapcheck // just sending raw error
+	}
+}
+
+// Consume - брокер не теряет ответы, поэтому onLost не вызывается
+func (t *MemoryClient) Consume(ctx context.Context, onReply func(reply Message), _ func(err error)) {
+	go func() {
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case reply := <-t.inbox:
+				onReply(reply)
+			}
+		}
+	}()
+}
+
+func (t *MemoryClient) Notify() <-chan error {
+	return nil
+}
+
+// Close - после него ответы клиенту отбрасываются
+func (t *MemoryClient) Close() error {
+	t.broker.mu.Lock()
+	delete(t.broker.inboxes, t.replyTo)
+	t.broker.mu.Unlock()
+
+	return nil
+}
+
+// MemoryServer - серверный транспорт MemoryBroker, серверы с одним именем делят очередь
+type MemoryServer struct {
+	broker *MemoryBroker
+	server string
+	queue  chan *Delivery
+}
+
+func (t *MemoryServer) Consume(ctx context.Context, goroutines int, handler func(d *Delivery)) {
+	for i := 0; i < goroutines; i++ {
+		go func() {
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case d := <-t.queue:
+					handler(d)
+				}
+			}
+		}()
+	}
+}
+
+func (t *MemoryServer) Reply(ctx context.Context, replyTo string, reply Message) error {
+	inbox, ok := t.broker.inbox(replyTo)
+	if !ok {
+		return nil
+	}
+
+	reply.Body = append([]byte(nil), reply.Body...)
+
+	select {
+	case inbox <- reply:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err() //nolint:wrapcheck // just sending raw error
+	}
+}
+
+func (t *MemoryServer) DeadLetter(_ context.Context, call Message) error {
+	t.broker.mu.Lock()
+	t.broker.dead[t.server] = append(t.broker.dead[t.server], call)
+	t.broker.mu.Unlock()
+
+	return nil
+}
+
+func (t *MemoryServer) Notify() <-chan error {
+	return nil
+}
+
+// Close - очередь сохраняется, как устойчивая очередь RabbitMQ
+func (t *MemoryServer) Close() error {
+	return nil
+}
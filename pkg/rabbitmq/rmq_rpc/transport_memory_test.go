@@ -0,0 +1,419 @@
+package rmq_rpc_test
+
+import (
+	"WalletRieltaTestTask/pkg/rabbitmq/rmq_rpc"
+	"WalletRieltaTestTask/pkg/rabbitmq/rmq_rpc/client"
+	"WalletRieltaTestTask/pkg/rabbitmq/rmq_rpc/server"
+	"context"
+	"errors"
+	"fmt"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+const _testServer = "rpc_test"
+
+// Shutdown сервера и клиента ждет их таймаут, поэтому в тестах он короткий
+func serve(t *testing.T, broker *rmq_rpc.MemoryBroker, router map[string]server.CallHandler, opts ...server.Option) {
+	t.Helper()
+
+	opts = append([]server.Option{server.Timeout(10 * time.Millisecond)}, opts...)
+
+	s := server.NewWithTransport(broker.Server(_testServer), router, discardLogger(), opts...)
+	s.MustRun()
+
+	t.Cleanup(func() { _ = s.Shutdown() })
+}
+
+func newClient(t *testing.T, broker *rmq_rpc.MemoryBroker, opts ...client.Option) *client.Client {
+	t.Helper()
+
+	opts = append([]client.Option{client.Timeout(500 * time.Millisecond)}, opts...)
+
+	c := client.New(broker.Client(_testServer), opts...)
+
+	t.Cleanup(func() { _ = c.Shutdown() })
+
+	return c
+}
+
+type transferRequest struct {
+	From   string `json:"from"   msgpack:"from"`
+	To     string `json:"to"     msgpack:"to"`
+	Amount uint   `json:"amount" msgpack:"amount"`
+}
+
+type transferResponse struct {
+	ID     int64 `json:"id"     msgpack:"id"`
+	Amount uint  `json:"amount" msgpack:"amount"`
+}
+
+func TestMemoryRemoteCall(t *testing.T) {
+	tests := []struct {
+		name string
+		opts []client.Option
+	}{
+		{"json", nil},
+		{"msgpack", []client.Option{client.Codecs(rmq_rpc.MessagePack(), rmq_rpc.JSON())}},
+		// Запрос и ответ длиннее порога сжимаются
+		{"msgpack zstd", []client.Option{
+			client.Codecs(rmq_rpc.MessagePack(), rmq_rpc.JSON()),
+			client.Compression(1, rmq_rpc.Zstd()),
+		}},
+		{"json gzip", []client.Option{client.Compression(1, rmq_rpc.Gzip())}},
+	}
+
+	codes := rmq_rpc.NewRegistry().Register("insufficient", errInsufficient)
+
+	router := map[string]server.CallHandler{
+		"transfer": server.Handle(func(_ context.Context, request transferRequest) (transferResponse, error) {
+			if request.Amount > 100 {
+				return transferResponse{}, errInsufficient
+			}
+
+			return transferResponse{ID: 42, Amount: request.Amount}, nil
+		}),
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			broker := rmq_rpc.NewMemoryBroker()
+
+			serve(t, broker, router, server.Errors(codes), server.Compression(1, rmq_rpc.Zstd(), rmq_rpc.Gzip()))
+			c := newClient(t, broker, append(tt.opts, client.Errors(codes))...)
+
+			var response transferResponse
+
+			err := c.RemoteCall(context.Background(), "transfer", transferRequest{"a", "b", 30}, &response)
+			if err != nil {
+				t.Fatalf("RemoteCall: %v", err)
+			}
+
+			if response != (transferResponse{ID: 42, Amount: 30}) {
+				t.Fatalf("response = %+v, want the transfer of 30", response)
+			}
+
+			err = c.RemoteCall(context.Background(), "transfer", transferRequest{"a", "b", 300}, &response)
+			if !errors.Is(err, errInsufficient) {
+				t.Fatalf("err = %v, want %v", err, errInsufficient)
+			}
+
+			err = c.RemoteCall(context.Background(), "unknown", transferRequest{}, nil)
+			if !errors.Is(err, rmq_rpc.ErrBadHandler) {
+				t.Fatalf("err = %v, want %v", err, rmq_rpc.ErrBadHandler)
+			}
+		})
+	}
+}
+
+// Сгенерированные protobuf сообщения декодируются в новое значение указателя на обеих сторонах
+func TestMemoryRemoteCallProtobuf(t *testing.T) {
+	broker := rmq_rpc.NewMemoryBroker()
+
+	serve(t, broker, map[string]server.CallHandler{
+		"greet": server.Handle(func(_ context.Context, request *wrapperspb.StringValue) (*wrapperspb.StringValue, error) {
+			return wrapperspb.String("hello " + request.GetValue()), nil
+		}),
+	})
+
+	c := newClient(t, broker, client.Codecs(rmq_rpc.Protobuf(), rmq_rpc.JSON()))
+
+	response := &wrapperspb.StringValue{}
+
+	err := c.RemoteCall(context.Background(), "greet", wrapperspb.String("alice"), response)
+	if err != nil {
+		t.Fatalf("RemoteCall: %v", err)
+	}
+
+	if !proto.Equal(response, wrapperspb.String("hello alice")) {
+		t.Fatalf("response = %v, want hello alice", response)
+	}
+}
+
+// Зарегистрированная ошибка разворачивается на клиенте в sentinel ошибку,
+// остальные приходят как Error с кодом и сообщением сервера
+func TestMemoryErrorRegistry(t *testing.T) {
+	broker := rmq_rpc.NewMemoryBroker()
+
+	codes := rmq_rpc.NewRegistry().Register("insufficient", errInsufficient)
+
+	serve(t, broker, map[string]server.CallHandler{
+		"registered": server.Handle(func(_ context.Context, request transferRequest) (transferResponse, error) {
+			return transferResponse{}, fmt.Errorf("wallet %s: %w", request.From, errInsufficient)
+		}),
+		"unregistered": server.Handle(func(context.Context, transferRequest) (transferResponse, error) {
+			return transferResponse{}, errors.New("disk is full")
+		}),
+	}, server.Errors(codes))
+
+	c := newClient(t, broker, client.Errors(codes))
+
+	var rpcErr *rmq_rpc.Error
+
+	err := c.RemoteCall(context.Background(), "registered", transferRequest{From: "a"}, nil)
+	if !errors.Is(err, errInsufficient) || !errors.As(err, &rpcErr) || rpcErr.Code != "insufficient" {
+		t.Fatalf("err = %v, want %v with the insufficient code", err, errInsufficient)
+	}
+
+	err = c.RemoteCall(context.Background(), "unregistered", transferRequest{}, nil)
+	if !errors.As(err, &rpcErr) || rpcErr.Code != rmq_rpc.CodeUnknown || err.Error() != "disk is full" {
+		t.Fatalf("err = %v, want the unknown error", err)
+	}
+
+	if errors.Unwrap(err) != nil || errors.Is(err, errInsufficient) {
+		t.Fatalf("unknown error %v is unwrapped", err)
+	}
+
+	// Код, которого нет в реестре клиента, не разворачивается
+	c = newClient(t, broker)
+
+	err = c.RemoteCall(context.Background(), "registered", transferRequest{From: "a"}, nil)
+	if errors.Is(err, errInsufficient) || !errors.As(err, &rpcErr) || rpcErr.Code != "insufficient" {
+		t.Fatalf("err = %v, want the insufficient code without the sentinel error", err)
+	}
+}
+
+// Ответы приходят не в порядке вызовов и в разные очереди клиентов,
+// каждый вызов получает свой ответ по CorrelationID
+func TestMemoryReplyRouting(t *testing.T) {
+	broker := rmq_rpc.NewMemoryBroker()
+
+	serve(t, broker, map[string]server.CallHandler{
+		"delay": server.Handle(func(_ context.Context, n int) (int, error) {
+			time.Sleep(time.Duration(n%5) * time.Millisecond)
+
+			return n, nil
+		}),
+	}, server.DefaultGoroutinesCount(8))
+
+	clients := []*client.Client{newClient(t, broker), newClient(t, broker)}
+
+	var wg sync.WaitGroup
+
+	for i, c := range clients {
+		for j := 0; j < 50; j++ {
+			wg.Add(1)
+
+			go func(c *client.Client, n int) {
+				defer wg.Done()
+
+				var response int
+				if err := c.RemoteCall(context.Background(), "delay", n, &response); err != nil {
+					t.Errorf("RemoteCall(%d): %v", n, err)
+
+					return
+				}
+
+				if response != n {
+					t.Errorf("call %d got the reply %d", n, response)
+				}
+			}(c, i*1000+j)
+		}
+	}
+
+	wg.Wait()
+}
+
+// Обработчик видит дедлайн клиента, а клиент сам завершает вызов по нему
+func TestMemoryDeadlinePropagation(t *testing.T) {
+	const timeout = 200 * time.Millisecond
+
+	broker := rmq_rpc.NewMemoryBroker()
+	deadlines := make(chan time.Time, 1)
+	release := make(chan struct{})
+
+	// Ответ обработчика с ошибкой дедлайна не должен опередить таймаут клиента
+	serve(t, broker, map[string]server.CallHandler{
+		"wait": server.Handle(func(ctx context.Context, _ struct{}) (struct{}, error) {
+			deadline, _ := ctx.Deadline()
+			deadlines <- deadline
+
+			<-ctx.Done()
+			<-release
+
+			return struct{}{}, ctx.Err()
+		}),
+	})
+
+	t.Cleanup(func() { close(release) })
+
+	c := newClient(t, broker, client.Timeout(timeout))
+
+	t.Run("client timeout", func(t *testing.T) {
+		start := time.Now()
+
+		err := c.RemoteCall(context.Background(), "wait", struct{}{}, nil)
+		end := time.Now()
+		deadline := <-deadlines
+
+		if !errors.Is(err, rmq_rpc.ErrTimeout) {
+			t.Fatalf("err = %v, want %v", err, rmq_rpc.ErrTimeout)
+		}
+
+		if deadline.Before(start.Add(timeout)) || deadline.After(end) {
+			t.Fatalf("handler deadline = %s, want %s after the call", deadline, timeout)
+		}
+	})
+
+	t.Run("caller deadline", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), timeout/4)
+		defer cancel()
+
+		err := c.RemoteCall(ctx, "wait", struct{}{}, nil)
+		deadline := <-deadlines
+
+		if !errors.Is(err, context.DeadlineExceeded) {
+			t.Fatalf("err = %v, want %v", err, context.DeadlineExceeded)
+		}
+
+		if want, _ := ctx.Deadline(); !deadline.Equal(want) {
+			t.Fatalf("handler deadline = %s, want the caller deadline %s", deadline, want)
+		}
+	})
+}
+
+// Вызов, дождавшийся сервера после дедлайна, не выполняется
+func TestMemoryExpiredCallIsNotServed(t *testing.T) {
+	broker := rmq_rpc.NewMemoryBroker()
+	broker.Server(_testServer)
+
+	c := newClient(t, broker, client.Timeout(20*time.Millisecond))
+
+	err := c.RemoteCall(context.Background(), "count", struct{}{}, nil)
+	if !errors.Is(err, rmq_rpc.ErrTimeout) {
+		t.Fatalf("err = %v, want %v", err, rmq_rpc.ErrTimeout)
+	}
+
+	var calls atomic.Int32
+
+	// Одна горутина разбирает очередь по порядку, истекший вызов идет первым
+	serve(t, broker, map[string]server.CallHandler{
+		"count": server.Handle(func(context.Context, struct{}) (int32, error) {
+			return calls.Add(1), nil
+		}),
+	}, server.DefaultGoroutinesCount(1))
+
+	var served int32
+
+	err = newClient(t, broker).RemoteCall(context.Background(), "count", struct{}{}, &served)
+	if err != nil {
+		t.Fatalf("RemoteCall: %v", err)
+	}
+
+	if served != 1 {
+		t.Fatalf("handler was called %d times, want once for the live call", served)
+	}
+}
+
+var errBusy = errors.New("busy")
+
+// Временные ошибки возвращают вызов в очередь, после MaxAttempts он уходит в dead letters
+func TestMemoryRequeueThenDeadLetter(t *testing.T) {
+	const maxAttempts = 3
+
+	codes := rmq_rpc.NewRegistry().Register("busy", errBusy)
+
+	tests := []struct {
+		name     string
+		failures int32
+		panics   bool
+		dead     bool
+		code     string
+		reason   string
+	}{
+		{"recovers", maxAttempts - 1, false, false, "", ""},
+		{"dead-lettered", maxAttempts + 1, false, true, "busy", "busy"},
+		// Паника обработчика повторяется, как временная ошибка
+		{"panics", maxAttempts + 1, true, true, rmq_rpc.CodeUnknown, "handler panicked"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var attempts atomic.Int32
+
+			broker := rmq_rpc.NewMemoryBroker()
+
+			serve(t, broker, map[string]server.CallHandler{
+				"flaky": server.Handle(func(context.Context, struct{}) (int32, error) {
+					attempt := attempts.Add(1)
+					if attempt <= tt.failures {
+						if tt.panics {
+							panic("worker state is broken")
+						}
+
+						return 0, errBusy
+					}
+
+					return attempt, nil
+				}),
+			},
+				server.Errors(codes),
+				server.MaxAttempts(maxAttempts),
+				server.Transient(func(err error) bool { return errors.Is(err, errBusy) }),
+			)
+
+			var served int32
+
+			err := newClient(t, broker, client.Errors(codes)).
+				RemoteCall(context.Background(), "flaky", struct{}{}, &served)
+
+			if got := attempts.Load(); got != min(tt.failures+1, maxAttempts) {
+				t.Fatalf("handler was called %d times", got)
+			}
+
+			dead := broker.DeadLetters(_testServer)
+
+			if !tt.dead {
+				if err != nil || served != maxAttempts {
+					t.Fatalf("RemoteCall = %d, %v, want success of the last attempt", served, err)
+				}
+
+				if len(dead) != 0 {
+					t.Fatalf("%d calls dead-lettered, want none", len(dead))
+				}
+
+				return
+			}
+
+			var rpcErr *rmq_rpc.Error
+			if !errors.As(err, &rpcErr) {
+				t.Fatalf("err = %v, want the error of the server", err)
+			}
+
+			if rpcErr.Code != tt.code || !rpcErr.Retryable || rpcErr.Details["attempts"] != "3" {
+				t.Fatalf("err = %+v, want retryable %s after 3 attempts", rpcErr, tt.code)
+			}
+
+			if len(dead) != 1 || dead[0].Type != "flaky" {
+				t.Fatalf("dead letters = %+v, want the call", dead)
+			}
+
+			if reason, _ := dead[0].Headers[server.ErrorHeader].(string); !strings.Contains(reason, tt.reason) {
+				t.Fatalf("dead letter reason = %q, want %q of the last attempt", reason, tt.reason)
+			}
+		})
+	}
+}
+
+// Ответ, который не кодируется, возвращается клиенту ошибкой, а не пустым результатом
+func TestMemoryResponseMarshalError(t *testing.T) {
+	broker := rmq_rpc.NewMemoryBroker()
+
+	serve(t, broker, map[string]server.CallHandler{
+		"channel": server.Handle(func(context.Context, struct{}) (chan int, error) {
+			return make(chan int), nil
+		}),
+	})
+
+	var response []int
+
+	err := newClient(t, broker).RemoteCall(context.Background(), "channel", struct{}{}, &response)
+	if !errors.Is(err, rmq_rpc.ErrInternal) {
+		t.Fatalf("err = %v, want %v", err, rmq_rpc.ErrInternal)
+	}
+}
@@ -0,0 +1,24 @@
+package subscriber
+
+import "time"
+
+type Option func(*Subscriber)
+
+func ConnWaitTime(timeout time.Duration) Option {
+	return func(s *Subscriber) {
+		s.waitTime = timeout
+	}
+}
+
+func ConnAttempts(attempts int) Option {
+	return func(s *Subscriber) {
+		s.attempts = attempts
+	}
+}
+
+// OnReconnect - called after the lost connection is restored.
+func OnReconnect(f func()) Option {
+	return func(s *Subscriber) {
+		s.onReconnect = f
+	}
+}
@@ -0,0 +1,217 @@
+package subscriber
+
+import (
+	"WalletRieltaTestTask/pkg/logger"
+	"fmt"
+	amqp "github.com/rabbitmq/amqp091-go"
+	"log"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+const (
+	_defaultWaitTime = 2 * time.Second
+	_defaultAttempts = 10
+)
+
+type Handler func(d amqp.Delivery)
+
+// Subscriber - consumes messages of a durable topic exchange through a temporary queue,
+// so every instance receives all messages matching the binding keys.
+// Messages published while the subscriber is disconnected are lost.
+type Subscriber struct {
+	url      string
+	exchange string
+	keys     []string
+	handler  Handler
+
+	conn       *amqp.Connection
+	deliveries <-chan amqp.Delivery
+
+	stop     chan struct{}
+	done     chan struct{}
+	stopOnce sync.Once
+
+	waitTime    time.Duration
+	attempts    int
+	onReconnect func()
+
+	logger *slog.Logger
+}
+
+func New(url, exchange string, keys []string, handler Handler, l *slog.Logger, opts ...Option) (*Subscriber, error) {
+	s := &Subscriber{
+		url:      url,
+		exchange: exchange,
+		keys:     keys,
+		handler:  handler,
+		stop:     make(chan struct{}),
+		done:     make(chan struct{}),
+		waitTime: _defaultWaitTime,
+		attempts: _defaultAttempts,
+		logger:   l,
+	}
+
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	err := s.attemptConnect()
+	if err != nil {
+		return nil, fmt.Errorf("rabbitmq subscriber - New - s.attemptConnect: %w", err)
+	}
+
+	return s, nil
+}
+
+func (s *Subscriber) attemptConnect() error {
+	var err error
+	for i := s.attempts; i > 0; i-- {
+		if err = s.connect(); err == nil {
+			break
+		}
+
+		log.Printf("RabbitMQ subscriber is trying to connect, attempts left: %d", i)
+
+		select {
+		case <-s.stop:
+			return err
+		case <-time.After(s.waitTime):
+		}
+	}
+
+	return err
+}
+
+func (s *Subscriber) connect() error {
+	conn, err := amqp.Dial(s.url)
+	if err != nil {
+		return fmt.Errorf("amqp.Dial: %w", err)
+	}
+
+	channel, err := conn.Channel()
+	if err != nil {
+		_ = conn.Close()
+		return fmt.Errorf("conn.Channel: %w", err)
+	}
+
+	// Same declaration as the publisher, so the subscriber may start first
+	err = channel.ExchangeDeclare(
+		s.exchange,
+		"topic",
+		true,
+		false,
+		false,
+		false,
+		nil,
+	)
+	if err != nil {
+		_ = conn.Close()
+		return fmt.Errorf("channel.ExchangeDeclare: %w", err)
+	}
+
+	// Temporary queue is removed after the connection is closed
+	queue, err := channel.QueueDeclare(
+		"",
+		false,
+		true,
+		true,
+		false,
+		nil,
+	)
+	if err != nil {
+		_ = conn.Close()
+		return fmt.Errorf("channel.QueueDeclare: %w", err)
+	}
+
+	for _, key := range s.keys {
+		err = channel.QueueBind(queue.Name, key, s.exchange, false, nil)
+		if err != nil {
+			_ = conn.Close()
+			return fmt.Errorf("channel.QueueBind: %w", err)
+		}
+	}
+
+	deliveries, err := channel.Consume(
+		queue.Name,
+		"",
+		true,
+		true,
+		false,
+		false,
+		nil,
+	)
+	if err != nil {
+		_ = conn.Close()
+		return fmt.Errorf("channel.Consume: %w", err)
+	}
+
+	s.conn = conn
+	s.deliveries = deliveries
+
+	return nil
+}
+
+// MustRun - passing messages to the handler one by one until the shutdown.
+func (s *Subscriber) MustRun() {
+	go s.consume()
+}
+
+func (s *Subscriber) consume() {
+	defer close(s.done)
+
+	for {
+		select {
+		case <-s.stop:
+			return
+		case d, opened := <-s.deliveries:
+			if opened {
+				s.handler(d)
+
+				continue
+			}
+
+			s.reconnect()
+		}
+	}
+}
+
+// Connecting again until it succeeds or the subscriber is stopped.
+func (s *Subscriber) reconnect() {
+	for {
+		err := s.attemptConnect()
+		if err == nil {
+			break
+		}
+
+		select {
+		case <-s.stop:
+			return
+		default:
+		}
+
+		s.logger.Error("rabbitmq subscriber - Subscriber - reconnect - s.attemptConnect", logger.Err(err))
+	}
+
+	// Messages of the downtime are lost, the owner decides how to catch up
+	if s.onReconnect != nil {
+		s.onReconnect()
+	}
+}
+
+func (s *Subscriber) Shutdown() error {
+	s.stopOnce.Do(func() { close(s.stop) })
+	<-s.done
+
+	if s.conn == nil || s.conn.IsClosed() {
+		return nil
+	}
+
+	err := s.conn.Close()
+	if err != nil {
+		return fmt.Errorf("rabbitmq subscriber - Shutdown - s.conn.Close: %w", err)
+	}
+
+	return nil
+}
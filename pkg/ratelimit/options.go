@@ -0,0 +1,21 @@
+package ratelimit
+
+import "time"
+
+type Option func(*Limiter)
+
+// Burst - maximum number of requests made at once, defaults to the number of requests per period.
+func Burst(burst int) Option {
+	return func(l *Limiter) {
+		if burst > 0 {
+			l.burst = float64(burst)
+		}
+	}
+}
+
+// IdleTimeout - buckets of the keys without requests for this time are removed.
+func IdleTimeout(timeout time.Duration) Option {
+	return func(l *Limiter) {
+		l.idleTimeout = timeout
+	}
+}
@@ -0,0 +1,126 @@
+package ratelimit
+
+import (
+	"math"
+	"sync"
+	"time"
+)
+
+const _defaultIdleTimeout = 10 * time.Minute
+
+// Limiter - token bucket rate limiter with a separate bucket for every key.
+type Limiter struct {
+	mu      sync.Mutex
+	buckets map[string]*bucket
+
+	requests int
+	period   time.Duration
+	rate     float64 // tokens per second
+	burst    float64
+
+	idleTimeout time.Duration
+	lastSweep   time.Time
+
+	now func() time.Time
+}
+
+type bucket struct {
+	tokens  float64
+	updated time.Time
+}
+
+// Result - state of the bucket after the request.
+type Result struct {
+	Allowed bool
+	// Limit - size of the bucket.
+	Limit int
+	// Remaining - requests that can be made right now.
+	Remaining int
+	// Reset - time until the bucket is full again.
+	Reset time.Duration
+	// RetryAfter - time until the next request is allowed, zero if the request is allowed.
+	RetryAfter time.Duration
+}
+
+// New - allows the number of requests per period for every key.
+func New(requests int, period time.Duration, opts ...Option) *Limiter {
+	l := &Limiter{
+		buckets:     make(map[string]*bucket),
+		requests:    requests,
+		period:      period,
+		rate:        float64(requests) / period.Seconds(),
+		burst:       float64(requests),
+		idleTimeout: _defaultIdleTimeout,
+		now:         time.Now,
+	}
+
+	for _, opt := range opts {
+		opt(l)
+	}
+
+	return l
+}
+
+// Requests - number of requests allowed per period.
+func (l *Limiter) Requests() int {
+	return l.requests
+}
+
+// Period - window of the requests number.
+func (l *Limiter) Period() time.Duration {
+	return l.period
+}
+
+// Allow - takes a token from the bucket of the key if there is one.
+func (l *Limiter) Allow(key string) Result {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	l.sweep(now)
+
+	b, ok := l.buckets[key]
+	if !ok {
+		b = &bucket{tokens: l.burst, updated: now}
+		l.buckets[key] = b
+	}
+
+	// Refill for the time passed since the last request
+	b.tokens = math.Min(l.burst, b.tokens+now.Sub(b.updated).Seconds()*l.rate)
+	b.updated = now
+
+	result := Result{Limit: int(l.burst)}
+
+	if b.tokens >= 1 {
+		b.tokens--
+		result.Allowed = true
+	} else {
+		result.RetryAfter = l.duration(1 - b.tokens)
+	}
+
+	result.Remaining = int(b.tokens)
+	result.Reset = l.duration(l.burst - b.tokens)
+
+	return result
+}
+
+// Time needed to refill the tokens.
+func (l *Limiter) duration(tokens float64) time.Duration {
+	return time.Duration(math.Ceil(tokens / l.rate * float64(time.Second)))
+}
+
+// Idle buckets that are already refilled can be dropped, a new bucket starts full anyway.
+func (l *Limiter) sweep(now time.Time) {
+	if now.Sub(l.lastSweep) < l.idleTimeout {
+		return
+	}
+
+	for key, b := range l.buckets {
+		idle := now.Sub(b.updated)
+		if idle >= l.idleTimeout && idle >= l.duration(l.burst-b.tokens) {
+			delete(l.buckets, key)
+		}
+	}
+
+	l.lastSweep = now
+}
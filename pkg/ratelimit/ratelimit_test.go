@@ -0,0 +1,86 @@
+package ratelimit
+
+import (
+	"testing"
+	"time"
+)
+
+type clock struct {
+	now time.Time
+}
+
+func (c *clock) Now() time.Time {
+	return c.now
+}
+
+func newTestLimiter(requests int, period time.Duration, opts ...Option) (*Limiter, *clock) {
+	c := &clock{now: time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)}
+
+	l := New(requests, period, opts...)
+	l.now = c.Now
+
+	return l, c
+}
+
+func TestLimiterBurst(t *testing.T) {
+	l, _ := newTestLimiter(10, time.Minute, Burst(3))
+
+	for i := 0; i < 3; i++ {
+		result := l.Allow("key")
+		if !result.Allowed || result.Remaining != 2-i || result.Limit != 3 {
+			t.Fatalf("request %d = %+v, want allowed with %d remaining", i, result, 2-i)
+		}
+	}
+
+	// A token is refilled every 6 seconds
+	result := l.Allow("key")
+	if result.Allowed || result.RetryAfter != 6*time.Second || result.Reset != 18*time.Second {
+		t.Fatalf("request over the burst = %+v, want retry after 6s", result)
+	}
+
+	if !l.Allow("other").Allowed {
+		t.Fatal("another key shares the bucket")
+	}
+}
+
+func TestLimiterRefill(t *testing.T) {
+	l, c := newTestLimiter(2, time.Minute)
+
+	l.Allow("key")
+	l.Allow("key")
+
+	c.now = c.now.Add(29 * time.Second)
+
+	if result := l.Allow("key"); result.Allowed || result.RetryAfter != time.Second {
+		t.Fatalf("request before the refill = %+v, want retry after 1s", result)
+	}
+
+	c.now = c.now.Add(time.Second)
+
+	if result := l.Allow("key"); !result.Allowed || result.Remaining != 0 {
+		t.Fatalf("request after the refill = %+v, want allowed", result)
+	}
+
+	// The bucket doesn't grow over the burst while it's idle
+	c.now = c.now.Add(time.Hour)
+
+	if result := l.Allow("key"); !result.Allowed || result.Remaining != 1 {
+		t.Fatalf("request after an idle hour = %+v, want 1 remaining", result)
+	}
+}
+
+func TestLimiterSweep(t *testing.T) {
+	l, c := newTestLimiter(2, time.Minute, IdleTimeout(time.Minute))
+
+	l.Allow("idle")
+	c.now = c.now.Add(time.Minute)
+	l.Allow("active")
+
+	if _, ok := l.buckets["idle"]; ok {
+		t.Fatal("refilled idle bucket is kept")
+	}
+
+	if _, ok := l.buckets["active"]; !ok {
+		t.Fatal("active bucket is dropped")
+	}
+}
@@ -0,0 +1,66 @@
+package sealer
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+)
+
+const _keySize = 32
+
+var (
+	ErrKeySize = errors.New("sealer key must be 32 bytes hex encoded")
+	ErrOpen    = errors.New("sealed data is corrupted or sealed with another key")
+)
+
+// Sealer - AES-256-GCM encryption of the secrets stored at rest, the nonce is prepended to the ciphertext.
+type Sealer struct {
+	aead cipher.AEAD
+}
+
+// New - hexKey is 32 random bytes, e.g. "openssl rand -hex 32".
+func New(hexKey string) (*Sealer, error) {
+	key, err := hex.DecodeString(hexKey)
+	if err != nil || len(key) != _keySize {
+		return nil, ErrKeySize
+	}
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("sealer - New - aes.NewCipher: %w", err)
+	}
+
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, fmt.Errorf("sealer - New - cipher.NewGCM: %w", err)
+	}
+
+	return &Sealer{aead: aead}, nil
+}
+
+func (s *Sealer) Seal(plaintext []byte) ([]byte, error) {
+	nonce := make([]byte, s.aead.NonceSize(), s.aead.NonceSize()+len(plaintext)+s.aead.Overhead())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, fmt.Errorf("sealer - Seal - rand.Read: %w", err)
+	}
+
+	return s.aead.Seal(nonce, nonce, plaintext, nil), nil
+}
+
+func (s *Sealer) Open(sealed []byte) ([]byte, error) {
+	if len(sealed) < s.aead.NonceSize() {
+		return nil, ErrOpen
+	}
+
+	nonce, ciphertext := sealed[:s.aead.NonceSize()], sealed[s.aead.NonceSize():]
+
+	plaintext, err := s.aead.Open(nil, nonce, ciphertext, nil)
+	if err != nil {
+		return nil, ErrOpen
+	}
+
+	return plaintext, nil
+}
@@ -0,0 +1,9 @@
+DROP TABLE IF EXISTS audit_log;
+
+ALTER TABLE api_keys DROP COLUMN IF EXISTS roles;
+
+ALTER TABLE transactions DROP COLUMN IF EXISTS reversed_at;
+ALTER TABLE transactions DROP COLUMN IF EXISTS reversal_of;
+ALTER TABLE transactions DROP COLUMN IF EXISTS id;
+
+ALTER TABLE wallets DROP COLUMN IF EXISTS frozen;
\ No newline at end of file
@@ -0,0 +1,21 @@
+ALTER TABLE wallets ADD COLUMN IF NOT EXISTS frozen BOOLEAN DEFAULT FALSE NOT NULL;
+
+ALTER TABLE transactions ADD COLUMN IF NOT EXISTS id BIGSERIAL PRIMARY KEY;
+ALTER TABLE transactions ADD COLUMN IF NOT EXISTS reversal_of BIGINT REFERENCES transactions(id);
+ALTER TABLE transactions ADD COLUMN IF NOT EXISTS reversed_at TIMESTAMP WITH TIME ZONE;
+
+ALTER TABLE api_keys ADD COLUMN IF NOT EXISTS roles TEXT[] DEFAULT '{}' NOT NULL;
+
+CREATE TABLE IF NOT EXISTS audit_log
+(
+    id BIGSERIAL PRIMARY KEY,
+    time TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    actor TEXT NOT NULL,
+    action TEXT NOT NULL,
+    wallet_id TEXT,
+    transaction_id BIGINT,
+    reason TEXT DEFAULT '' NOT NULL,
+    details JSONB DEFAULT '{}' NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS audit_log_wallet_id_idx ON audit_log (wallet_id);
\ No newline at end of file
@@ -0,0 +1,25 @@
+ALTER TABLE audit_log ADD COLUMN IF NOT EXISTS request_id TEXT DEFAULT '' NOT NULL;
+ALTER TABLE audit_log ADD COLUMN IF NOT EXISTS ip TEXT DEFAULT '' NOT NULL;
+ALTER TABLE audit_log ADD COLUMN IF NOT EXISTS payload_hash TEXT DEFAULT '' NOT NULL;
+ALTER TABLE audit_log ADD COLUMN IF NOT EXISTS balances JSONB DEFAULT '[]' NOT NULL;
+ALTER TABLE audit_log ADD COLUMN IF NOT EXISTS prev_hash TEXT DEFAULT '' NOT NULL;
+ALTER TABLE audit_log ADD COLUMN IF NOT EXISTS hash TEXT DEFAULT '' NOT NULL;
+
+CREATE INDEX IF NOT EXISTS audit_log_transaction_id_idx ON audit_log (transaction_id);
+CREATE INDEX IF NOT EXISTS audit_log_actor_idx ON audit_log (actor);
+CREATE INDEX IF NOT EXISTS audit_log_request_id_idx ON audit_log (request_id);
+
+-- The audit log is append-only
+CREATE OR REPLACE FUNCTION audit_log_immutable() RETURNS TRIGGER AS $$
+BEGIN
+    RAISE EXCEPTION 'audit_log is append-only';
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER audit_log_no_modify
+    BEFORE UPDATE OR DELETE ON audit_log
+    FOR EACH ROW EXECUTE FUNCTION audit_log_immutable();
+
+CREATE TRIGGER audit_log_no_truncate
+    BEFORE TRUNCATE ON audit_log
+    FOR EACH STATEMENT EXECUTE FUNCTION audit_log_immutable();
@@ -0,0 +1 @@
+CREATE INDEX IF NOT EXISTS outbox_wallet_idx ON outbox (wallet_id, id);
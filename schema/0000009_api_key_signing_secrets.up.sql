@@ -0,0 +1,3 @@
+-- Signing secrets are random and stored encrypted with AUTH_SECRET_KEY, separately from the lookup hash.
+-- Keys created before have no signing secret and can't sign requests until they are created again.
+ALTER TABLE api_keys ADD COLUMN IF NOT EXISTS signing_secret BYTEA;
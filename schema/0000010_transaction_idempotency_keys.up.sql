@@ -0,0 +1,7 @@
+-- Transfers are retried by clients and redelivered by the RPC queue, the key of a transfer is stored
+-- with it so the repeated request returns the same result instead of moving the funds again.
+-- Reversals and transfers made before have no key. Keys are unique per sender wallet, so a key chosen by one
+-- client doesn't block the transfers of others.
+ALTER TABLE transactions ADD COLUMN IF NOT EXISTS idempotency_key TEXT;
+
+CREATE UNIQUE INDEX IF NOT EXISTS transactions_idempotency_key_idx ON transactions (from_wallet_id, idempotency_key);